@@ -0,0 +1,39 @@
+// The null-plugin is a reference implementation of an out-of-process
+// provider plugin. It serves the null provider either on stdin/stdout, when
+// launched by the controller, or on the address passed via --listen.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/null"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/plugin"
+)
+
+func main() {
+	listen := flag.String("listen", "", "Address to listen on, e.g. unix:///var/run/null-plugin.sock. If empty, the plugin is served on stdin/stdout.")
+	flag.Parse()
+
+	if err := run(*listen); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(listen string) error {
+	impl := &null.Provider{}
+
+	if listen == "" {
+		return plugin.ServeStdio(impl)
+	}
+
+	l, err := plugin.Listen(listen)
+	if err != nil {
+		return err
+	}
+	defer l.Close()
+
+	return plugin.Serve(l, impl)
+}
@@ -4,23 +4,36 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"dario.cat/mergo"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/controller"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/gateway"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	networkingv1 "k8s.io/api/networking/v1"
+	apiruntime "k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	runtime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	restconfig "sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
+// providerCheckCacheDuration is the duration for which the result of the
+// provider readiness check is cached.
+const providerCheckCacheDuration = time.Minute
+
 var (
 	debug bool
 
@@ -84,27 +97,119 @@ func Run(options *config.Options) error {
 		}
 	}
 
-	mgr, err := manager.New(restconfig.GetConfigOrDie(), manager.Options{})
+	scheme := apiruntime.NewScheme()
+
+	err := clientgoscheme.AddToScheme(scheme)
+	if err != nil {
+		return errors.Wrapf(err, "failed to register built-in types")
+	}
+
+	err = v1alpha1.AddToScheme(scheme)
+	if err != nil {
+		return errors.Wrapf(err, "failed to register ingress-monitor.bonial.com types")
+	}
+
+	mgr, err := manager.New(restconfig.GetConfigOrDie(), manager.Options{
+		Scheme:                        scheme,
+		Cache:                         controller.CacheOptions(options),
+		Client:                        controller.ClientOptions(),
+		LeaderElection:                options.LeaderElection,
+		LeaderElectionID:              options.LeaderElectionID,
+		LeaderElectionNamespace:       options.LeaderElectionNamespace,
+		LeaderElectionReleaseOnCancel: true,
+		Metrics: metricsserver.Options{
+			BindAddress: options.MetricsBindAddress,
+		},
+		HealthProbeBindAddress: options.HealthProbeBindAddress,
+	})
 	if err != nil {
 		return errors.Wrapf(err, "failed to create controller manager")
 	}
 
-	svc, err := monitor.NewService(options)
+	recorder := mgr.GetEventRecorderFor("ingress-monitor-controller")
+
+	svc, err := monitor.NewService(mgr.GetClient(), recorder, options)
 	if err != nil {
 		return errors.Wrapf(err, "failed to initialize monitor service")
 	}
 
-	reconciler := controller.NewIngressReconciler(mgr.GetClient(), svc, options)
+	err = mgr.AddHealthzCheck("ping", healthz.Ping)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add liveness check")
+	}
+
+	err = mgr.AddReadyzCheck("providers", controller.NewProviderCheck(svc, providerCheckCacheDuration).Check)
+	if err != nil {
+		return errors.Wrapf(err, "failed to add readiness check")
+	}
+
+	selector, err := controller.NewIngressSelector(options)
+	if err != nil {
+		return err
+	}
+
+	reconciler := controller.NewIngressReconciler(mgr.GetClient(), svc, selector, options)
 
-	err = builder.
+	ingressBuilder := builder.
 		ControllerManagedBy(mgr).
 		Named("ingress-monitor-controller").
-		For(&networkingv1.Ingress{}).
-		Complete(reconciler)
+		For(&networkingv1.Ingress{}, builder.WithPredicates(controller.IgnoreStatusUpdates(), selector.Predicate()))
+
+	err = watchProfiles(ingressBuilder, reconciler.IngressesForProfile, options).Complete(reconciler)
 	if err != nil {
 		return errors.Wrapf(err, "failed to create controller")
 	}
 
+	if options.GatewayAPI {
+		routeReconciler := controller.NewRouteReconciler(mgr.GetClient(), svc, selector, options)
+
+		// Gateways are watched to update the monitors of attached routes if
+		// listeners change. Status updates of gateways are filtered out as
+		// each event causes routes to be listed.
+		routeBuilder := builder.
+			ControllerManagedBy(mgr).
+			Named("httproute-monitor-controller").
+			For(gateway.NewHTTPRoute(), builder.WithPredicates(controller.IgnoreStatusUpdates(), selector.Predicate())).
+			Watches(
+				gateway.NewGateway(),
+				handler.EnqueueRequestsFromMapFunc(routeReconciler.RoutesForGateway),
+				builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+			)
+
+		err = watchProfiles(routeBuilder, routeReconciler.RoutesForProfile, options).Complete(routeReconciler)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create HTTPRoute controller")
+		}
+	}
+
+	if options.IngressMonitors {
+		ingressMonitorReconciler := controller.NewIngressMonitorReconciler(mgr.GetClient(), svc, selector, options)
+
+		// Referenced ingresses are watched to update the monitor URL if
+		// their hosts or annotations change.
+		ingressMonitorBuilder := builder.
+			ControllerManagedBy(mgr).
+			Named("ingressmonitor-controller").
+			For(&v1alpha1.IngressMonitor{}, builder.WithPredicates(controller.IgnoreStatusUpdates(), selector.Predicate())).
+			Watches(
+				&networkingv1.Ingress{},
+				handler.EnqueueRequestsFromMapFunc(ingressMonitorReconciler.MonitorsForIngress),
+				builder.WithPredicates(controller.IgnoreStatusUpdates()),
+			)
+
+		err = watchProfiles(ingressMonitorBuilder, ingressMonitorReconciler.MonitorsForProfile, options).Complete(ingressMonitorReconciler)
+		if err != nil {
+			return errors.Wrapf(err, "failed to create IngressMonitor controller")
+		}
+	}
+
+	if options.GCInterval > 0 {
+		err = mgr.Add(controller.NewGarbageCollector(mgr.GetClient(), svc, selector, options))
+		if err != nil {
+			return errors.Wrapf(err, "failed to add garbage collector")
+		}
+	}
+
 	err = mgr.Start(signals.SetupSignalHandler())
 	if err != nil {
 		return errors.Wrapf(err, "unable to run manager")
@@ -112,3 +217,18 @@ func Run(options *config.Options) error {
 
 	return nil
 }
+
+// watchProfiles adds a watch for MonitorProfiles to b if they are enabled,
+// so that the resources referencing a profile returned by mapFunc are
+// reconciled if the profile changes.
+func watchProfiles(b *builder.Builder, mapFunc handler.MapFunc, options *config.Options) *builder.Builder {
+	if !options.MonitorProfiles {
+		return b
+	}
+
+	return b.Watches(
+		&v1alpha1.MonitorProfile{},
+		handler.EnqueueRequestsFromMapFunc(mapFunc),
+		builder.WithPredicates(predicate.GenerationChangedPredicate{}),
+	)
+}
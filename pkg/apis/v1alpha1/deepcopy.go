@@ -0,0 +1,311 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto copies the receiver into out.
+func (in *IngressMonitor) DeepCopyInto(out *IngressMonitor) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy creates a deep copy of the receiver.
+func (in *IngressMonitor) DeepCopy() *IngressMonitor {
+	if in == nil {
+		return nil
+	}
+
+	out := new(IngressMonitor)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *IngressMonitor) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *IngressMonitorList) DeepCopyInto(out *IngressMonitorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+
+	if in.Items != nil {
+		out.Items = make([]IngressMonitor, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of the receiver.
+func (in *IngressMonitorList) DeepCopy() *IngressMonitorList {
+	if in == nil {
+		return nil
+	}
+
+	out := new(IngressMonitorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *IngressMonitorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *IngressMonitorSpec) DeepCopyInto(out *IngressMonitorSpec) {
+	*out = *in
+
+	if in.IngressRef != nil {
+		out.IngressRef = new(IngressReference)
+		*out.IngressRef = *in.IngressRef
+	}
+
+	if in.Providers != nil {
+		out.Providers = append([]string(nil), in.Providers...)
+	}
+
+	if in.Site24x7 != nil {
+		out.Site24x7 = new(Site24x7Spec)
+		in.Site24x7.DeepCopyInto(out.Site24x7)
+	}
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *Site24x7Spec) DeepCopyInto(out *Site24x7Spec) {
+	*out = *in
+
+	if in.Timeout != nil {
+		out.Timeout = new(int)
+		*out.Timeout = *in.Timeout
+	}
+
+	if in.MatchCase != nil {
+		out.MatchCase = new(bool)
+		*out.MatchCase = *in.MatchCase
+	}
+
+	if in.UseNameServer != nil {
+		out.UseNameServer = new(bool)
+		*out.UseNameServer = *in.UseNameServer
+	}
+
+	if in.AuthPasswordSecretRef != nil {
+		out.AuthPasswordSecretRef = in.AuthPasswordSecretRef.DeepCopy()
+	}
+
+	if in.MonitorGroupIDs != nil {
+		out.MonitorGroupIDs = append([]string(nil), in.MonitorGroupIDs...)
+	}
+
+	if in.UserGroupIDs != nil {
+		out.UserGroupIDs = append([]string(nil), in.UserGroupIDs...)
+	}
+
+	if in.CustomHeaders != nil {
+		out.CustomHeaders = append([]Header(nil), in.CustomHeaders...)
+	}
+
+	if in.Actions != nil {
+		out.Actions = append([]Site24x7Action(nil), in.Actions...)
+	}
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *IngressMonitorStatus) DeepCopyInto(out *IngressMonitorStatus) {
+	*out = *in
+
+	if in.Providers != nil {
+		out.Providers = append([]string(nil), in.Providers...)
+	}
+
+	if in.LastSyncTime != nil {
+		out.LastSyncTime = in.LastSyncTime.DeepCopy()
+	}
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *MonitorProfile) DeepCopyInto(out *MonitorProfile) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy creates a deep copy of the receiver.
+func (in *MonitorProfile) DeepCopy() *MonitorProfile {
+	if in == nil {
+		return nil
+	}
+
+	out := new(MonitorProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *MonitorProfile) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *MonitorProfileList) DeepCopyInto(out *MonitorProfileList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+
+	if in.Items != nil {
+		out.Items = make([]MonitorProfile, len(in.Items))
+		for i := range in.Items {
+			in.Items[i].DeepCopyInto(&out.Items[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of the receiver.
+func (in *MonitorProfileList) DeepCopy() *MonitorProfileList {
+	if in == nil {
+		return nil
+	}
+
+	out := new(MonitorProfileList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object.
+func (in *MonitorProfileList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+
+	return nil
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *MonitorProfileSpec) DeepCopyInto(out *MonitorProfileSpec) {
+	*out = *in
+
+	if in.Site24x7 != nil {
+		out.Site24x7 = new(Site24x7Spec)
+		in.Site24x7.DeepCopyInto(out.Site24x7)
+	}
+
+	if in.UptimeRobot != nil {
+		out.UptimeRobot = new(UptimeRobotSpec)
+		in.UptimeRobot.DeepCopyInto(out.UptimeRobot)
+	}
+
+	if in.Pingdom != nil {
+		out.Pingdom = new(PingdomSpec)
+		in.Pingdom.DeepCopyInto(out.Pingdom)
+	}
+
+	if in.Blackbox != nil {
+		out.Blackbox = new(BlackboxSpec)
+		*out.Blackbox = *in.Blackbox
+	}
+
+	if in.Local != nil {
+		out.Local = new(LocalSpec)
+		in.Local.DeepCopyInto(out.Local)
+	}
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *UptimeRobotSpec) DeepCopyInto(out *UptimeRobotSpec) {
+	*out = *in
+
+	if in.AlertContacts != nil {
+		out.AlertContacts = append([]string(nil), in.AlertContacts...)
+	}
+
+	if in.CustomHTTPHeaders != nil {
+		out.CustomHTTPHeaders = make(map[string]string, len(in.CustomHTTPHeaders))
+		for name, value := range in.CustomHTTPHeaders {
+			out.CustomHTTPHeaders[name] = value
+		}
+	}
+
+	if in.Interval != nil {
+		out.Interval = new(int)
+		*out.Interval = *in.Interval
+	}
+
+	if in.Timeout != nil {
+		out.Timeout = new(int)
+		*out.Timeout = *in.Timeout
+	}
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *PingdomSpec) DeepCopyInto(out *PingdomSpec) {
+	*out = *in
+
+	if in.IntegrationIDs != nil {
+		out.IntegrationIDs = append([]int(nil), in.IntegrationIDs...)
+	}
+
+	if in.ProbeFilters != nil {
+		out.ProbeFilters = append([]string(nil), in.ProbeFilters...)
+	}
+
+	if in.RequestHeaders != nil {
+		out.RequestHeaders = make(map[string]string, len(in.RequestHeaders))
+		for name, value := range in.RequestHeaders {
+			out.RequestHeaders[name] = value
+		}
+	}
+
+	if in.Resolution != nil {
+		out.Resolution = new(int)
+		*out.Resolution = *in.Resolution
+	}
+
+	if in.Tags != nil {
+		out.Tags = append([]string(nil), in.Tags...)
+	}
+
+	if in.TeamIDs != nil {
+		out.TeamIDs = append([]int(nil), in.TeamIDs...)
+	}
+
+	if in.UserIDs != nil {
+		out.UserIDs = append([]int(nil), in.UserIDs...)
+	}
+}
+
+// DeepCopyInto copies the receiver into out.
+func (in *LocalSpec) DeepCopyInto(out *LocalSpec) {
+	*out = *in
+
+	if in.Interval != nil {
+		out.Interval = new(metav1.Duration)
+		*out.Interval = *in.Interval
+	}
+
+	if in.Timeout != nil {
+		out.Timeout = new(metav1.Duration)
+		*out.Timeout = *in.Timeout
+	}
+}
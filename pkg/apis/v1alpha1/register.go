@@ -0,0 +1,27 @@
+// Package v1alpha1 contains the v1alpha1 API of the
+// ingress-monitor.bonial.com group. It allows defining monitors explicitly
+// via IngressMonitor custom resources instead of ingress annotations and
+// sharing monitor settings via MonitorProfile custom resources. The
+// CustomResourceDefinitions are located in deploy/crd.yaml.
+package v1alpha1
+
+import (
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/scheme"
+)
+
+var (
+	// GroupVersion is the group version of the API.
+	GroupVersion = schema.GroupVersion{Group: "ingress-monitor.bonial.com", Version: "v1alpha1"}
+
+	// SchemeBuilder registers the types of the API with a scheme.
+	SchemeBuilder = &scheme.Builder{GroupVersion: GroupVersion}
+
+	// AddToScheme adds the types of the API to a scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+func init() {
+	SchemeBuilder.Register(&IngressMonitor{}, &IngressMonitorList{})
+	SchemeBuilder.Register(&MonitorProfile{}, &MonitorProfileList{})
+}
@@ -0,0 +1,279 @@
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IngressMonitorKind is the kind of the IngressMonitor resource.
+const IngressMonitorKind = "IngressMonitor"
+
+// IngressMonitor defines a monitor explicitly. The monitored URL is either
+// derived from an ingress in the same namespace or given as is.
+type IngressMonitor struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   IngressMonitorSpec   `json:"spec,omitempty"`
+	Status IngressMonitorStatus `json:"status,omitempty"`
+}
+
+// IngressMonitorList is a list of IngressMonitors.
+type IngressMonitorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []IngressMonitor `json:"items"`
+}
+
+// IngressMonitorSpec is the desired state of an IngressMonitor. Exactly one
+// of IngressRef and URL must be set.
+type IngressMonitorSpec struct {
+	// IngressRef references the ingress whose URL is monitored.
+	IngressRef *IngressReference `json:"ingressRef,omitempty"`
+
+	// URL is the URL to monitor, e.g. "https://example.com/health".
+	URL string `json:"url,omitempty"`
+
+	// Providers selects the providers that manage the monitor. If empty,
+	// the providers passed via --provider are used.
+	Providers []string `json:"providers,omitempty"`
+
+	// Site24x7 configures the monitor if it is managed by the site24x7
+	// provider. Unset fields fall back to the provider defaults.
+	Site24x7 *Site24x7Spec `json:"site24x7,omitempty"`
+}
+
+// IngressReference references an ingress in the namespace of the
+// IngressMonitor.
+type IngressReference struct {
+	// Name is the name of the ingress.
+	Name string `json:"name"`
+
+	// Path overrides the monitored path. If empty, the path-override
+	// annotation of the ingress is honored.
+	Path string `json:"path,omitempty"`
+}
+
+// Site24x7Spec contains the typed equivalents of the
+// site24x7.ingress-monitor.bonial.com annotations.
+type Site24x7Spec struct {
+	// CheckFrequency is the check interval in minutes. See
+	// https://www.site24x7.com/help/api/#check_interval for valid values.
+	CheckFrequency string `json:"checkFrequency,omitempty"`
+
+	// HTTPMethod is the HTTP method used for the check. See
+	// https://www.site24x7.com/help/api/#http_methods for valid values.
+	HTTPMethod string `json:"httpMethod,omitempty"`
+
+	// Timeout is the timeout in seconds for connecting to the website.
+	Timeout *int `json:"timeout,omitempty"`
+
+	// MatchCase makes the keyword search case sensitive.
+	MatchCase *bool `json:"matchCase,omitempty"`
+
+	// UseNameServer resolves the IP address using DNS.
+	UseNameServer *bool `json:"useNameServer,omitempty"`
+
+	// UserAgent overrides the user agent used by the check.
+	UserAgent string `json:"userAgent,omitempty"`
+
+	// AuthUser is the username if the website requires basic auth.
+	AuthUser string `json:"authUser,omitempty"`
+
+	// AuthPasswordSecretRef selects the key of a secret holding the
+	// password if the website requires basic auth. The secret is looked up
+	// in the namespace of the monitored resource.
+	AuthPasswordSecretRef *corev1.SecretKeySelector `json:"authPasswordSecretRef,omitempty"`
+
+	// LocationProfileID is the ID of the location profile.
+	LocationProfileID string `json:"locationProfileID,omitempty"`
+
+	// NotificationProfileID is the ID of the notification profile.
+	NotificationProfileID string `json:"notificationProfileID,omitempty"`
+
+	// ThresholdProfileID is the ID of the threshold profile.
+	ThresholdProfileID string `json:"thresholdProfileID,omitempty"`
+
+	// MonitorGroupIDs are the IDs of the monitor groups.
+	MonitorGroupIDs []string `json:"monitorGroupIDs,omitempty"`
+
+	// UserGroupIDs are the IDs of the user groups to alert.
+	UserGroupIDs []string `json:"userGroupIDs,omitempty"`
+
+	// CustomHeaders are additional HTTP headers sent with each check.
+	CustomHeaders []Header `json:"customHeaders,omitempty"`
+
+	// Actions are the IT Automation actions triggered by alerts.
+	Actions []Site24x7Action `json:"actions,omitempty"`
+}
+
+// Header is an HTTP header.
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Site24x7Action references a Site24x7 IT Automation action.
+type Site24x7Action struct {
+	// ActionID is the ID of the action.
+	ActionID string `json:"actionID"`
+
+	// AlertType is the monitor status that triggers the action. See
+	// https://www.site24x7.com/help/api/#action_constants for valid values.
+	AlertType int `json:"alertType"`
+}
+
+// MonitorState is the state of the last monitor sync.
+type MonitorState string
+
+const (
+	// MonitorStateSynced indicates that the monitor was synced
+	// successfully.
+	MonitorStateSynced MonitorState = "Synced"
+
+	// MonitorStateFailed indicates that the last sync failed, see the
+	// LastError field for details.
+	MonitorStateFailed MonitorState = "Failed"
+)
+
+// IngressMonitorStatus is the observed state of an IngressMonitor.
+type IngressMonitorStatus struct {
+	// ObservedGeneration is the generation of the spec that was last
+	// synced.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// State is the state of the last sync.
+	State MonitorState `json:"state,omitempty"`
+
+	// MonitorName is the name of the monitor.
+	MonitorName string `json:"monitorName,omitempty"`
+
+	// MonitorID is the provider specific ID of the monitor. It is omitted
+	// if the monitor is managed by multiple providers.
+	MonitorID string `json:"monitorID,omitempty"`
+
+	// Providers are the providers that manage the monitor.
+	Providers []string `json:"providers,omitempty"`
+
+	// LastError is the error of the last sync, if any.
+	LastError string `json:"lastError,omitempty"`
+
+	// LastSyncTime is the time of the last sync.
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// MonitorProfileKind is the kind of the MonitorProfile resource.
+const MonitorProfileKind = "MonitorProfile"
+
+// MonitorProfile contains reusable monitor settings. It is cluster-scoped and
+// referenced by name via the ingress-monitor.bonial.com/profile annotation.
+// Settings of the profile take precedence over the monitorDefaults of the
+// provider config, but annotations of the referencing resource take
+// precedence over the profile.
+type MonitorProfile struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec MonitorProfileSpec `json:"spec,omitempty"`
+}
+
+// MonitorProfileList is a list of MonitorProfiles.
+type MonitorProfileList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []MonitorProfile `json:"items"`
+}
+
+// MonitorProfileSpec contains the settings of a MonitorProfile per provider.
+// Unset fields fall back to the provider defaults.
+type MonitorProfileSpec struct {
+	// Site24x7 configures monitors managed by the site24x7 provider.
+	Site24x7 *Site24x7Spec `json:"site24x7,omitempty"`
+
+	// UptimeRobot configures monitors managed by the uptimerobot provider.
+	UptimeRobot *UptimeRobotSpec `json:"uptimerobot,omitempty"`
+
+	// Pingdom configures checks managed by the pingdom provider.
+	Pingdom *PingdomSpec `json:"pingdom,omitempty"`
+
+	// Blackbox configures Probes managed by the blackbox provider.
+	Blackbox *BlackboxSpec `json:"blackbox,omitempty"`
+
+	// Local configures monitors managed by the local provider.
+	Local *LocalSpec `json:"local,omitempty"`
+}
+
+// UptimeRobotSpec contains the typed equivalents of the
+// uptimerobot.ingress-monitor.bonial.com annotations.
+type UptimeRobotSpec struct {
+	// AlertContacts are the alert contacts in the format
+	// "<id>_<threshold>_<recurrence>", e.g. "123456_0_0".
+	AlertContacts []string `json:"alertContacts,omitempty"`
+
+	// CustomHTTPHeaders are additional HTTP headers sent with each check.
+	CustomHTTPHeaders map[string]string `json:"customHTTPHeaders,omitempty"`
+
+	// HTTPMethod is the HTTP method used for the check. Valid values are
+	// HEAD, GET, POST, PUT, PATCH, DELETE and OPTIONS.
+	HTTPMethod string `json:"httpMethod,omitempty"`
+
+	// Interval is the check interval in seconds.
+	Interval *int `json:"interval,omitempty"`
+
+	// Timeout is the timeout in seconds for connecting to the website. Has
+	// to be in range 1-60.
+	Timeout *int `json:"timeout,omitempty"`
+}
+
+// PingdomSpec contains the typed equivalents of the
+// pingdom.ingress-monitor.bonial.com annotations.
+type PingdomSpec struct {
+	// IntegrationIDs are the IDs of the integrations that are notified when
+	// the check changes state.
+	IntegrationIDs []int `json:"integrationIDs,omitempty"`
+
+	// ProbeFilters restrict the probes used for the check. Each filter has
+	// to be in the format "region: <region>".
+	ProbeFilters []string `json:"probeFilters,omitempty"`
+
+	// RequestHeaders are additional HTTP headers sent with each check.
+	RequestHeaders map[string]string `json:"requestHeaders,omitempty"`
+
+	// Resolution is the check interval in minutes. Valid values are 1, 5,
+	// 15, 30 and 60.
+	Resolution *int `json:"resolution,omitempty"`
+
+	// Tags are attached to the check.
+	Tags []string `json:"tags,omitempty"`
+
+	// TeamIDs are the IDs of the teams to alert.
+	TeamIDs []int `json:"teamIDs,omitempty"`
+
+	// UserIDs are the IDs of the users to alert.
+	UserIDs []int `json:"userIDs,omitempty"`
+}
+
+// BlackboxSpec contains the typed equivalents of the
+// blackbox.ingress-monitor.bonial.com annotations.
+type BlackboxSpec struct {
+	// Interval is the interval at which the target is probed, e.g. "30s".
+	Interval string `json:"interval,omitempty"`
+
+	// Module is the blackbox exporter module used for probing.
+	Module string `json:"module,omitempty"`
+
+	// ScrapeTimeout is the timeout for scraping the blackbox exporter.
+	ScrapeTimeout string `json:"scrapeTimeout,omitempty"`
+}
+
+// LocalSpec contains the typed equivalents of the
+// local.ingress-monitor.bonial.com annotations.
+type LocalSpec struct {
+	// Interval is the interval at which the monitor is probed.
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Timeout is the timeout of a single probe.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
@@ -3,8 +3,10 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 )
@@ -21,6 +23,55 @@ const (
 	// AnnotationPathOverride configures a custom path that should be monitored
 	// (e.g. "/health").
 	AnnotationPathOverride = "ingress-monitor.bonial.com/path-override"
+
+	// AnnotationMonitorPaths enables a separate monitor for every path of
+	// the ingress rules instead of a single monitor for the root path or the
+	// path override. If set to "true", all paths that can be probed are
+	// monitored. Alternatively, the value can be a comma-separated list of
+	// rule paths that should be monitored (e.g. "/api,/auth").
+	AnnotationMonitorPaths = "ingress-monitor.bonial.com/monitor-paths"
+
+	// AnnotationProbeHosts configures the hosts that are monitored instead
+	// of the hosts of the ingress. The value is a comma-separated list of
+	// concrete host names which must be matched by a host of the ingress.
+	// This allows monitoring ingresses with wildcard hosts (e.g.
+	// "*.example.com") by probing some of the hosts they serve (e.g.
+	// "shop.example.com").
+	AnnotationProbeHosts = "ingress-monitor.bonial.com/probe-hosts"
+
+	// AnnotationProvider selects the providers that manage the monitor for
+	// this ingress. The value is a comma-separated list of provider names
+	// which must either be passed via --provider or be listed in the
+	// enabledProviders of the provider config. If omitted, the providers
+	// passed via --provider are used.
+	AnnotationProvider = "ingress-monitor.bonial.com/provider"
+
+	// AnnotationProfile references a cluster-scoped MonitorProfile by name
+	// whose settings are used for the monitor unless they are overridden by
+	// provider specific annotations. Requires --monitor-profiles to be set.
+	AnnotationProfile = "ingress-monitor.bonial.com/profile"
+
+	// AnnotationAdopt allows the controller to take over an existing monitor
+	// with the same name which is not owned by this controller instance if
+	// set to "true", e.g. a monitor which was created manually.
+	AnnotationAdopt = "ingress-monitor.bonial.com/adopt"
+
+	// AnnotationMonitorName is maintained by the controller and records the
+	// name of the monitor that was last ensured for the ingress. If the
+	// rendered monitor name changes, e.g. because --name-template was
+	// changed, the monitor is renamed instead of being recreated.
+	AnnotationMonitorName = "ingress-monitor.bonial.com/monitor-name"
+
+	// AnnotationMonitorID is maintained by the controller and records the
+	// provider specific ID of the monitor. It is informational only and is
+	// omitted if the monitor is managed by multiple providers.
+	AnnotationMonitorID = "ingress-monitor.bonial.com/monitor-id"
+
+	// AnnotationStatus is maintained by the controller and contains the
+	// status of the last monitor sync as json, e.g. the last error. It allows
+	// users without access to the controller logs to find out why their
+	// monitor is not working.
+	AnnotationStatus = "ingress-monitor.bonial.com/status"
 )
 
 // Site24x7 Provider Annotations.
@@ -98,10 +149,194 @@ const (
 	AnnotationSite24x7UserGroupIDs = "site24x7.ingress-monitor.bonial.com/user-group-ids"
 )
 
+// UptimeRobot Provider Annotations.
+const (
+	// AnnotationUptimeRobotAlertContacts overrides the alert contacts for
+	// this monitor. Expects a comma separated list of alert contacts in the
+	// format "<id>_<threshold>_<recurrence>", e.g. "123456_0_0,789012_5_0".
+	AnnotationUptimeRobotAlertContacts = "uptimerobot.ingress-monitor.bonial.com/alert-contacts"
+
+	// AnnotationUptimeRobotCustomHTTPHeaders configures additional custom
+	// HTTP headers to send with each check. The value has to be a json
+	// object. Example:
+	//
+	//   uptimerobot.ingress-monitor.bonial.com/custom-http-headers: |
+	//     {"Content-Type":"application/json"}
+	//
+	AnnotationUptimeRobotCustomHTTPHeaders = "uptimerobot.ingress-monitor.bonial.com/custom-http-headers"
+
+	// AnnotationUptimeRobotHTTPMethod overrides the HTTP method to use for
+	// the check. Valid values are HEAD, GET, POST, PUT, PATCH, DELETE and
+	// OPTIONS.
+	AnnotationUptimeRobotHTTPMethod = "uptimerobot.ingress-monitor.bonial.com/http-method"
+
+	// AnnotationUptimeRobotHTTPPassword sets the password if basic auth is
+	// required.
+	AnnotationUptimeRobotHTTPPassword = "uptimerobot.ingress-monitor.bonial.com/http-password"
+
+	// AnnotationUptimeRobotHTTPUsername sets the username if basic auth is
+	// required.
+	AnnotationUptimeRobotHTTPUsername = "uptimerobot.ingress-monitor.bonial.com/http-username"
+
+	// AnnotationUptimeRobotInterval overrides the check interval in seconds.
+	AnnotationUptimeRobotInterval = "uptimerobot.ingress-monitor.bonial.com/interval"
+
+	// AnnotationUptimeRobotTimeout overrides the timeout in seconds for
+	// connecting to the website. Has to be in range 1-60.
+	AnnotationUptimeRobotTimeout = "uptimerobot.ingress-monitor.bonial.com/timeout"
+)
+
+// Pingdom Provider Annotations.
+const (
+	// AnnotationPingdomIntegrationIDs overrides the integrations that are
+	// notified when the check changes state. Expects a comma separated list of
+	// integration IDs.
+	AnnotationPingdomIntegrationIDs = "pingdom.ingress-monitor.bonial.com/integration-ids"
+
+	// AnnotationPingdomProbeFilters overrides the probe filters of the check.
+	// Expects a comma separated list of filters in the format
+	// "region: <region>", e.g. "region: EU".
+	AnnotationPingdomProbeFilters = "pingdom.ingress-monitor.bonial.com/probe-filters"
+
+	// AnnotationPingdomRequestHeaders configures additional custom HTTP
+	// headers to send with each check. The value has to be a json object.
+	// Example:
+	//
+	//   pingdom.ingress-monitor.bonial.com/request-headers: |
+	//     {"Content-Type":"application/json"}
+	//
+	AnnotationPingdomRequestHeaders = "pingdom.ingress-monitor.bonial.com/request-headers"
+
+	// AnnotationPingdomResolution overrides the check interval in minutes.
+	// Valid values are 1, 5, 15, 30 and 60.
+	AnnotationPingdomResolution = "pingdom.ingress-monitor.bonial.com/resolution"
+
+	// AnnotationPingdomTags overrides the tags of the check. Expects a comma
+	// separated list of tags.
+	AnnotationPingdomTags = "pingdom.ingress-monitor.bonial.com/tags"
+
+	// AnnotationPingdomTeamIDs overrides the teams to alert. Expects a comma
+	// separated list of team IDs.
+	AnnotationPingdomTeamIDs = "pingdom.ingress-monitor.bonial.com/team-ids"
+
+	// AnnotationPingdomUserIDs overrides the users to alert. Expects a comma
+	// separated list of user IDs.
+	AnnotationPingdomUserIDs = "pingdom.ingress-monitor.bonial.com/user-ids"
+)
+
+// Blackbox Provider Annotations.
+const (
+	// AnnotationBlackboxInterval overrides the interval at which the target
+	// is probed, e.g. "30s".
+	AnnotationBlackboxInterval = "blackbox.ingress-monitor.bonial.com/interval"
+
+	// AnnotationBlackboxModule overrides the blackbox exporter module used
+	// for probing, e.g. "http_post_2xx".
+	AnnotationBlackboxModule = "blackbox.ingress-monitor.bonial.com/module"
+
+	// AnnotationBlackboxScrapeTimeout overrides the timeout for scraping the
+	// blackbox exporter, e.g. "5s".
+	AnnotationBlackboxScrapeTimeout = "blackbox.ingress-monitor.bonial.com/scrape-timeout"
+)
+
+// Local Provider Annotations.
+const (
+	// AnnotationLocalInterval overrides the interval at which the monitor is
+	// probed, e.g. "1m".
+	AnnotationLocalInterval = "local.ingress-monitor.bonial.com/interval"
+
+	// AnnotationLocalTimeout overrides the timeout of a single probe, e.g.
+	// "5s".
+	AnnotationLocalTimeout = "local.ingress-monitor.bonial.com/timeout"
+)
+
+// Annotation value types used for validation.
+const (
+	valueTypeBool     = "bool"
+	valueTypeInt      = "int"
+	valueTypeIntSlice = "int slice"
+	valueTypeDuration = "duration"
+	valueTypeJSON     = "json"
+)
+
+// annotationValueTypes maps all annotations with typed values to the type of
+// their value. Annotations with string values are omitted.
+var annotationValueTypes = map[string]string{
+	AnnotationEnabled:                      valueTypeBool,
+	AnnotationForceHTTPS:                   valueTypeBool,
+	AnnotationAdopt:                        valueTypeBool,
+	AnnotationSite24x7Actions:              valueTypeJSON,
+	AnnotationSite24x7CustomHeaders:        valueTypeJSON,
+	AnnotationSite24x7MatchCase:            valueTypeBool,
+	AnnotationSite24x7Timeout:              valueTypeInt,
+	AnnotationSite24x7UseNameServer:        valueTypeBool,
+	AnnotationUptimeRobotCustomHTTPHeaders: valueTypeJSON,
+	AnnotationUptimeRobotInterval:          valueTypeInt,
+	AnnotationUptimeRobotTimeout:           valueTypeInt,
+	AnnotationPingdomIntegrationIDs:        valueTypeIntSlice,
+	AnnotationPingdomRequestHeaders:        valueTypeJSON,
+	AnnotationPingdomResolution:            valueTypeInt,
+	AnnotationPingdomTeamIDs:               valueTypeIntSlice,
+	AnnotationPingdomUserIDs:               valueTypeIntSlice,
+	AnnotationLocalInterval:                valueTypeDuration,
+	AnnotationLocalTimeout:                 valueTypeDuration,
+}
+
 // Annotations is a container for ingress annotations with added functionality
 // for parsing and defaulting annotation values.
 type Annotations map[string]string
 
+// Validate checks that the values of all known annotations can be parsed.
+// The accessor methods silently fall back to zero values for unparsable
+// values, so Validate can be used to report them to the user. Returns an
+// error for every invalid value, sorted by annotation name.
+func (a Annotations) Validate() []error {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		if _, ok := annotationValueTypes[name]; ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	var errs []error
+
+	for _, name := range names {
+		valueType := annotationValueTypes[name]
+
+		if err := validateValue(valueType, a[name]); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s value in annotation %q: %s: %v", valueType, name, a[name], err))
+		}
+	}
+
+	return errs
+}
+
+func validateValue(valueType, val string) error {
+	var err error
+
+	switch valueType {
+	case valueTypeBool:
+		_, err = strconv.ParseBool(val)
+	case valueTypeInt:
+		_, err = strconv.Atoi(val)
+	case valueTypeIntSlice:
+		for _, sval := range strings.Split(val, ",") {
+			if _, err = strconv.Atoi(strings.TrimSpace(sval)); err != nil {
+				break
+			}
+		}
+	case valueTypeDuration:
+		_, err = time.ParseDuration(val)
+	case valueTypeJSON:
+		var v interface{}
+		err = json.Unmarshal([]byte(val), &v)
+	}
+
+	return err
+}
+
 // StringValue returns the string value of an annotation. If the annotations
 // does not exist, the optional default value will be returned, empty string
 // otherwise.
@@ -133,6 +368,35 @@ func (a Annotations) StringSliceValue(name string, defaultValue ...[]string) []s
 	return nil
 }
 
+// IntSliceValue returns the int slice value of an annotation. The annotation
+// value is separated on commas. If the annotations does not exist, the
+// optional default value will be returned, nil otherwise. Elements that
+// cannot be parsed as int are omitted from the result.
+func (a Annotations) IntSliceValue(name string, defaultValue ...[]int) []int {
+	svals := a.StringSliceValue(name)
+	if len(svals) > 0 {
+		vals := make([]int, 0, len(svals))
+
+		for _, sval := range svals {
+			val, err := strconv.Atoi(strings.TrimSpace(sval))
+			if err != nil {
+				log.Errorf("invalid int value in annotation %q: %s", name, sval)
+				continue
+			}
+
+			vals = append(vals, val)
+		}
+
+		return vals
+	}
+
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+
+	return nil
+}
+
 // BoolValue returns the bool value of an annotation. If the annotations does
 // not exist, the optional default value will be returned, false otherwise. If
 // the annotation's value cannot be parsed as bool, false is returned.
@@ -173,6 +437,27 @@ func (a Annotations) IntValue(name string, defaultValue ...int) int {
 	return 0
 }
 
+// DurationValue returns the duration value of an annotation. If the
+// annotations does not exist, the optional default value will be returned,
+// zero otherwise. If the annotation's value cannot be parsed as duration, zero
+// is returned.
+func (a Annotations) DurationValue(name string, defaultValue ...time.Duration) time.Duration {
+	if sval, ok := a[name]; ok {
+		val, err := time.ParseDuration(sval)
+		if err != nil {
+			log.Errorf("invalid duration value in annotation %q: %s", name, sval)
+		}
+
+		return val
+	}
+
+	if len(defaultValue) > 0 {
+		return defaultValue[0]
+	}
+
+	return 0
+}
+
 // ParseJSON parses the value of the annotation into p. P must be a pointer. If
 // the annotation does not exist, p is not altered. JSON will return any errors
 // occurring during unmarshal operations.
@@ -2,6 +2,7 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,6 +15,8 @@ func TestAnnotations(t *testing.T) {
 		"c":           "42",
 		"d":           "foo,bar,baz",
 		"e":           `{"foo":"bar"}`,
+		"f":           "1, 2,x,3",
+		"g":           "1m30s",
 		"invalidjson": `{invalidjson`,
 	}
 
@@ -22,22 +25,28 @@ func TestAnnotations(t *testing.T) {
 	assert.Equal(t, true, annotations.BoolValue("b"))
 	assert.Equal(t, 42, annotations.IntValue("c"))
 	assert.Equal(t, []string{"foo", "bar", "baz"}, annotations.StringSliceValue("d"))
+	assert.Equal(t, []int{1, 2, 3}, annotations.IntSliceValue("f"))
+	assert.Equal(t, 90*time.Second, annotations.DurationValue("g"))
 
 	// default fallback
 	assert.Equal(t, "thedefault", annotations.StringValue("nonexistent", "thedefault"))
 	assert.Equal(t, true, annotations.BoolValue("nonexistent", true))
 	assert.Equal(t, 2, annotations.IntValue("nonexistent", 2))
 	assert.Equal(t, []string{"thedefault"}, annotations.StringSliceValue("nonexistent", []string{"thedefault"}))
+	assert.Equal(t, []int{42}, annotations.IntSliceValue("nonexistent", []int{42}))
+	assert.Equal(t, time.Minute, annotations.DurationValue("nonexistent", time.Minute))
 
-	// unparsable bool/int
+	// unparsable bool/int/duration
 	assert.Equal(t, false, annotations.BoolValue("invalidjson"))
 	assert.Equal(t, 0, annotations.IntValue("invalidjson"))
+	assert.Equal(t, time.Duration(0), annotations.DurationValue("invalidjson"))
 
 	// zero value
 	assert.Equal(t, "", annotations.StringValue("nonexistent"))
 	assert.Equal(t, false, annotations.BoolValue("nonexistent"))
 	assert.Equal(t, 0, annotations.IntValue("nonexistent"))
 	assert.Equal(t, []string(nil), annotations.StringSliceValue("nonexistent"))
+	assert.Equal(t, []int(nil), annotations.IntSliceValue("nonexistent"))
 
 	// json tests
 	dest := map[string]string{}
@@ -51,3 +60,25 @@ func TestAnnotations(t *testing.T) {
 	dest = map[string]string{}
 	require.Error(t, annotations.ParseJSON("invalidjson", &dest))
 }
+
+func TestAnnotations_Validate(t *testing.T) {
+	annotations := Annotations{
+		AnnotationEnabled:               "true",
+		AnnotationForceHTTPS:            "yes",
+		AnnotationPathOverride:          "/health",
+		AnnotationPingdomIntegrationIDs: "1, 2,x",
+		AnnotationLocalInterval:         "1m",
+		AnnotationLocalTimeout:          "5",
+		AnnotationSite24x7CustomHeaders: `[{"name":"foo"`,
+		"unknown":                       "whatever",
+	}
+
+	errs := annotations.Validate()
+	require.Len(t, errs, 4)
+	assert.Equal(t, `invalid bool value in annotation "ingress-monitor.bonial.com/force-https": yes: strconv.ParseBool: parsing "yes": invalid syntax`, errs[0].Error())
+	assert.Equal(t, `invalid duration value in annotation "local.ingress-monitor.bonial.com/timeout": 5: time: missing unit in duration "5"`, errs[1].Error())
+	assert.Equal(t, `invalid int slice value in annotation "pingdom.ingress-monitor.bonial.com/integration-ids": 1, 2,x: strconv.Atoi: parsing "x": invalid syntax`, errs[2].Error())
+	assert.Equal(t, `invalid json value in annotation "site24x7.ingress-monitor.bonial.com/custom-headers": [{"name":"foo": unexpected end of JSON input`, errs[3].Error())
+
+	assert.Empty(t, Annotations{AnnotationEnabled: "true"}.Validate())
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 const (
@@ -14,36 +15,84 @@ const (
 
 	// DefaultNameTemplate is the default template used for naming monitors.
 	DefaultNameTemplate = "{{.Namespace}}-{{.IngressName}}"
+
+	// DefaultLeaderElectionID is the default name of the lease used for
+	// leader election.
+	DefaultLeaderElectionID = "ingress-monitor-controller"
+
+	// DefaultMetricsBindAddress is the default address of the metrics
+	// endpoint.
+	DefaultMetricsBindAddress = ":8080"
+
+	// DefaultHealthProbeBindAddress is the default address of the liveness
+	// and readiness probe endpoints.
+	DefaultHealthProbeBindAddress = ":8081"
 )
 
 // Options holds the options that can be configured via cli flags.
 type Options struct {
 	ProviderConfigFile string
-	Namespace          string
-	ProviderName       string
+	ClusterID          string
+	AdoptUnowned       bool
+	Namespaces         []string
+	ExcludedNamespaces []string
+	IngressSelector    string
+	IngressClasses     []string
+	GatewayAPI         bool
+	IngressMonitors    bool
+	MonitorProfiles    bool
+	ProviderNames      []string
 	NameTemplate       string
 	NoDelete           bool
 	CreationDelay      time.Duration
+	ProviderTimeout    time.Duration
+	GCInterval         time.Duration
+	GCDryRun           bool
 	ProviderConfig     ProviderConfig
+
+	LeaderElection          bool
+	LeaderElectionID        string
+	LeaderElectionNamespace string
+	MetricsBindAddress      string
+	HealthProbeBindAddress  string
 }
 
 // NewDefaultOptions creates a new *Options value with defaults set.
 func NewDefaultOptions() *Options {
 	return &Options{
-		ProviderName:   DefaultProvider,
-		NameTemplate:   DefaultNameTemplate,
-		ProviderConfig: NewDefaultProviderConfig(),
+		ProviderNames:          []string{DefaultProvider},
+		NameTemplate:           DefaultNameTemplate,
+		ProviderConfig:         NewDefaultProviderConfig(),
+		LeaderElectionID:       DefaultLeaderElectionID,
+		MetricsBindAddress:     DefaultMetricsBindAddress,
+		HealthProbeBindAddress: DefaultHealthProbeBindAddress,
 	}
 }
 
 // AddFlags adds cli flags for configurable options to the command.
 func (o *Options) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.ClusterID, "cluster-id", o.ClusterID, "ID of the cluster which is recorded in the ownership metadata of monitors. Monitors owned by other clusters are never modified. Must be unique if multiple clusters share provider accounts.")
+	cmd.Flags().BoolVar(&o.AdoptUnowned, "adopt-unowned", o.AdoptUnowned, "If set, monitors without ownership metadata are treated as owned. Enable temporarily when upgrading from versions which did not record ownership, so that their monitors are still updated and deleted.")
 	cmd.Flags().BoolVar(&o.NoDelete, "no-delete", o.NoDelete, "If set, monitors will not be deleted if the ingress is deleted.")
 	cmd.Flags().DurationVar(&o.CreationDelay, "creation-delay", o.CreationDelay, "Duration to wait after an ingress is created before creating the monitor for it.")
-	cmd.Flags().StringVar(&o.NameTemplate, "name-template", o.NameTemplate, "The template to use for the monitor name. Valid fields are: .IngressName, .Namespace.")
-	cmd.Flags().StringVar(&o.Namespace, "namespace", o.Namespace, "Namespace to watch. If empty, all namespaces are watched.")
+	cmd.Flags().DurationVar(&o.GCInterval, "gc-interval", o.GCInterval, "Interval for garbage collection of monitors whose ingress does not exist anymore. If zero, garbage collection is disabled.")
+	cmd.Flags().BoolVar(&o.GCDryRun, "gc-dry-run", o.GCDryRun, "If set, garbage collection only reports orphaned monitors instead of deleting them.")
+	cmd.Flags().DurationVar(&o.ProviderTimeout, "provider-timeout", o.ProviderTimeout, "Timeout for all provider calls of a single monitor operation. If zero, no timeout is applied.")
+	cmd.Flags().StringVar(&o.NameTemplate, "name-template", o.NameTemplate, "The template to use for the monitor name. Valid fields are: .IngressName, .Namespace, .Kind, .Host, .Path. If .Host is used, a monitor is created for every host of an ingress.")
+	cmd.Flags().StringSliceVar(&o.Namespaces, "namespace", o.Namespaces, "Comma-separated list of namespaces to watch. If empty, all namespaces are watched.")
+	cmd.Flags().StringSliceVar(&o.ExcludedNamespaces, "exclude-namespace", o.ExcludedNamespaces, "Comma-separated list of namespaces to ignore. Cannot be combined with --namespace.")
+	cmd.Flags().StringVar(&o.IngressSelector, "ingress-selector", o.IngressSelector, "Label selector for the ingresses to manage monitors for, e.g. \"team=foo\". If empty, all ingresses are considered.")
+	cmd.Flags().StringSliceVar(&o.IngressClasses, "ingress-class", o.IngressClasses, "Comma-separated list of ingress classes to manage monitors for. If empty, ingresses of all classes are considered.")
+	cmd.Flags().BoolVar(&o.GatewayAPI, "gateway-api", o.GatewayAPI, "If set, monitors are also managed for Gateway API HTTPRoutes. Requires the gateway.networking.k8s.io/v1 CRDs to be installed.")
+	cmd.Flags().BoolVar(&o.IngressMonitors, "ingress-monitors", o.IngressMonitors, "If set, monitors are also managed for IngressMonitor custom resources. Requires the CRD from deploy/crd.yaml to be installed.")
+	cmd.Flags().BoolVar(&o.MonitorProfiles, "monitor-profiles", o.MonitorProfiles, "If set, MonitorProfile custom resources referenced via the ingress-monitor.bonial.com/profile annotation are applied to monitors. Requires the CRD from deploy/crd.yaml to be installed.")
 	cmd.Flags().StringVar(&o.ProviderConfigFile, "provider-config", o.ProviderConfigFile, "Location of the config file for the monitor providers.")
-	cmd.Flags().StringVar(&o.ProviderName, "provider", o.ProviderName, "The provider to use for creating monitors.")
+	cmd.Flags().StringSliceVar(&o.ProviderNames, "provider", o.ProviderNames, "Comma-separated list of providers to use for creating monitors. If multiple providers are given, monitors are managed in all of them.")
+	cmd.Flags().BoolVar(&o.LeaderElection, "leader-elect", o.LeaderElection, "Enable leader election. Required when running more than one replica, otherwise all replicas reconcile concurrently and may create duplicate monitors.")
+	cmd.Flags().StringVar(&o.LeaderElectionID, "leader-election-id", o.LeaderElectionID, "Name of the lease used for leader election.")
+	cmd.Flags().StringVar(&o.LeaderElectionNamespace, "leader-election-namespace", o.LeaderElectionNamespace, "Namespace of the lease used for leader election. If empty, the namespace of the controller pod is used.")
+	cmd.Flags().StringVar(&o.MetricsBindAddress, "metrics-bind-address", o.MetricsBindAddress, "Address the metrics endpoint binds to. Set to \"0\" to disable it.")
+	cmd.Flags().StringVar(&o.HealthProbeBindAddress, "health-probe-bind-address", o.HealthProbeBindAddress, "Address the /healthz and /readyz endpoints bind to. Set to \"0\" to disable them.")
 }
 
 // Validate validates options.
@@ -52,13 +101,75 @@ func (o *Options) Validate() error {
 		return errors.Errorf("--creation-delay has to be greater than or equal to 0s")
 	}
 
+	if o.ProviderTimeout < 0 {
+		return errors.Errorf("--provider-timeout has to be greater than or equal to 0s")
+	}
+
+	if o.GCInterval < 0 {
+		return errors.Errorf("--gc-interval has to be greater than or equal to 0s")
+	}
+
+	if o.GCInterval > 0 && o.ClusterID == "" {
+		return errors.Errorf("--cluster-id must not be empty if garbage collection is enabled")
+	}
+
 	if o.NameTemplate == "" {
 		return errors.Errorf("--name-template must not be empty")
 	}
 
-	if o.ProviderName == "" {
+	if o.LeaderElection && o.LeaderElectionID == "" {
+		return errors.Errorf("--leader-election-id must not be empty if leader election is enabled")
+	}
+
+	if len(o.ProviderNames) == 0 {
 		return errors.Errorf("--provider must not be empty")
 	}
 
+	if len(o.Namespaces) > 0 && len(o.ExcludedNamespaces) > 0 {
+		return errors.Errorf("--namespace and --exclude-namespace are mutually exclusive")
+	}
+
+	if contains(o.Namespaces, "") || contains(o.ExcludedNamespaces, "") {
+		return errors.Errorf("--namespace and --exclude-namespace must not contain empty namespaces")
+	}
+
+	if _, err := labels.Parse(o.IngressSelector); err != nil {
+		return errors.Wrapf(err, "--ingress-selector is invalid")
+	}
+
+	seen := make(map[string]bool, len(o.ProviderNames))
+
+	for _, name := range o.ProviderNames {
+		if name == "" {
+			return errors.Errorf("--provider must not contain empty provider names")
+		}
+
+		if seen[name] {
+			return errors.Errorf("--provider must not contain duplicate provider %q", name)
+		}
+
+		seen[name] = true
+	}
+
 	return nil
 }
+
+// WatchesNamespace returns true if ingresses in namespace are managed by the
+// controller according to the --namespace and --exclude-namespace flags.
+func (o *Options) WatchesNamespace(namespace string) bool {
+	if len(o.Namespaces) > 0 {
+		return contains(o.Namespaces, namespace)
+	}
+
+	return !contains(o.ExcludedNamespaces, namespace)
+}
+
+func contains(s []string, e string) bool {
+	for _, v := range s {
+		if v == e {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,7 +2,9 @@ package config
 
 import (
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,7 +23,109 @@ func TestOptions_Validate(t *testing.T) {
 			name: "provider name must not be empty",
 			options: func() *Options {
 				o := NewDefaultOptions()
-				o.ProviderName = ""
+				o.ProviderNames = nil
+				return o
+			}(),
+			valid: false,
+		},
+		{
+			name: "multiple providers are valid",
+			options: func() *Options {
+				o := NewDefaultOptions()
+				o.ProviderNames = []string{ProviderSite24x7, ProviderUptimeRobot}
+				return o
+			}(),
+			valid: true,
+		},
+		{
+			name: "provider names must not contain empty values",
+			options: func() *Options {
+				o := NewDefaultOptions()
+				o.ProviderNames = []string{ProviderSite24x7, ""}
+				return o
+			}(),
+			valid: false,
+		},
+		{
+			name: "provider names must not contain duplicates",
+			options: func() *Options {
+				o := NewDefaultOptions()
+				o.ProviderNames = []string{ProviderSite24x7, ProviderSite24x7}
+				return o
+			}(),
+			valid: false,
+		},
+		{
+			name: "provider timeout must not be negative",
+			options: func() *Options {
+				o := NewDefaultOptions()
+				o.ProviderTimeout = -1
+				return o
+			}(),
+			valid: false,
+		},
+		{
+			name: "gc interval must not be negative",
+			options: func() *Options {
+				o := NewDefaultOptions()
+				o.GCInterval = -1
+				return o
+			}(),
+			valid: false,
+		},
+		{
+			name: "gc requires cluster id",
+			options: func() *Options {
+				o := NewDefaultOptions()
+				o.GCInterval = time.Hour
+				return o
+			}(),
+			valid: false,
+		},
+		{
+			name: "gc with cluster id is valid",
+			options: func() *Options {
+				o := NewDefaultOptions()
+				o.GCInterval = time.Hour
+				o.ClusterID = "prod"
+				return o
+			}(),
+			valid: true,
+		},
+		{
+			name: "namespaces and excluded namespaces are mutually exclusive",
+			options: func() *Options {
+				o := NewDefaultOptions()
+				o.Namespaces = []string{"foo"}
+				o.ExcludedNamespaces = []string{"kube-system"}
+				return o
+			}(),
+			valid: false,
+		},
+		{
+			name: "namespaces must not contain empty values",
+			options: func() *Options {
+				o := NewDefaultOptions()
+				o.Namespaces = []string{"foo", ""}
+				return o
+			}(),
+			valid: false,
+		},
+		{
+			name: "ingress selector must be valid",
+			options: func() *Options {
+				o := NewDefaultOptions()
+				o.IngressSelector = "foo in (bar"
+				return o
+			}(),
+			valid: false,
+		},
+		{
+			name: "leader election id must not be empty if leader election is enabled",
+			options: func() *Options {
+				o := NewDefaultOptions()
+				o.LeaderElection = true
+				o.LeaderElectionID = ""
 				return o
 			}(),
 			valid: false,
@@ -48,3 +152,49 @@ func TestOptions_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestOptions_WatchesNamespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		options   *Options
+		namespace string
+		expected  bool
+	}{
+		{
+			name:      "all namespaces are watched by default",
+			options:   &Options{},
+			namespace: "foo",
+			expected:  true,
+		},
+		{
+			name:      "listed namespace",
+			options:   &Options{Namespaces: []string{"foo", "bar"}},
+			namespace: "bar",
+			expected:  true,
+		},
+		{
+			name:      "namespace not listed",
+			options:   &Options{Namespaces: []string{"foo", "bar"}},
+			namespace: "baz",
+			expected:  false,
+		},
+		{
+			name:      "excluded namespace",
+			options:   &Options{ExcludedNamespaces: []string{"kube-system"}},
+			namespace: "kube-system",
+			expected:  false,
+		},
+		{
+			name:      "namespace not excluded",
+			options:   &Options{ExcludedNamespaces: []string{"kube-system"}},
+			namespace: "foo",
+			expected:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.options.WatchesNamespace(test.namespace))
+		})
+	}
+}
@@ -3,8 +3,10 @@ package config
 import (
 	"io/ioutil"
 	"os"
+	"time"
 
 	site24x7api "github.com/Bonial-International-GmbH/site24x7-go/api"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
 
@@ -12,15 +14,52 @@ const (
 	// ProviderSite24x7 uses Site24x7 for managing ingress monitors.
 	ProviderSite24x7 = "site24x7"
 
+	// ProviderUptimeRobot uses UptimeRobot for managing ingress monitors.
+	ProviderUptimeRobot = "uptimerobot"
+
+	// ProviderPingdom uses Pingdom for managing ingress monitors.
+	ProviderPingdom = "pingdom"
+
+	// ProviderBlackbox manages prometheus-operator Probe resources which are
+	// scraped via a blackbox exporter running in the cluster.
+	ProviderBlackbox = "blackbox"
+
+	// ProviderLocal probes monitors from the controller itself and exposes
+	// the results as prometheus metrics. This is intended for development
+	// clusters and end-to-end tests.
+	ProviderLocal = "local"
+
+	// ProviderWebhook manages monitors via a generic REST API.
+	ProviderWebhook = "webhook"
+
 	// ProviderNull does nothing but log create/update/delete monitor events.
 	// This is intended for testing purposes only.
 	ProviderNull = "null"
+
+	// ProviderPluginPrefix is the prefix of provider names that refer to
+	// out-of-process provider plugins configured in the plugins section of
+	// the provider config, e.g. "plugin:foo".
+	ProviderPluginPrefix = "plugin:"
 )
 
 // ProviderConfig contains the configuration for all supported monitor
 // providers.
 type ProviderConfig struct {
-	Site24x7 Site24x7Config `json:"site24x7"`
+	// EnabledProviders is a list of providers that can be selected on a
+	// per-ingress basis via the ingress-monitor.bonial.com/provider
+	// annotation in addition to the providers passed via --provider.
+	EnabledProviders []string `json:"enabledProviders"`
+
+	Site24x7    Site24x7Config    `json:"site24x7"`
+	UptimeRobot UptimeRobotConfig `json:"uptimerobot"`
+	Pingdom     PingdomConfig     `json:"pingdom"`
+	Blackbox    BlackboxConfig    `json:"blackbox"`
+	Local       LocalConfig       `json:"local"`
+	Webhook     WebhookConfig     `json:"webhook"`
+
+	// Plugins configures out-of-process provider plugins by name. A plugin
+	// named foo is selected via the provider name "plugin:foo".
+	Plugins map[string]PluginConfig `json:"plugins"`
 }
 
 // Site24x7Config is the configration for the Site24x7 website monitor
@@ -40,6 +79,13 @@ type Site24x7Config struct {
 	// environment variable.
 	RefreshToken string `json:"refreshToken"`
 
+	// MonitorCacheRefreshInterval is the interval at which the index of all
+	// Site24x7 monitors is refreshed from the API. Between refreshes, monitor
+	// lookups are served from the index, which is kept up to date with the
+	// changes made by the controller. If zero, the monitors are listed on
+	// every lookup.
+	MonitorCacheRefreshInterval metav1.Duration `json:"monitorCacheRefreshInterval"`
+
 	// MonitorDefaults contain defaults that apply to all monitors. The
 	// defaults can be overridden explicitly for each monitor via ingress
 	// annotations (see annotations.go for all available annotations).
@@ -140,13 +186,285 @@ type Site24x7MonitorDefaults struct {
 	UserGroupIDs []string `json:"userGroupIDs"`
 }
 
+// UptimeRobotConfig is the configuration for the UptimeRobot website monitor
+// provider.
+type UptimeRobotConfig struct {
+	// APIKey is the main API key provided by UptimeRobot. If not specified,
+	// the value will be read from the UPTIMEROBOT_API_KEY environment
+	// variable.
+	APIKey string `json:"apiKey"`
+
+	// MonitorDefaults contain defaults that apply to all monitors. The
+	// defaults can be overridden explicitly for each monitor via ingress
+	// annotations (see annotations.go for all available annotations).
+	MonitorDefaults UptimeRobotMonitorDefaults `json:"monitorDefaults"`
+}
+
+// UptimeRobotMonitorDefaults define the monitor defaults that are used for
+// each monitor if not overridden explicitly via ingress annotations.
+type UptimeRobotMonitorDefaults struct {
+	// AlertContacts configures the default alert contacts. Each element must
+	// be in the format expected by the UptimeRobot API, that is
+	// "<id>_<threshold>_<recurrence>", e.g. "123456_0_0". See
+	// https://uptimerobot.com/api/ for details.
+	AlertContacts []string `json:"alertContacts"`
+
+	// CustomHTTPHeaders configures additional custom HTTP headers to send
+	// with each check.
+	CustomHTTPHeaders map[string]string `json:"customHTTPHeaders"`
+
+	// HTTPMethod sets the default HTTP method to use for all checks. Valid
+	// values are HEAD, GET, POST, PUT, PATCH, DELETE and OPTIONS.
+	HTTPMethod string `json:"httpMethod"`
+
+	// HTTPPassword sets the default password for endpoints requiring basic
+	// auth.
+	HTTPPassword string `json:"httpPassword"`
+
+	// HTTPUsername sets the default user for endpoints requiring basic auth.
+	HTTPUsername string `json:"httpUsername"`
+
+	// Interval configures the default check interval in seconds.
+	Interval int `json:"interval"`
+
+	// Timeout configures the default timeout in seconds for connecting to
+	// the monitored website. Has to be in range 1-60.
+	Timeout int `json:"timeout"`
+}
+
+// PingdomConfig is the configuration for the Pingdom HTTP check provider.
+type PingdomConfig struct {
+	// APIToken is the API token provided by Pingdom. If not specified, the
+	// value will be read from the PINGDOM_API_TOKEN environment variable.
+	APIToken string `json:"apiToken"`
+
+	// MonitorCacheRefreshInterval is the interval at which the index of all
+	// Pingdom checks is refreshed from the API. Between refreshes, check
+	// lookups are served from the index, which is kept up to date with the
+	// changes made by the controller. If zero, the checks are listed on
+	// every lookup.
+	MonitorCacheRefreshInterval metav1.Duration `json:"monitorCacheRefreshInterval"`
+
+	// MonitorDefaults contain defaults that apply to all checks. The
+	// defaults can be overridden explicitly for each check via ingress
+	// annotations (see annotations.go for all available annotations).
+	MonitorDefaults PingdomMonitorDefaults `json:"monitorDefaults"`
+}
+
+// PingdomMonitorDefaults define the check defaults that are used for each
+// check if not overridden explicitly via ingress annotations.
+type PingdomMonitorDefaults struct {
+	// IntegrationIDs configures the IDs of the integrations (e.g. webhooks)
+	// that are notified when the check changes state.
+	IntegrationIDs []int `json:"integrationIDs"`
+
+	// ProbeFilters restricts the probes used for checks. Each filter has to
+	// be in the format "region: <region>", where region is one of EU, NA,
+	// APAC or LATAM. If empty, all probes are used.
+	ProbeFilters []string `json:"probeFilters"`
+
+	// RequestHeaders configures additional custom HTTP headers to send with
+	// each check.
+	RequestHeaders map[string]string `json:"requestHeaders"`
+
+	// Resolution configures the default check interval in minutes. Valid
+	// values are 1, 5, 15, 30 and 60.
+	Resolution int `json:"resolution"`
+
+	// Tags configures the default tags attached to all checks.
+	Tags []string `json:"tags"`
+
+	// TeamIDs configures the IDs of the teams to alert.
+	TeamIDs []int `json:"teamIDs"`
+
+	// UserIDs configures the IDs of the users to alert.
+	UserIDs []int `json:"userIDs"`
+}
+
+// BlackboxConfig is the configuration for the blackbox exporter provider which
+// materializes monitors as prometheus-operator Probe resources.
+type BlackboxConfig struct {
+	// Namespace is the namespace in which the Probe resources are created.
+	Namespace string `json:"namespace"`
+
+	// Labels are added to all Probe resources. They can be used to match the
+	// probeSelector of the Prometheus resource.
+	Labels map[string]string `json:"labels"`
+
+	// ProberURL is the address of the blackbox exporter, e.g.
+	// "blackbox-exporter.monitoring.svc:9115".
+	ProberURL string `json:"proberURL"`
+
+	// ProberScheme is the scheme used to reach the blackbox exporter.
+	ProberScheme string `json:"proberScheme"`
+
+	// ProberPath is the path of the probe endpoint of the blackbox exporter.
+	ProberPath string `json:"proberPath"`
+
+	// SourceRanges are the CIDR blocks that the blackbox exporter performs
+	// its checks from, e.g. the egress IPs of the cluster.
+	SourceRanges []string `json:"sourceRanges"`
+
+	// MonitorDefaults contain defaults that apply to all Probes. The
+	// defaults can be overridden explicitly for each Probe via ingress
+	// annotations (see annotations.go for all available annotations).
+	MonitorDefaults BlackboxMonitorDefaults `json:"monitorDefaults"`
+}
+
+// BlackboxMonitorDefaults define the Probe defaults that are used for each
+// Probe if not overridden explicitly via ingress annotations.
+type BlackboxMonitorDefaults struct {
+	// Interval is the default interval at which targets are probed, e.g.
+	// "60s".
+	Interval string `json:"interval"`
+
+	// Module is the default blackbox exporter module to use for probing,
+	// e.g. "http_2xx".
+	Module string `json:"module"`
+
+	// ScrapeTimeout is the default timeout for scraping the blackbox
+	// exporter, e.g. "10s". If empty, the Prometheus default is used.
+	ScrapeTimeout string `json:"scrapeTimeout"`
+}
+
+// LocalConfig is the configuration for the local provider which probes
+// monitors from the controller itself.
+type LocalConfig struct {
+	// SourceRanges are the CIDR blocks that the controller performs its
+	// checks from, e.g. the egress IPs of the cluster.
+	SourceRanges []string `json:"sourceRanges"`
+
+	// MonitorDefaults contain defaults that apply to all monitors. The
+	// defaults can be overridden explicitly for each monitor via ingress
+	// annotations (see annotations.go for all available annotations).
+	MonitorDefaults LocalMonitorDefaults `json:"monitorDefaults"`
+}
+
+// LocalMonitorDefaults define the monitor defaults that are used for each
+// monitor if not overridden explicitly via ingress annotations.
+type LocalMonitorDefaults struct {
+	// Interval is the default interval at which monitors are probed.
+	Interval metav1.Duration `json:"interval"`
+
+	// Timeout is the default timeout of a single probe.
+	Timeout metav1.Duration `json:"timeout"`
+
+	// InsecureSkipVerify disables verification of TLS certificates. The TLS
+	// expiry metric is still populated.
+	InsecureSkipVerify bool `json:"insecureSkipVerify"`
+}
+
+// WebhookConfig is the configuration for the webhook provider which manages
+// monitors via a generic REST API.
+type WebhookConfig struct {
+	// BaseURL is prepended to all endpoints that are not absolute URLs.
+	BaseURL string `json:"baseURL"`
+
+	// Endpoints configures the endpoints for the monitor operations.
+	Endpoints WebhookEndpoints `json:"endpoints"`
+
+	// Headers are additional HTTP headers that are sent with every request,
+	// e.g. for authentication.
+	Headers map[string]string `json:"headers"`
+
+	// Secret is used to sign requests with HMAC-SHA256. The signature covers
+	// the timestamp, the method, the URL path including the query and the
+	// body of the request, each separated by a newline. It is sent
+	// hex-encoded in the SignatureHeader in the format "sha256=<signature>".
+	// If not specified, the value will be read from the WEBHOOK_SECRET
+	// environment variable. Requests are not signed if the secret is empty.
+	Secret string `json:"secret"`
+
+	// SignatureHeader is the name of the header containing the request
+	// signature.
+	SignatureHeader string `json:"signatureHeader"`
+
+	// TimestampHeader is the name of the header containing the time the
+	// request was signed at as Unix timestamp in seconds. Receivers should
+	// reject requests with old timestamps to prevent replays.
+	TimestampHeader string `json:"timestampHeader"`
+
+	// IDField is the field of the JSON responses of the create and get
+	// endpoints which contains the monitor ID. Nested fields are separated
+	// by dots, e.g. "data.id".
+	IDField string `json:"idField"`
+
+	// MaxRetries is the number of times a GET, PUT or DELETE request is
+	// retried if it fails with a network error, status 429 or a 5xx status.
+	// Create requests are never retried, as they are not idempotent.
+	MaxRetries int `json:"maxRetries"`
+
+	// RetryBackoff is the time to wait before the first retry. It is
+	// increased linearly for subsequent retries.
+	RetryBackoff metav1.Duration `json:"retryBackoff"`
+
+	// Timeout is the timeout for a single request.
+	Timeout metav1.Duration `json:"timeout"`
+
+	// SourceRanges are static source ranges that are used if the
+	// sourceRanges endpoint is not configured.
+	SourceRanges []string `json:"sourceRanges"`
+}
+
+// WebhookEndpoints are the endpoints used by the webhook provider. Each
+// endpoint is a text/template which is rendered with the fields .ID and
+// .Name of the monitor. The values are path escaped.
+type WebhookEndpoints struct {
+	// Create is the endpoint monitors are POSTed to.
+	Create string `json:"create"`
+
+	// Get is the endpoint for retrieving a monitor by name via GET. It must
+	// respond with status 404 if the monitor does not exist.
+	Get string `json:"get"`
+
+	// Update is the endpoint monitors are PUT to.
+	Update string `json:"update"`
+
+	// Delete is the endpoint for deleting monitors via DELETE.
+	Delete string `json:"delete"`
+
+	// SourceRanges is the endpoint that responds with a JSON array of CIDR
+	// blocks the checks are performed from. Optional.
+	SourceRanges string `json:"sourceRanges"`
+
+	// List is the endpoint that responds with a JSON array of all monitors
+	// in the same format as the get endpoint. Optional, but required for
+	// garbage collection of orphaned monitors.
+	List string `json:"list"`
+}
+
+// PluginConfig is the configuration for an out-of-process provider plugin.
+// Plugins speak JSON-RPC, see pkg/provider/plugin for the protocol.
+type PluginConfig struct {
+	// Command is the path to the plugin binary. If set, the plugin is
+	// launched by the controller and the protocol is spoken over its stdin
+	// and stdout.
+	Command string `json:"command"`
+
+	// Args are passed to the plugin command.
+	Args []string `json:"args"`
+
+	// Env contains additional environment variables for the plugin command.
+	// The environment of the controller is inherited.
+	Env map[string]string `json:"env"`
+
+	// Address is the address of an already running plugin, e.g.
+	// unix:///var/run/plugin.sock or tcp://localhost:9000. Ignored if Command
+	// is set.
+	Address string `json:"address"`
+
+	// DialTimeout is the timeout for connecting to the plugin at Address.
+	DialTimeout metav1.Duration `json:"dialTimeout"`
+}
+
 // NewDefaultProviderConfig creates a new default provider config.
 func NewDefaultProviderConfig() ProviderConfig {
 	return ProviderConfig{
 		Site24x7: Site24x7Config{
-			ClientID:     os.Getenv("SITE24X7_CLIENT_ID"),
-			ClientSecret: os.Getenv("SITE24X7_CLIENT_SECRET"),
-			RefreshToken: os.Getenv("SITE24X7_REFRESH_TOKEN"),
+			ClientID:                    os.Getenv("SITE24X7_CLIENT_ID"),
+			ClientSecret:                os.Getenv("SITE24X7_CLIENT_SECRET"),
+			RefreshToken:                os.Getenv("SITE24X7_REFRESH_TOKEN"),
+			MonitorCacheRefreshInterval: metav1.Duration{Duration: 5 * time.Minute},
 			MonitorDefaults: Site24x7MonitorDefaults{
 				AutoLocationProfile:     true,
 				AutoNotificationProfile: true,
@@ -161,6 +479,65 @@ func NewDefaultProviderConfig() ProviderConfig {
 				Actions:                 []site24x7api.ActionRef{},
 			},
 		},
+		UptimeRobot: UptimeRobotConfig{
+			APIKey: os.Getenv("UPTIMEROBOT_API_KEY"),
+			MonitorDefaults: UptimeRobotMonitorDefaults{
+				AlertContacts:     []string{},
+				CustomHTTPHeaders: map[string]string{},
+				HTTPMethod:        "GET",
+				Interval:          300,
+				Timeout:           30,
+			},
+		},
+		Pingdom: PingdomConfig{
+			APIToken:                    os.Getenv("PINGDOM_API_TOKEN"),
+			MonitorCacheRefreshInterval: metav1.Duration{Duration: 5 * time.Minute},
+			MonitorDefaults: PingdomMonitorDefaults{
+				IntegrationIDs: []int{},
+				ProbeFilters:   []string{},
+				RequestHeaders: map[string]string{},
+				Resolution:     1,
+				Tags:           []string{},
+				TeamIDs:        []int{},
+				UserIDs:        []int{},
+			},
+		},
+		Blackbox: BlackboxConfig{
+			Namespace:    "monitoring",
+			Labels:       map[string]string{},
+			ProberURL:    "blackbox-exporter.monitoring.svc:9115",
+			ProberScheme: "http",
+			ProberPath:   "/probe",
+			SourceRanges: []string{},
+			MonitorDefaults: BlackboxMonitorDefaults{
+				Interval: "60s",
+				Module:   "http_2xx",
+			},
+		},
+		Local: LocalConfig{
+			SourceRanges: []string{},
+			MonitorDefaults: LocalMonitorDefaults{
+				Interval: metav1.Duration{Duration: 30 * time.Second},
+				Timeout:  metav1.Duration{Duration: 10 * time.Second},
+			},
+		},
+		Webhook: WebhookConfig{
+			Endpoints: WebhookEndpoints{
+				Create: "/monitors",
+				Get:    "/monitors/{{.Name}}",
+				Update: "/monitors/{{.ID}}",
+				Delete: "/monitors/{{.ID}}",
+			},
+			Headers:         map[string]string{},
+			Secret:          os.Getenv("WEBHOOK_SECRET"),
+			SignatureHeader: "X-Ingress-Monitor-Signature",
+			TimestampHeader: "X-Ingress-Monitor-Timestamp",
+			IDField:         "id",
+			MaxRetries:      3,
+			RetryBackoff:    metav1.Duration{Duration: time.Second},
+			Timeout:         metav1.Duration{Duration: 10 * time.Second},
+			SourceRanges:    []string{},
+		},
 	}
 }
 
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CacheOptions returns the options for the cache of the controller manager.
+// The cache is restricted to the namespaces that are watched according to
+// options, so that the controller only requires namespaced RBAC permissions
+// if --namespace is set. Excluded namespaces are filtered out using a field
+// selector.
+func CacheOptions(options *config.Options) cache.Options {
+	if len(options.Namespaces) > 0 {
+		namespaces := make(map[string]cache.Config, len(options.Namespaces))
+		for _, namespace := range options.Namespaces {
+			namespaces[namespace] = cache.Config{}
+		}
+
+		return cache.Options{DefaultNamespaces: namespaces}
+	}
+
+	if len(options.ExcludedNamespaces) > 0 {
+		selectors := make([]fields.Selector, len(options.ExcludedNamespaces))
+		for i, namespace := range options.ExcludedNamespaces {
+			selectors[i] = fields.OneTermNotEqualSelector("metadata.namespace", namespace)
+		}
+
+		return cache.Options{DefaultFieldSelector: fields.AndSelectors(selectors...)}
+	}
+
+	return cache.Options{}
+}
+
+// ClientOptions returns the options for the client of the controller
+// manager. Secrets are only read on demand for the passwords referenced by
+// IngressMonitors and MonitorProfiles, so they are not cached. This avoids
+// watching all secrets and requires only the get permission.
+func ClientOptions() client.Options {
+	return client.Options{
+		Cache: &client.CacheOptions{
+			DisableFor: []client.Object{&corev1.Secret{}},
+		},
+	}
+}
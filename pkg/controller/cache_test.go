@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+func TestCacheOptions(t *testing.T) {
+	t.Run("all namespaces", func(t *testing.T) {
+		opts := CacheOptions(&config.Options{})
+
+		assert.Nil(t, opts.DefaultNamespaces)
+		assert.Nil(t, opts.DefaultFieldSelector)
+	})
+
+	t.Run("restricted to namespaces", func(t *testing.T) {
+		opts := CacheOptions(&config.Options{Namespaces: []string{"foo", "bar"}})
+
+		assert.Equal(t, map[string]cache.Config{"foo": {}, "bar": {}}, opts.DefaultNamespaces)
+		assert.Nil(t, opts.DefaultFieldSelector)
+	})
+
+	t.Run("excluded namespaces", func(t *testing.T) {
+		opts := CacheOptions(&config.Options{ExcludedNamespaces: []string{"kube-system", "monitoring"}})
+
+		assert.Nil(t, opts.DefaultNamespaces)
+		assert.Equal(t, "metadata.namespace!=kube-system,metadata.namespace!=monitoring", opts.DefaultFieldSelector.String())
+	})
+}
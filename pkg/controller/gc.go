@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/gateway"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("garbage-collector")
+
+// GarbageCollector periodically deletes monitors owned by the controller
+// whose ingress, HTTPRoute or IngressMonitor does not exist or does not have
+// monitoring enabled anymore.
+// This cleans up monitors that were missed by the reconciler, e.g. because
+// the ingress was deleted while the controller was not running. It
+// implements manager.Runnable.
+type GarbageCollector struct {
+	client          client.Client
+	monitorService  monitor.Service
+	selector        *IngressSelector
+	interval        time.Duration
+	gatewayAPI      bool
+	ingressMonitors bool
+}
+
+// NewGarbageCollector creates a new *GarbageCollector. The client should be
+// backed by the manager's cache as it is queried for every owned monitor.
+// Monitors of objects which are not matched by selector are left alone, as
+// those objects may be handled by another controller instance. Monitors of
+// HTTPRoutes are considered orphaned if Gateway API support is disabled and
+// monitors of IngressMonitors if IngressMonitor support is disabled.
+func NewGarbageCollector(client client.Client, monitorService monitor.Service, selector *IngressSelector, options *config.Options) *GarbageCollector {
+	return &GarbageCollector{
+		client:          client,
+		monitorService:  monitorService,
+		selector:        selector,
+		interval:        options.GCInterval,
+		gatewayAPI:      options.GatewayAPI,
+		ingressMonitors: options.IngressMonitors,
+	}
+}
+
+// Start runs garbage collection on every interval until ctx is done. It
+// implements manager.Runnable.
+func (c *GarbageCollector) Start(ctx context.Context) error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		err := c.Collect(ctx)
+		if err != nil {
+			log.Error(err, "garbage collection failed")
+		}
+	}
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable. Garbage
+// collection must only be performed by the leader.
+func (c *GarbageCollector) NeedLeaderElection() bool {
+	return true
+}
+
+// Collect performs a single garbage collection run.
+func (c *GarbageCollector) Collect(ctx context.Context) error {
+	log.V(1).Info("collecting orphaned monitors")
+
+	return c.monitorService.DeleteOrphanedMonitors(ctx, func(owner models.Owner) (bool, error) {
+		return c.isOrphaned(ctx, owner)
+	})
+}
+
+// isOrphaned returns true if the ingress, HTTPRoute or IngressMonitor of
+// owner does not exist, or if it is selected but does not have monitoring
+// enabled. Objects which are not selected are out of scope of this
+// controller instance and never considered orphaned. IngressMonitors do not
+// need to be enabled via annotation. Objects that are being deleted are
+// handled by the reconciler via the finalizer and are never considered
+// orphaned. Monitors of unknown kinds are never considered orphaned.
+func (c *GarbageCollector) isOrphaned(ctx context.Context, owner models.Owner) (bool, error) {
+	var obj client.Object
+
+	switch owner.Kind {
+	case "":
+		obj = &networkingv1.Ingress{}
+	case gateway.HTTPRouteGVK.Kind:
+		if !c.gatewayAPI {
+			return true, nil
+		}
+
+		obj = gateway.NewHTTPRoute()
+	case v1alpha1.IngressMonitorKind:
+		if !c.ingressMonitors {
+			return true, nil
+		}
+
+		obj = &v1alpha1.IngressMonitor{}
+	default:
+		return false, nil
+	}
+
+	err := c.client.Get(ctx, client.ObjectKey{Namespace: owner.Namespace, Name: owner.Name}, obj)
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if !obj.GetDeletionTimestamp().IsZero() || !c.selector.Matches(obj) {
+		return false, nil
+	}
+
+	if _, ok := obj.(*v1alpha1.IngressMonitor); ok {
+		return false, nil
+	}
+
+	return obj.GetAnnotations()[config.AnnotationEnabled] != "true", nil
+}
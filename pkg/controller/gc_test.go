@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/gateway"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGarbageCollector_Collect(t *testing.T) {
+	client := fakeclient.NewFakeClient(
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "enabled",
+				Namespace: "kube-system",
+				Annotations: map[string]string{
+					config.AnnotationEnabled: "true",
+				},
+			},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "disabled",
+				Namespace: "kube-system",
+			},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "unselected",
+				Namespace: "kube-system",
+				Labels: map[string]string{
+					"team": "bar",
+				},
+				Annotations: map[string]string{
+					config.AnnotationEnabled: "true",
+				},
+			},
+		},
+		&networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "deleting",
+				Namespace:         "kube-system",
+				Finalizers:        []string{Finalizer},
+				DeletionTimestamp: &metav1.Time{Time: time.Now()},
+			},
+		},
+	)
+
+	svc := &fake.Service{}
+
+	var orphaned map[string]bool
+
+	svc.On("DeleteOrphanedMonitors", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		isOrphaned := args.Get(1).(func(models.Owner) (bool, error))
+
+		orphaned = make(map[string]bool)
+
+		for _, name := range []string{"enabled", "disabled", "unselected", "deleting", "deleted"} {
+			result, err := isOrphaned(models.Owner{Namespace: "kube-system", Name: name})
+			require.NoError(t, err)
+
+			orphaned[name] = result
+		}
+	}).Return(nil)
+
+	options := &config.Options{GCInterval: time.Minute, IngressSelector: "team!=bar"}
+
+	selector, err := NewIngressSelector(options)
+	require.NoError(t, err)
+
+	gc := NewGarbageCollector(client, svc, selector, options)
+
+	require.NoError(t, gc.Collect(context.Background()))
+	assert.Equal(t, map[string]bool{
+		"enabled":    false,
+		"disabled":   true,
+		"unselected": false,
+		"deleting":   false,
+		"deleted":    true,
+	}, orphaned)
+}
+
+func TestGarbageCollector_Collect_HTTPRoutes(t *testing.T) {
+	route := gateway.NewHTTPRoute()
+	route.SetName("enabled")
+	route.SetNamespace("kube-system")
+	route.SetAnnotations(map[string]string{config.AnnotationEnabled: "true"})
+
+	tests := []struct {
+		name       string
+		gatewayAPI bool
+		owner      models.Owner
+		expected   bool
+	}{
+		{
+			name:       "enabled route",
+			gatewayAPI: true,
+			owner:      models.Owner{Kind: "HTTPRoute", Namespace: "kube-system", Name: "enabled"},
+			expected:   false,
+		},
+		{
+			name:       "deleted route",
+			gatewayAPI: true,
+			owner:      models.Owner{Kind: "HTTPRoute", Namespace: "kube-system", Name: "deleted"},
+			expected:   true,
+		},
+		{
+			name:     "gateway API support disabled",
+			owner:    models.Owner{Kind: "HTTPRoute", Namespace: "kube-system", Name: "enabled"},
+			expected: true,
+		},
+		{
+			name:       "unknown kind",
+			gatewayAPI: true,
+			owner:      models.Owner{Kind: "GRPCRoute", Namespace: "kube-system", Name: "deleted"},
+			expected:   false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := &config.Options{GCInterval: time.Minute, GatewayAPI: test.gatewayAPI}
+
+			gc := NewGarbageCollector(fakeclient.NewFakeClient(route.DeepCopy()), &fake.Service{}, &IngressSelector{}, options)
+
+			orphaned, err := gc.isOrphaned(context.Background(), test.owner)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, orphaned)
+		})
+	}
+}
+
+func TestGarbageCollector_Collect_IngressMonitors(t *testing.T) {
+	tests := []struct {
+		name            string
+		ingressMonitors bool
+		owner           models.Owner
+		expected        bool
+	}{
+		{
+			name:            "existing IngressMonitor",
+			ingressMonitors: true,
+			owner:           models.Owner{Kind: "IngressMonitor", Namespace: "kube-system", Name: "foo"},
+			expected:        false,
+		},
+		{
+			name:            "deleted IngressMonitor",
+			ingressMonitors: true,
+			owner:           models.Owner{Kind: "IngressMonitor", Namespace: "kube-system", Name: "deleted"},
+			expected:        true,
+		},
+		{
+			name:     "IngressMonitor support disabled",
+			owner:    models.Owner{Kind: "IngressMonitor", Namespace: "kube-system", Name: "foo"},
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			options := &config.Options{GCInterval: time.Minute, IngressMonitors: test.ingressMonitors}
+
+			client := fakeclient.NewClientBuilder().
+				WithScheme(newTestScheme(t)).
+				WithObjects(newTestIngressMonitor("foo", "bar")).
+				Build()
+
+			gc := NewGarbageCollector(client, &fake.Service{}, &IngressSelector{}, options)
+
+			orphaned, err := gc.isOrphaned(context.Background(), test.owner)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, orphaned)
+		})
+	}
+}
+
+func TestGarbageCollector_Start(t *testing.T) {
+	svc := &fake.Service{}
+
+	collected := make(chan struct{}, 1)
+
+	svc.On("DeleteOrphanedMonitors", mock.Anything, mock.Anything).Run(func(mock.Arguments) {
+		select {
+		case collected <- struct{}{}:
+		default:
+		}
+	}).Return(errors.New("whoops"))
+
+	gc := NewGarbageCollector(fakeclient.NewFakeClient(), svc, &IngressSelector{}, &config.Options{GCInterval: time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan error)
+
+	go func() {
+		done <- gc.Start(ctx)
+	}()
+
+	select {
+	case <-collected:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for garbage collection")
+	}
+
+	cancel()
+
+	require.NoError(t, <-done)
+}
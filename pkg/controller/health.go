@@ -0,0 +1,52 @@
+package controller
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor"
+)
+
+// ProviderCheck is a readiness check which verifies that all enabled
+// providers are usable, e.g. that their credentials are valid. The result is
+// cached, so that frequent readiness probes do not exhaust the API rate
+// limits of the providers.
+type ProviderCheck struct {
+	monitorService monitor.Service
+	cacheDuration  time.Duration
+
+	mu        sync.Mutex
+	lastCheck time.Time
+	lastErr   error
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+// NewProviderCheck creates a new *ProviderCheck which caches the check result
+// for cacheDuration.
+func NewProviderCheck(monitorService monitor.Service, cacheDuration time.Duration) *ProviderCheck {
+	return &ProviderCheck{
+		monitorService: monitorService,
+		cacheDuration:  cacheDuration,
+		now:            time.Now,
+	}
+}
+
+// Check implements healthz.Checker.
+func (c *ProviderCheck) Check(req *http.Request) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+
+	if !c.lastCheck.IsZero() && now.Sub(c.lastCheck) < c.cacheDuration {
+		return c.lastErr
+	}
+
+	c.lastErr = c.monitorService.CheckProviders(req.Context())
+	c.lastCheck = now
+
+	return c.lastErr
+}
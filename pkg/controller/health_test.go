@@ -0,0 +1,36 @@
+package controller
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProviderCheck_Check(t *testing.T) {
+	svc := &fake.Service{}
+	svc.On("CheckProviders", mock.Anything).Return(errors.New("whoops")).Once()
+	svc.On("CheckProviders", mock.Anything).Return(nil).Once()
+
+	now := time.Now()
+
+	check := NewProviderCheck(svc, time.Minute)
+	check.now = func() time.Time { return now }
+
+	req := httptest.NewRequest("GET", "/readyz", nil)
+
+	assert.EqualError(t, check.Check(req), "whoops")
+
+	// The result is cached.
+	now = now.Add(30 * time.Second)
+	assert.EqualError(t, check.Check(req), "whoops")
+	svc.AssertNumberOfCalls(t, "CheckProviders", 1)
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, check.Check(req))
+	svc.AssertNumberOfCalls(t, "CheckProviders", 2)
+}
@@ -0,0 +1,160 @@
+package controller
+
+import (
+	"context"
+	"maps"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var ingressMonitorLog = logf.Log.WithName("ingressmonitor-reconciler")
+
+// IngressMonitorReconciler reconciles IngressMonitors to their desired state.
+// Unlike ingresses and HTTPRoutes, IngressMonitors do not need to be enabled
+// via annotation. The result of every sync is reported in their status.
+type IngressMonitorReconciler struct {
+	client.Client
+
+	monitorService monitor.Service
+	selector       *IngressSelector
+	options        *config.Options
+}
+
+// NewIngressMonitorReconciler creates a new *IngressMonitorReconciler.
+// Monitors of IngressMonitors which are not matched by selector are deleted.
+func NewIngressMonitorReconciler(client client.Client, monitorService monitor.Service, selector *IngressSelector, options *config.Options) *IngressMonitorReconciler {
+	return &IngressMonitorReconciler{
+		Client:         client,
+		monitorService: monitorService,
+		selector:       selector,
+		options:        options,
+	}
+}
+
+// Reconcile creates, updates or deletes monitors whenever an IngressMonitor
+// or the ingress it references changes. It implements reconcile.Reconciler.
+func (r *IngressMonitorReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	im := &v1alpha1.IngressMonitor{}
+
+	err := r.Get(ctx, req.NamespacedName, im)
+	if apierrors.IsNotFound(err) {
+		// The IngressMonitor was deleted without our finalizer. Construct a
+		// metadata-only object just for monitor deletion.
+		im = &v1alpha1.IngressMonitor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      req.NamespacedName.Name,
+				Namespace: req.NamespacedName.Namespace,
+			},
+		}
+
+		err = r.monitorService.DeleteMonitor(ctx, im)
+	} else if err == nil {
+		if !im.DeletionTimestamp.IsZero() || !r.selector.Matches(im) {
+			err = deleteMonitor(ctx, r.Client, r.monitorService, im)
+		} else {
+			err = r.handleCreateOrUpdate(ctx, im)
+		}
+	}
+
+	return reconcile.Result{}, err
+}
+
+func (r *IngressMonitorReconciler) handleCreateOrUpdate(ctx context.Context, im *v1alpha1.IngressMonitor) error {
+	if controllerutil.AddFinalizer(im, Finalizer) {
+		// The update will cause the creation of a new update event, so we
+		// can return here.
+		return r.Update(ctx, im)
+	}
+
+	imCopy := im.DeepCopy()
+
+	updated, err := r.monitorService.EnsureMonitor(ctx, imCopy)
+	if updated && !maps.Equal(im.Annotations, imCopy.Annotations) {
+		// Persist the recorded monitor name first, it is required to rename
+		// or clean up the monitor later on.
+		updateErr := r.Update(ctx, imCopy)
+		if updateErr != nil {
+			if err != nil {
+				return err
+			}
+
+			return updateErr
+		}
+	}
+
+	imCopy.Status = statusFor(imCopy)
+	if equality.Semantic.DeepEqual(im.Status, imCopy.Status) {
+		return err
+	}
+
+	statusErr := r.Status().Update(ctx, imCopy)
+	if err != nil {
+		return err
+	}
+
+	return statusErr
+}
+
+// statusFor returns the status of im based on the monitor name and sync
+// status that the monitor service recorded in its annotations.
+func statusFor(im *v1alpha1.IngressMonitor) v1alpha1.IngressMonitorStatus {
+	status := v1alpha1.IngressMonitorStatus{
+		ObservedGeneration: im.Generation,
+		MonitorName:        im.Annotations[config.AnnotationMonitorName],
+		State:              v1alpha1.MonitorStateSynced,
+	}
+
+	syncStatus := monitor.ParseStatus(im)
+	if syncStatus == nil {
+		return status
+	}
+
+	status.MonitorID = syncStatus.MonitorID
+	status.Providers = syncStatus.Providers
+	status.LastError = syncStatus.LastError
+	status.LastSyncTime = syncStatus.LastSyncTime.DeepCopy()
+
+	if syncStatus.LastError != "" {
+		status.State = v1alpha1.MonitorStateFailed
+	}
+
+	return status
+}
+
+// MonitorsForIngress maps an ingress to reconcile requests for all
+// IngressMonitors that reference it, so that their monitors are updated if
+// the ingress changes. It is meant to be used with
+// handler.EnqueueRequestsFromMapFunc.
+func (r *IngressMonitorReconciler) MonitorsForIngress(ctx context.Context, obj client.Object) []reconcile.Request {
+	list := &v1alpha1.IngressMonitorList{}
+
+	err := r.List(ctx, list, client.InNamespace(obj.GetNamespace()))
+	if err != nil {
+		ingressMonitorLog.Error(err, "failed to list IngressMonitors for ingress", "namespace", obj.GetNamespace(), "name", obj.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+
+	for _, im := range list.Items {
+		if im.Spec.IngressRef == nil || im.Spec.IngressRef.Name != obj.GetName() {
+			continue
+		}
+
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: im.Namespace, Name: im.Name},
+		})
+	}
+
+	return requests
+}
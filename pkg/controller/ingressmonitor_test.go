@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newTestScheme(t *testing.T) *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+	return scheme
+}
+
+func newTestIngressMonitor(name, ingressName string, finalizers ...string) *v1alpha1.IngressMonitor {
+	return &v1alpha1.IngressMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:  "kube-system",
+			Name:       name,
+			Generation: 2,
+			Finalizers: finalizers,
+		},
+		Spec: v1alpha1.IngressMonitorSpec{
+			IngressRef: &v1alpha1.IngressReference{Name: ingressName},
+		},
+	}
+}
+
+func getIngressMonitor(t *testing.T, c client.Client, name string) *v1alpha1.IngressMonitor {
+	im := &v1alpha1.IngressMonitor{}
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "kube-system", Name: name}, im))
+	return im
+}
+
+// recordSync simulates the annotations recorded by the monitor service.
+func recordSync(lastError string) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		im := args.Get(1).(*v1alpha1.IngressMonitor)
+		im.Annotations = map[string]string{
+			config.AnnotationMonitorName: "kube-system-foo",
+			config.AnnotationStatus:      `{"monitorID":"42","providers":["fake"],"lastSyncTime":"2026-01-02T15:04:05Z","lastError":"` + lastError + `"}`,
+		}
+	}
+}
+
+func TestIngressMonitorReconciler_Reconcile(t *testing.T) {
+	tests := []struct {
+		name          string
+		objects       []client.Object
+		setup         func(*fake.Service)
+		options       config.Options
+		expectedError string
+		validate      func(*testing.T, client.Client, *fake.Service)
+	}{
+		{
+			name: "it deletes monitors if IngressMonitor was deleted",
+			setup: func(s *fake.Service) {
+				s.On("DeleteMonitor", mock.Anything, mock.MatchedBy(func(im *v1alpha1.IngressMonitor) bool {
+					return im.Namespace == "kube-system" && im.Name == "foo"
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "it adds the finalizer before ensuring the monitor",
+			objects: []client.Object{newTestIngressMonitor("foo", "bar")},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				s.AssertNotCalled(t, "EnsureMonitor", mock.Anything, mock.Anything)
+				assert.True(t, controllerutil.ContainsFinalizer(getIngressMonitor(t, c, "foo"), Finalizer))
+			},
+		},
+		{
+			name:    "it ensures the monitor and updates the status",
+			objects: []client.Object{newTestIngressMonitor("foo", "bar", Finalizer)},
+			setup: func(s *fake.Service) {
+				s.On("EnsureMonitor", mock.Anything, mock.Anything).Run(recordSync("")).Return(true, nil)
+			},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				im := getIngressMonitor(t, c, "foo")
+				assert.Equal(t, "kube-system-foo", im.Annotations[config.AnnotationMonitorName])
+				assert.Equal(t, int64(2), im.Status.ObservedGeneration)
+				assert.Equal(t, v1alpha1.MonitorStateSynced, im.Status.State)
+				assert.Equal(t, "kube-system-foo", im.Status.MonitorName)
+				assert.Equal(t, "42", im.Status.MonitorID)
+				assert.Equal(t, []string{"fake"}, im.Status.Providers)
+				assert.Empty(t, im.Status.LastError)
+				assert.NotNil(t, im.Status.LastSyncTime)
+			},
+		},
+		{
+			name:    "it reports sync errors in the status",
+			objects: []client.Object{newTestIngressMonitor("foo", "bar", Finalizer)},
+			setup: func(s *fake.Service) {
+				s.On("EnsureMonitor", mock.Anything, mock.Anything).Run(recordSync("whoops")).Return(true, errors.New("whoops"))
+			},
+			expectedError: "whoops",
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				im := getIngressMonitor(t, c, "foo")
+				assert.Equal(t, v1alpha1.MonitorStateFailed, im.Status.State)
+				assert.Equal(t, "whoops", im.Status.LastError)
+			},
+		},
+		{
+			name:    "it deletes the monitor if IngressMonitor is not selected",
+			objects: []client.Object{newTestIngressMonitor("foo", "bar", Finalizer)},
+			options: config.Options{IngressSelector: "team=foo"},
+			setup: func(s *fake.Service) {
+				s.On("DeleteMonitor", mock.Anything, mock.Anything).Return(nil)
+			},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				s.AssertNotCalled(t, "EnsureMonitor", mock.Anything, mock.Anything)
+				assert.False(t, controllerutil.ContainsFinalizer(getIngressMonitor(t, c, "foo"), Finalizer))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := fakeclient.NewClientBuilder().
+				WithScheme(newTestScheme(t)).
+				WithStatusSubresource(&v1alpha1.IngressMonitor{}).
+				WithObjects(test.objects...).
+				Build()
+
+			svc := &fake.Service{}
+
+			if test.setup != nil {
+				test.setup(svc)
+			}
+
+			selector, err := NewIngressSelector(&test.options)
+			require.NoError(t, err)
+
+			r := NewIngressMonitorReconciler(c, svc, selector, &test.options)
+
+			_, err = r.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "kube-system", Name: "foo"},
+			})
+			if test.expectedError != "" {
+				require.EqualError(t, err, test.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			svc.AssertExpectations(t)
+
+			if test.validate != nil {
+				test.validate(t, c, svc)
+			}
+		})
+	}
+}
+
+func TestIngressMonitorReconciler_MonitorsForIngress(t *testing.T) {
+	urlMonitor := newTestIngressMonitor("url", "")
+	urlMonitor.Spec = v1alpha1.IngressMonitorSpec{URL: "https://example.com"}
+
+	other := newTestIngressMonitor("other", "bar")
+	other.Namespace = "default"
+
+	c := fakeclient.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(
+			newTestIngressMonitor("foo", "bar"),
+			newTestIngressMonitor("baz", "qux"),
+			urlMonitor,
+			other,
+		).
+		Build()
+
+	r := NewIngressMonitorReconciler(c, &fake.Service{}, &IngressSelector{}, &config.Options{})
+
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "bar"},
+	}
+
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "kube-system", Name: "foo"}},
+	}, r.MonitorsForIngress(context.Background(), ing))
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// IgnoreStatusUpdates returns a predicate that filters out ingress and
+// HTTPRoute update events which only changed the
+// ingress-monitor.bonial.com/status annotation. The status is updated on
+// every reconciliation, so without this predicate each reconciliation would
+// trigger the next one. Changes to the status subresource of IngressMonitors
+// are filtered out as well.
+func IgnoreStatusUpdates() predicate.Predicate {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil {
+				return true
+			}
+
+			return !equality.Semantic.DeepEqual(withoutStatus(e.ObjectOld), withoutStatus(e.ObjectNew))
+		},
+	}
+}
+
+// withoutStatus returns a copy of obj without the status annotation and the
+// metadata fields which change on every update.
+func withoutStatus(obj client.Object) client.Object {
+	obj = obj.DeepCopyObject().(client.Object)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+
+	if im, ok := obj.(*v1alpha1.IngressMonitor); ok {
+		im.Status = v1alpha1.IngressMonitorStatus{}
+	}
+
+	annotations := obj.GetAnnotations()
+	delete(annotations, config.AnnotationStatus)
+
+	if len(annotations) == 0 {
+		annotations = nil
+	}
+
+	obj.SetAnnotations(annotations)
+
+	return obj
+}
@@ -0,0 +1,116 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/gateway"
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestIgnoreStatusUpdates(t *testing.T) {
+	newIngress := func(resourceVersion string, annotations map[string]string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "foo",
+				Namespace:       "kube-system",
+				ResourceVersion: resourceVersion,
+				Annotations:     annotations,
+			},
+		}
+	}
+
+	newRoute := func(resourceVersion string, annotations map[string]string, hostname string) *unstructured.Unstructured {
+		route := gateway.NewHTTPRoute()
+		route.SetName("foo")
+		route.SetNamespace("kube-system")
+		route.SetResourceVersion(resourceVersion)
+		route.SetAnnotations(annotations)
+		route.Object["spec"] = map[string]interface{}{
+			"hostnames": []interface{}{hostname},
+		}
+		return route
+	}
+
+	newIngressMonitor := func(resourceVersion string, url string, state v1alpha1.MonitorState) *v1alpha1.IngressMonitor {
+		return &v1alpha1.IngressMonitor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "foo",
+				Namespace:       "kube-system",
+				ResourceVersion: resourceVersion,
+			},
+			Spec:   v1alpha1.IngressMonitorSpec{URL: url},
+			Status: v1alpha1.IngressMonitorStatus{State: state},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		old      client.Object
+		new      client.Object
+		expected bool
+	}{
+		{
+			name:     "status annotation added",
+			old:      newIngress("1", nil),
+			new:      newIngress("2", map[string]string{config.AnnotationStatus: "{}"}),
+			expected: false,
+		},
+		{
+			name:     "status annotation changed",
+			old:      newIngress("1", map[string]string{config.AnnotationEnabled: "true", config.AnnotationStatus: "{}"}),
+			new:      newIngress("2", map[string]string{config.AnnotationEnabled: "true", config.AnnotationStatus: `{"lastError":"whoops"}`}),
+			expected: false,
+		},
+		{
+			name:     "other annotation changed",
+			old:      newIngress("1", map[string]string{config.AnnotationEnabled: "true", config.AnnotationStatus: "{}"}),
+			new:      newIngress("2", map[string]string{config.AnnotationEnabled: "false", config.AnnotationStatus: "{}"}),
+			expected: true,
+		},
+		{
+			name:     "monitor ID recorded",
+			old:      newIngress("1", map[string]string{config.AnnotationStatus: "{}"}),
+			new:      newIngress("2", map[string]string{config.AnnotationMonitorID: "123", config.AnnotationStatus: `{"monitorID":"123"}`}),
+			expected: true,
+		},
+		{
+			name:     "HTTPRoute status annotation added",
+			old:      newRoute("1", nil, "foo.bar.baz"),
+			new:      newRoute("2", map[string]string{config.AnnotationStatus: "{}"}, "foo.bar.baz"),
+			expected: false,
+		},
+		{
+			name:     "HTTPRoute spec changed",
+			old:      newRoute("1", map[string]string{config.AnnotationStatus: "{}"}, "foo.bar.baz"),
+			new:      newRoute("2", map[string]string{config.AnnotationStatus: "{}"}, "bar.bar.baz"),
+			expected: true,
+		},
+		{
+			name:     "IngressMonitor status changed",
+			old:      newIngressMonitor("1", "https://foo.bar.baz", ""),
+			new:      newIngressMonitor("2", "https://foo.bar.baz", v1alpha1.MonitorStateSynced),
+			expected: false,
+		},
+		{
+			name:     "IngressMonitor spec changed",
+			old:      newIngressMonitor("1", "https://foo.bar.baz", v1alpha1.MonitorStateSynced),
+			new:      newIngressMonitor("2", "https://bar.bar.baz", v1alpha1.MonitorStateSynced),
+			expected: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := IgnoreStatusUpdates()
+
+			assert.Equal(t, test.expected, p.Update(event.UpdateEvent{ObjectOld: test.old, ObjectNew: test.new}))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/gateway"
+	"github.com/go-logr/logr"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// IngressesForProfile maps a MonitorProfile to reconcile requests for all
+// ingresses referencing it, so that monitors are updated if the profile
+// changes. It is meant to be used with handler.EnqueueRequestsFromMapFunc.
+func (r *IngressReconciler) IngressesForProfile(ctx context.Context, obj client.Object) []reconcile.Request {
+	return requestsForProfile(ctx, r.Client, r.options, ingressLog, obj, func() client.ObjectList {
+		return &networkingv1.IngressList{}
+	})
+}
+
+// RoutesForProfile maps a MonitorProfile to reconcile requests for all
+// HTTPRoutes referencing it. See IngressesForProfile.
+func (r *RouteReconciler) RoutesForProfile(ctx context.Context, obj client.Object) []reconcile.Request {
+	return requestsForProfile(ctx, r.Client, r.options, routeLog, obj, func() client.ObjectList {
+		return gateway.NewHTTPRouteList()
+	})
+}
+
+// MonitorsForProfile maps a MonitorProfile to reconcile requests for all
+// IngressMonitors referencing it. See IngressesForProfile.
+func (r *IngressMonitorReconciler) MonitorsForProfile(ctx context.Context, obj client.Object) []reconcile.Request {
+	return requestsForProfile(ctx, r.Client, r.options, ingressMonitorLog, obj, func() client.ObjectList {
+		return &v1alpha1.IngressMonitorList{}
+	})
+}
+
+// requestsForProfile lists the objects in all watched namespaces using lists
+// created by newList and returns reconcile requests for those which reference
+// profile via the profile annotation.
+func requestsForProfile(ctx context.Context, c client.Client, options *config.Options, log logr.Logger, profile client.Object, newList func() client.ObjectList) []reconcile.Request {
+	namespaces := options.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var requests []reconcile.Request
+
+	for _, namespace := range namespaces {
+		list := newList()
+
+		err := c.List(ctx, list, client.InNamespace(namespace))
+		if err != nil {
+			log.Error(err, "failed to list resources for monitor profile", "profile", profile.GetName())
+			continue
+		}
+
+		_ = meta.EachListItem(list, func(item runtime.Object) error {
+			obj, ok := item.(client.Object)
+			if !ok || !options.WatchesNamespace(obj.GetNamespace()) {
+				return nil
+			}
+
+			if strings.TrimSpace(obj.GetAnnotations()[config.AnnotationProfile]) != profile.GetName() {
+				return nil
+			}
+
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()},
+			})
+
+			return nil
+		})
+	}
+
+	return requests
+}
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/fake"
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestIngressReconciler_IngressesForProfile(t *testing.T) {
+	newIngress := func(namespace, name, profile string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:   namespace,
+				Name:        name,
+				Annotations: map[string]string{config.AnnotationProfile: profile},
+			},
+		}
+	}
+
+	c := fakeclient.NewClientBuilder().WithObjects(
+		newIngress("kube-system", "foo", "critical-api"),
+		newIngress("default", "bar", " critical-api "),
+		newIngress("default", "baz", "other"),
+		newIngress("excluded", "qux", "critical-api"),
+	).Build()
+
+	r := NewIngressReconciler(c, &fake.Service{}, &IngressSelector{}, &config.Options{ExcludedNamespaces: []string{"excluded"}})
+
+	profile := &v1alpha1.MonitorProfile{ObjectMeta: metav1.ObjectMeta{Name: "critical-api"}}
+
+	requests := r.IngressesForProfile(context.Background(), profile)
+
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "kube-system", Name: "foo"}},
+		{NamespacedName: types.NamespacedName{Namespace: "default", Name: "bar"}},
+	}, requests)
+}
+
+func TestIngressMonitorReconciler_MonitorsForProfile(t *testing.T) {
+	foo := newTestIngressMonitor("foo", "foo")
+	foo.Annotations = map[string]string{config.AnnotationProfile: "critical-api"}
+
+	c := fakeclient.NewClientBuilder().
+		WithScheme(newTestScheme(t)).
+		WithObjects(foo, newTestIngressMonitor("bar", "bar")).
+		Build()
+
+	r := NewIngressMonitorReconciler(c, &fake.Service{}, &IngressSelector{}, &config.Options{Namespaces: []string{"kube-system"}})
+
+	profile := &v1alpha1.MonitorProfile{ObjectMeta: metav1.ObjectMeta{Name: "critical-api"}}
+
+	requests := r.MonitorsForProfile(context.Background(), profile)
+
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "kube-system", Name: "foo"}},
+	}, requests)
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"maps"
 	"time"
 
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
@@ -10,23 +11,35 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+var ingressLog = logf.Log.WithName("ingress-reconciler")
+
+// Finalizer is added to ingresses and HTTPRoutes with enabled monitors. It
+// ensures that the monitor deletion is processed with the full object at hand
+// and that the object is only removed after the monitor was deleted.
+const Finalizer = "ingress-monitor.bonial.com/monitor-cleanup"
+
 // IngressReconciler reconciles ingresses to their desired state.
 type IngressReconciler struct {
 	client.Client
 
 	monitorService monitor.Service
-	creationDelay  time.Duration
+	selector       *IngressSelector
+	options        *config.Options
 }
 
-// NewIngressReconciler creates a new *IngressReconciler.
-func NewIngressReconciler(client client.Client, monitorService monitor.Service, options *config.Options) *IngressReconciler {
+// NewIngressReconciler creates a new *IngressReconciler. Monitors of
+// ingresses which are not matched by selector are deleted.
+func NewIngressReconciler(client client.Client, monitorService monitor.Service, selector *IngressSelector, options *config.Options) *IngressReconciler {
 	return &IngressReconciler{
 		Client:         client,
 		monitorService: monitorService,
-		creationDelay:  options.CreationDelay,
+		selector:       selector,
+		options:        options,
 	}
 }
 
@@ -37,8 +50,9 @@ func (r *IngressReconciler) Reconcile(ctx context.Context, req reconcile.Request
 
 	err := r.Get(ctx, req.NamespacedName, ingress)
 	if apierrors.IsNotFound(err) {
-		// The ingress was deleted. Construct a metadata-only ingress object
-		// just for monitor deletion.
+		// The ingress was deleted without our finalizer, e.g. because it
+		// was created before the finalizer was introduced. Construct a
+		// metadata-only ingress object just for monitor deletion.
 		ingress = &networkingv1.Ingress{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      req.NamespacedName.Name,
@@ -46,10 +60,12 @@ func (r *IngressReconciler) Reconcile(ctx context.Context, req reconcile.Request
 			},
 		}
 
-		err = r.monitorService.DeleteMonitor(ingress)
+		err = r.monitorService.DeleteMonitor(ctx, ingress)
 	} else if err == nil {
-		if ingress.Annotations[config.AnnotationEnabled] == "true" {
-			createAfter := time.Until(ingress.CreationTimestamp.Add(r.creationDelay))
+		if !ingress.DeletionTimestamp.IsZero() {
+			err = deleteMonitor(ctx, r.Client, r.monitorService, ingress)
+		} else if ingress.Annotations[config.AnnotationEnabled] == "true" && r.selector.Matches(ingress) {
+			createAfter := time.Until(ingress.CreationTimestamp.Add(r.options.CreationDelay))
 
 			// If a creation delay was configured, we will requeue the
 			// reconciliation until after the creation delay passed.
@@ -59,27 +75,109 @@ func (r *IngressReconciler) Reconcile(ctx context.Context, req reconcile.Request
 
 			err = r.handleCreateOrUpdate(ctx, ingress)
 		} else {
-			err = r.monitorService.DeleteMonitor(ingress)
+			err = deleteMonitor(ctx, r.Client, r.monitorService, ingress)
 		}
 	}
 
 	return reconcile.Result{}, err
 }
 
+// deleteMonitor deletes the monitor of an ingress or HTTPRoute that is being
+// deleted, has monitoring disabled or is not selected anymore. The finalizer
+// is only removed after the monitor was deleted successfully.
+func deleteMonitor(ctx context.Context, c client.Client, monitorService monitor.Service, obj client.Object) error {
+	if !obj.GetDeletionTimestamp().IsZero() && !controllerutil.ContainsFinalizer(obj, Finalizer) {
+		// Nothing to do for us, the monitor was already deleted.
+		return nil
+	}
+
+	err := monitorService.DeleteMonitor(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	removedFinalizer := controllerutil.RemoveFinalizer(obj, Finalizer)
+	removedAnnotations := removeMonitorAnnotations(obj)
+
+	if !removedFinalizer && !removedAnnotations {
+		return nil
+	}
+
+	return c.Update(ctx, obj)
+}
+
+// removeMonitorAnnotations removes the annotations maintained by the monitor
+// service from obj, so that no stale monitor status is left behind once
+// monitoring is disabled. Returns true if any annotation was removed.
+func removeMonitorAnnotations(obj client.Object) bool {
+	annotations := obj.GetAnnotations()
+	removed := false
+
+	for _, name := range []string{config.AnnotationMonitorName, config.AnnotationMonitorID, config.AnnotationStatus} {
+		if _, found := annotations[name]; found {
+			delete(annotations, name)
+			removed = true
+		}
+	}
+
+	if removed {
+		obj.SetAnnotations(annotations)
+	}
+
+	return removed
+}
+
+// ensureMonitor ensures the monitor of an ingress or HTTPRoute and persists
+// the monitor name, ID and sync status that were recorded in its annotations.
+// The object is only updated if the annotations actually changed.
+func ensureMonitor(ctx context.Context, c client.Client, monitorService monitor.Service, obj client.Object) error {
+	objCopy := obj.DeepCopyObject().(client.Object)
+
+	updated, err := monitorService.EnsureMonitor(ctx, objCopy)
+	if !updated || maps.Equal(obj.GetAnnotations(), objCopy.GetAnnotations()) {
+		return err
+	}
+
+	// Persist the recorded monitor name and status even if ensuring the
+	// monitor failed, so that the user can see the error on the object.
+	updateErr := c.Update(ctx, objCopy)
+	if err != nil {
+		return err
+	}
+
+	return updateErr
+}
+
 func (r *IngressReconciler) handleCreateOrUpdate(ctx context.Context, ingress *networkingv1.Ingress) error {
-	updated, err := r.reconcileAnnotations(ctx, ingress)
-	if err != nil || updated {
-		// In case of an error we return it here to force requeuing of the
-		// reconciliation request. If the ingress was updated, we return
-		// here as well because the update will cause the creation of a new
-		// ingress update event which will be consumed by Reconcile and we
-		// want to avoid duplicate execution of the EnsureMonitor logic. This
-		// is an optimization to avoid unnecessary API calls to the monitor
-		// provider.
+	if controllerutil.AddFinalizer(ingress, Finalizer) {
+		// Like for annotation updates below, the update will cause the
+		// creation of a new ingress update event, so we can return here.
+		return r.Update(ctx, ingress)
+	}
+
+	updated, annotateErr := r.reconcileAnnotations(ctx, ingress)
+	if updated {
+		// If the ingress was updated, we return here because the update
+		// will cause the creation of a new ingress update event which will
+		// be consumed by Reconcile and we want to avoid duplicate execution
+		// of the EnsureMonitor logic. This is an optimization to avoid
+		// unnecessary API calls to the monitor provider.
+		return annotateErr
+	}
+
+	if annotateErr != nil {
+		// A provider failing to return its source ranges must not keep the
+		// monitors from being ensured in the others. The error is returned
+		// afterwards to force requeuing of the reconciliation request.
+		ingressLog.Error(annotateErr, "failed to reconcile annotations, ensuring monitors anyway", "namespace", ingress.Namespace, "name", ingress.Name)
+	}
+
+	err := ensureMonitor(ctx, r.Client, r.monitorService, ingress)
+	if err != nil {
 		return err
 	}
 
-	return r.monitorService.EnsureMonitor(ingress)
+	return annotateErr
 }
 
 // reconcileAnnotations reconciles the ingress annotations, that is, it may
@@ -87,19 +185,21 @@ func (r *IngressReconciler) handleCreateOrUpdate(ctx context.Context, ingress *n
 // with ip source ranges of the monitor provider. If annotations were updated,
 // it will update the ingress object on the cluster and return true and the
 // first return value. The will effectively cause the creation of a new ingress
-// update event which is then picked up by the reconciler.
+// update event which is then picked up by the reconciler. The source ranges of
+// healthy providers are persisted even if other providers failed, in which
+// case the error is returned as well.
 func (r *IngressReconciler) reconcileAnnotations(ctx context.Context, ingress *networkingv1.Ingress) (updated bool, err error) {
 	ingressCopy := ingress.DeepCopy()
 
-	updated, err = r.monitorService.AnnotateIngress(ingressCopy)
-	if err != nil || !updated {
+	updated, err = r.monitorService.AnnotateIngress(ctx, ingressCopy)
+	if !updated {
 		return false, err
 	}
 
-	err = r.Update(ctx, ingressCopy)
-	if err != nil {
-		return false, err
+	updateErr := r.Update(ctx, ingressCopy)
+	if updateErr != nil {
+		return false, updateErr
 	}
 
-	return true, nil
+	return true, err
 }
@@ -2,14 +2,17 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/fake"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -37,7 +40,7 @@ func TestIngressReconciler_Reconcile(t *testing.T) {
 				},
 			},
 			setup: func(s *fake.Service) {
-				s.On("DeleteMonitor", &networkingv1.Ingress{
+				s.On("DeleteMonitor", mock.Anything, &networkingv1.Ingress{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "foo",
 						Namespace: "kube-system",
@@ -65,6 +68,7 @@ func TestIngressReconciler_Reconcile(t *testing.T) {
 						Annotations: map[string]string{
 							config.AnnotationEnabled: "true",
 						},
+						Finalizers: []string{Finalizer},
 					},
 				})
 			},
@@ -80,12 +84,79 @@ func TestIngressReconciler_Reconcile(t *testing.T) {
 						Annotations: map[string]string{
 							config.AnnotationEnabled: "true",
 						},
+						Finalizers:      []string{Finalizer},
 						ResourceVersion: "999",
 					},
 				}
 
-				s.On("AnnotateIngress", ing).Return(false, nil)
-				s.On("EnsureMonitor", ing).Return(nil)
+				s.On("AnnotateIngress", mock.Anything, ing).Return(false, nil)
+				s.On("EnsureMonitor", mock.Anything, ing).Return(false, nil)
+			},
+		},
+		{
+			name: "it persists the monitor name recorded by the monitor service",
+			req: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "bar",
+					Namespace: "kube-system",
+				},
+			},
+			clientFn: func() client.Client {
+				return fakeclient.NewFakeClient(&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "bar",
+						Namespace: "kube-system",
+						Annotations: map[string]string{
+							config.AnnotationEnabled: "true",
+						},
+						Finalizers: []string{Finalizer},
+					},
+				})
+			},
+			setup: func(s *fake.Service) {
+				s.On("AnnotateIngress", mock.Anything, mock.Anything).Return(false, nil)
+				s.On("EnsureMonitor", mock.Anything, mock.Anything).
+					Run(func(args mock.Arguments) {
+						ingress := args.Get(1).(*networkingv1.Ingress)
+						ingress.Annotations[config.AnnotationMonitorName] = "kube-system-bar"
+					}).
+					Return(true, nil)
+			},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				ingress := &networkingv1.Ingress{}
+				require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "bar", Namespace: "kube-system"}, ingress))
+				assert.Equal(t, "kube-system-bar", ingress.Annotations[config.AnnotationMonitorName])
+			},
+		},
+		{
+			name: "it does not update the ingress if the annotations did not change",
+			req: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "bar",
+					Namespace: "kube-system",
+				},
+			},
+			clientFn: func() client.Client {
+				return fakeclient.NewFakeClient(&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "bar",
+						Namespace: "kube-system",
+						Annotations: map[string]string{
+							config.AnnotationEnabled:     "true",
+							config.AnnotationMonitorName: "kube-system-bar",
+						},
+						Finalizers: []string{Finalizer},
+					},
+				})
+			},
+			setup: func(s *fake.Service) {
+				s.On("AnnotateIngress", mock.Anything, mock.Anything).Return(false, nil)
+				s.On("EnsureMonitor", mock.Anything, mock.Anything).Return(true, nil)
+			},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				ingress := &networkingv1.Ingress{}
+				require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "bar", Namespace: "kube-system"}, ingress))
+				assert.Equal(t, "999", ingress.ResourceVersion)
 			},
 		},
 		{
@@ -108,6 +179,7 @@ func TestIngressReconciler_Reconcile(t *testing.T) {
 						Annotations: map[string]string{
 							config.AnnotationEnabled: "true",
 						},
+						Finalizers: []string{Finalizer},
 					},
 				})
 			},
@@ -123,11 +195,12 @@ func TestIngressReconciler_Reconcile(t *testing.T) {
 						Annotations: map[string]string{
 							config.AnnotationEnabled: "true",
 						},
+						Finalizers:      []string{Finalizer},
 						ResourceVersion: "999",
 					},
 				}
 
-				s.On("AnnotateIngress", ing).Return(true, nil)
+				s.On("AnnotateIngress", mock.Anything, ing).Return(true, nil)
 			},
 		},
 		{
@@ -151,7 +224,7 @@ func TestIngressReconciler_Reconcile(t *testing.T) {
 				})
 			},
 			setup: func(s *fake.Service) {
-				s.On("DeleteMonitor", &networkingv1.Ingress{
+				s.On("DeleteMonitor", mock.Anything, &networkingv1.Ingress{
 					TypeMeta: metav1.TypeMeta{
 						Kind:       "Ingress",
 						APIVersion: "networking.k8s.io/v1",
@@ -164,6 +237,254 @@ func TestIngressReconciler_Reconcile(t *testing.T) {
 				}).Return(nil)
 			},
 		},
+		{
+			name: "it adds the finalizer before ensuring the monitor",
+			req: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "bar",
+					Namespace: "kube-system",
+				},
+			},
+			clientFn: func() client.Client {
+				return fakeclient.NewFakeClient(&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "bar",
+						Namespace: "kube-system",
+						Annotations: map[string]string{
+							config.AnnotationEnabled: "true",
+						},
+					},
+				})
+			},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				ingress := &networkingv1.Ingress{}
+				require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "bar", Namespace: "kube-system"}, ingress))
+				assert.Equal(t, []string{Finalizer}, ingress.Finalizers)
+				assert.Len(t, s.Calls, 0)
+			},
+		},
+		{
+			name: "it deletes the monitor with the full ingress object and removes the finalizer if ingress is being deleted",
+			req: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "bar",
+					Namespace: "kube-system",
+				},
+			},
+			clientFn: func() client.Client {
+				return fakeclient.NewFakeClient(&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "bar",
+						Namespace: "kube-system",
+						Annotations: map[string]string{
+							config.AnnotationEnabled:  "true",
+							config.AnnotationProvider: "null",
+						},
+						Finalizers:        []string{Finalizer},
+						DeletionTimestamp: &metav1.Time{Time: time.Now()},
+					},
+				})
+			},
+			setup: func(s *fake.Service) {
+				s.On("DeleteMonitor", mock.Anything, mock.MatchedBy(func(ingress *networkingv1.Ingress) bool {
+					return ingress.Annotations[config.AnnotationProvider] == "null"
+				})).Return(nil)
+			},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				s.AssertExpectations(t)
+
+				err := c.Get(context.Background(), types.NamespacedName{Name: "bar", Namespace: "kube-system"}, &networkingv1.Ingress{})
+				assert.True(t, apierrors.IsNotFound(err))
+			},
+		},
+		{
+			name: "it keeps the finalizer if monitor deletion fails",
+			req: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "bar",
+					Namespace: "kube-system",
+				},
+			},
+			clientFn: func() client.Client {
+				return fakeclient.NewFakeClient(&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "bar",
+						Namespace: "kube-system",
+						Annotations: map[string]string{
+							config.AnnotationEnabled: "true",
+						},
+						Finalizers:        []string{Finalizer},
+						DeletionTimestamp: &metav1.Time{Time: time.Now()},
+					},
+				})
+			},
+			setup: func(s *fake.Service) {
+				s.On("DeleteMonitor", mock.Anything, mock.Anything).Return(errors.New("whoops"))
+			},
+			expectError: true,
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				ingress := &networkingv1.Ingress{}
+				require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "bar", Namespace: "kube-system"}, ingress))
+				assert.Equal(t, []string{Finalizer}, ingress.Finalizers)
+			},
+		},
+		{
+			name: "it removes the finalizer if monitoring was disabled",
+			req: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "bar",
+					Namespace: "kube-system",
+				},
+			},
+			clientFn: func() client.Client {
+				return fakeclient.NewFakeClient(&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:       "bar",
+						Namespace:  "kube-system",
+						Finalizers: []string{Finalizer},
+					},
+				})
+			},
+			setup: func(s *fake.Service) {
+				s.On("DeleteMonitor", mock.Anything, mock.Anything).Return(nil)
+			},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				ingress := &networkingv1.Ingress{}
+				require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "bar", Namespace: "kube-system"}, ingress))
+				assert.Empty(t, ingress.Finalizers)
+				s.AssertExpectations(t)
+			},
+		},
+		{
+			name:    "it deletes the monitor and removes the finalizer if ingress is not selected anymore",
+			options: config.Options{IngressSelector: "team=foo"},
+			req: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "bar",
+					Namespace: "kube-system",
+				},
+			},
+			clientFn: func() client.Client {
+				return fakeclient.NewFakeClient(&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "bar",
+						Namespace: "kube-system",
+						Labels: map[string]string{
+							"team": "bar",
+						},
+						Annotations: map[string]string{
+							config.AnnotationEnabled: "true",
+						},
+						Finalizers: []string{Finalizer},
+					},
+				})
+			},
+			setup: func(s *fake.Service) {
+				s.On("DeleteMonitor", mock.Anything, mock.Anything).Return(nil)
+			},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				ingress := &networkingv1.Ingress{}
+				require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "bar", Namespace: "kube-system"}, ingress))
+				assert.Empty(t, ingress.Finalizers)
+				s.AssertExpectations(t)
+				s.AssertNotCalled(t, "EnsureMonitor", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "it persists the monitor status even if ensuring the monitor fails",
+			req: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "bar",
+					Namespace: "kube-system",
+				},
+			},
+			clientFn: func() client.Client {
+				return fakeclient.NewFakeClient(&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "bar",
+						Namespace: "kube-system",
+						Annotations: map[string]string{
+							config.AnnotationEnabled: "true",
+						},
+						Finalizers: []string{Finalizer},
+					},
+				})
+			},
+			setup: func(s *fake.Service) {
+				s.On("AnnotateIngress", mock.Anything, mock.Anything).Return(false, nil)
+				s.On("EnsureMonitor", mock.Anything, mock.Anything).
+					Run(func(args mock.Arguments) {
+						ingress := args.Get(1).(*networkingv1.Ingress)
+						ingress.Annotations[config.AnnotationStatus] = `{"lastError":"whoops"}`
+					}).
+					Return(true, errors.New("whoops"))
+			},
+			expectError: true,
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				ingress := &networkingv1.Ingress{}
+				require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "bar", Namespace: "kube-system"}, ingress))
+				assert.Equal(t, `{"lastError":"whoops"}`, ingress.Annotations[config.AnnotationStatus])
+			},
+		},
+		{
+			name: "it ensures monitors if provider source ranges cannot be retrieved",
+			req: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "bar",
+					Namespace: "kube-system",
+				},
+			},
+			clientFn: func() client.Client {
+				return fakeclient.NewFakeClient(&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "bar",
+						Namespace: "kube-system",
+						Annotations: map[string]string{
+							config.AnnotationEnabled: "true",
+						},
+						Finalizers: []string{Finalizer},
+					},
+				})
+			},
+			setup: func(s *fake.Service) {
+				s.On("AnnotateIngress", mock.Anything, mock.Anything).Return(false, errors.New("provider b: whoops"))
+				s.On("EnsureMonitor", mock.Anything, mock.Anything).Return(false, nil)
+			},
+			expectError: true,
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				s.AssertCalled(t, "EnsureMonitor", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "it removes the monitor annotations if monitoring was disabled",
+			req: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "bar",
+					Namespace: "kube-system",
+				},
+			},
+			clientFn: func() client.Client {
+				return fakeclient.NewFakeClient(&networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "bar",
+						Namespace: "kube-system",
+						Annotations: map[string]string{
+							config.AnnotationMonitorName: "kube-system-bar",
+							config.AnnotationMonitorID:   "123",
+							config.AnnotationStatus:      `{"monitorID":"123"}`,
+						},
+					},
+				})
+			},
+			setup: func(s *fake.Service) {
+				s.On("DeleteMonitor", mock.Anything, mock.Anything).Return(nil)
+			},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				ingress := &networkingv1.Ingress{}
+				require.NoError(t, c.Get(context.Background(), types.NamespacedName{Name: "bar", Namespace: "kube-system"}, ingress))
+				assert.Empty(t, ingress.Annotations)
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -182,7 +503,10 @@ func TestIngressReconciler_Reconcile(t *testing.T) {
 				test.setup(svc)
 			}
 
-			r := NewIngressReconciler(client, svc, &test.options)
+			selector, err := NewIngressSelector(&test.options)
+			require.NoError(t, err)
+
+			r := NewIngressReconciler(client, svc, selector, &test.options)
 
 			result, err := r.Reconcile(context.Background(), test.req)
 			if test.expectError {
@@ -215,7 +539,7 @@ func TestIngressReconciler_Reconcile_DelayCreation(t *testing.T) {
 		},
 	})
 
-	r := NewIngressReconciler(client, &fake.Service{}, &config.Options{
+	r := NewIngressReconciler(client, &fake.Service{}, &IngressSelector{}, &config.Options{
 		CreationDelay: 1 * time.Minute,
 	})
 
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/gateway"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var routeLog = logf.Log.WithName("route-reconciler")
+
+// RouteReconciler reconciles Gateway API HTTPRoutes to their desired state.
+// HTTPRoutes are handled as unstructured objects, see package gateway.
+type RouteReconciler struct {
+	client.Client
+
+	monitorService monitor.Service
+	selector       *IngressSelector
+	options        *config.Options
+}
+
+// NewRouteReconciler creates a new *RouteReconciler. Monitors of HTTPRoutes
+// which are not matched by selector are deleted.
+func NewRouteReconciler(client client.Client, monitorService monitor.Service, selector *IngressSelector, options *config.Options) *RouteReconciler {
+	return &RouteReconciler{
+		Client:         client,
+		monitorService: monitorService,
+		selector:       selector,
+		options:        options,
+	}
+}
+
+// Reconcile creates, updates or deletes monitors whenever an HTTPRoute
+// changes. It implements reconcile.Reconciler.
+func (r *RouteReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	route := gateway.NewHTTPRoute()
+
+	err := r.Get(ctx, req.NamespacedName, route)
+	if apierrors.IsNotFound(err) {
+		// The route was deleted without our finalizer. Construct a
+		// metadata-only route object just for monitor deletion.
+		route = gateway.NewHTTPRoute()
+		route.SetName(req.NamespacedName.Name)
+		route.SetNamespace(req.NamespacedName.Namespace)
+
+		err = r.monitorService.DeleteMonitor(ctx, route)
+	} else if err == nil {
+		if !route.GetDeletionTimestamp().IsZero() {
+			err = deleteMonitor(ctx, r.Client, r.monitorService, route)
+		} else if route.GetAnnotations()[config.AnnotationEnabled] == "true" && r.selector.Matches(route) {
+			createAfter := time.Until(route.GetCreationTimestamp().Add(r.options.CreationDelay))
+
+			// If a creation delay was configured, we will requeue the
+			// reconciliation until after the creation delay passed.
+			if createAfter > 0 {
+				return reconcile.Result{RequeueAfter: createAfter}, nil
+			}
+
+			err = r.handleCreateOrUpdate(ctx, route)
+		} else {
+			err = deleteMonitor(ctx, r.Client, r.monitorService, route)
+		}
+	}
+
+	return reconcile.Result{}, err
+}
+
+func (r *RouteReconciler) handleCreateOrUpdate(ctx context.Context, route client.Object) error {
+	if controllerutil.AddFinalizer(route, Finalizer) {
+		// The update will cause the creation of a new route update event, so
+		// we can return here.
+		return r.Update(ctx, route)
+	}
+
+	return ensureMonitor(ctx, r.Client, r.monitorService, route)
+}
+
+// RoutesForGateway maps a Gateway to reconcile requests for all HTTPRoutes
+// attached to it, so that monitors are updated if the Gateway listeners
+// change. It is meant to be used with handler.EnqueueRequestsFromMapFunc.
+func (r *RouteReconciler) RoutesForGateway(ctx context.Context, obj client.Object) []reconcile.Request {
+	key := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+
+	namespaces := r.options.Namespaces
+	if len(namespaces) == 0 {
+		namespaces = []string{metav1.NamespaceAll}
+	}
+
+	var requests []reconcile.Request
+
+	for _, namespace := range namespaces {
+		routes := gateway.NewHTTPRouteList()
+
+		err := r.List(ctx, routes, client.InNamespace(namespace))
+		if err != nil {
+			routeLog.Error(err, "failed to list HTTPRoutes for gateway", "gateway", key)
+			continue
+		}
+
+		for i := range routes.Items {
+			route := &routes.Items[i]
+
+			if !r.options.WatchesNamespace(route.GetNamespace()) || !attachedTo(route, key) {
+				continue
+			}
+
+			requests = append(requests, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: route.GetNamespace(), Name: route.GetName()},
+			})
+		}
+	}
+
+	return requests
+}
+
+// attachedTo returns true if route references the Gateway key as parent.
+func attachedTo(route *unstructured.Unstructured, key types.NamespacedName) bool {
+	for _, ref := range gateway.ParentGateways(route) {
+		if ref.NamespacedName == key {
+			return true
+		}
+	}
+
+	return false
+}
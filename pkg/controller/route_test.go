@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/gateway"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func newTestRoute(namespace, name string, annotations map[string]string, finalizers ...string) *unstructured.Unstructured {
+	route := gateway.NewHTTPRoute()
+	route.SetNamespace(namespace)
+	route.SetName(name)
+	route.SetAnnotations(annotations)
+	route.SetFinalizers(finalizers)
+	route.Object["spec"] = map[string]interface{}{
+		"hostnames": []interface{}{"foo.bar.baz"},
+		"parentRefs": []interface{}{
+			map[string]interface{}{"name": "gw", "namespace": "infra"},
+		},
+	}
+	return route
+}
+
+func getRoute(t *testing.T, c client.Client, name string) *unstructured.Unstructured {
+	route := gateway.NewHTTPRoute()
+	require.NoError(t, c.Get(context.Background(), types.NamespacedName{Namespace: "kube-system", Name: name}, route))
+	return route
+}
+
+func TestRouteReconciler_Reconcile(t *testing.T) {
+	enabled := map[string]string{config.AnnotationEnabled: "true"}
+
+	tests := []struct {
+		name     string
+		objects  []client.Object
+		setup    func(*fake.Service)
+		options  config.Options
+		validate func(*testing.T, client.Client, *fake.Service)
+	}{
+		{
+			name: "it deletes monitors if route was deleted",
+			setup: func(s *fake.Service) {
+				s.On("DeleteMonitor", mock.Anything, mock.MatchedBy(func(route *unstructured.Unstructured) bool {
+					return gateway.IsHTTPRoute(route) && route.GetNamespace() == "kube-system" && route.GetName() == "foo"
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "it adds the finalizer before ensuring the monitor",
+			objects: []client.Object{newTestRoute("kube-system", "foo", enabled)},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				s.AssertNotCalled(t, "EnsureMonitor", mock.Anything, mock.Anything)
+				assert.True(t, controllerutil.ContainsFinalizer(getRoute(t, c, "foo"), Finalizer))
+			},
+		},
+		{
+			name:    "it ensures the monitor and persists the recorded annotations",
+			objects: []client.Object{newTestRoute("kube-system", "foo", enabled, Finalizer)},
+			setup: func(s *fake.Service) {
+				s.On("EnsureMonitor", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					route := args.Get(1).(*unstructured.Unstructured)
+					annotations := route.GetAnnotations()
+					annotations[config.AnnotationMonitorName] = "kube-system-foo"
+					route.SetAnnotations(annotations)
+				}).Return(true, nil)
+			},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				assert.Equal(t, "kube-system-foo", getRoute(t, c, "foo").GetAnnotations()[config.AnnotationMonitorName])
+			},
+		},
+		{
+			name: "it deletes the monitor and removes finalizer and annotations if monitoring was disabled",
+			objects: []client.Object{newTestRoute("kube-system", "foo", map[string]string{
+				config.AnnotationMonitorName: "kube-system-foo",
+			}, Finalizer)},
+			setup: func(s *fake.Service) {
+				s.On("DeleteMonitor", mock.Anything, mock.Anything).Return(nil)
+			},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				route := getRoute(t, c, "foo")
+				assert.False(t, controllerutil.ContainsFinalizer(route, Finalizer))
+				assert.Empty(t, route.GetAnnotations())
+			},
+		},
+		{
+			name:    "it deletes the monitor if route is not selected",
+			objects: []client.Object{newTestRoute("kube-system", "foo", enabled, Finalizer)},
+			options: config.Options{IngressSelector: "team=foo"},
+			setup: func(s *fake.Service) {
+				s.On("DeleteMonitor", mock.Anything, mock.Anything).Return(nil)
+			},
+			validate: func(t *testing.T, c client.Client, s *fake.Service) {
+				s.AssertNotCalled(t, "EnsureMonitor", mock.Anything, mock.Anything)
+				assert.False(t, controllerutil.ContainsFinalizer(getRoute(t, c, "foo"), Finalizer))
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := fakeclient.NewClientBuilder().WithObjects(test.objects...).Build()
+
+			svc := &fake.Service{}
+
+			if test.setup != nil {
+				test.setup(svc)
+			}
+
+			selector, err := NewIngressSelector(&test.options)
+			require.NoError(t, err)
+
+			r := NewRouteReconciler(c, svc, selector, &test.options)
+
+			result, err := r.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "kube-system", Name: "foo"},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, reconcile.Result{}, result)
+
+			svc.AssertExpectations(t)
+
+			if test.validate != nil {
+				test.validate(t, c, svc)
+			}
+		})
+	}
+}
+
+func TestRouteReconciler_RoutesForGateway(t *testing.T) {
+	other := newTestRoute("kube-system", "other", nil)
+	other.Object["spec"].(map[string]interface{})["parentRefs"] = []interface{}{
+		map[string]interface{}{"name": "other-gw", "namespace": "infra"},
+	}
+
+	c := fakeclient.NewClientBuilder().WithObjects(
+		newTestRoute("kube-system", "foo", nil),
+		newTestRoute("default", "bar", nil),
+		newTestRoute("excluded", "baz", nil),
+		other,
+	).Build()
+
+	r := NewRouteReconciler(c, &fake.Service{}, &IngressSelector{}, &config.Options{ExcludedNamespaces: []string{"excluded"}})
+
+	gw := gateway.NewGateway()
+	gw.SetNamespace("infra")
+	gw.SetName("gw")
+
+	requests := r.RoutesForGateway(context.Background(), gw)
+
+	assert.ElementsMatch(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: "kube-system", Name: "foo"}},
+		{NamespacedName: types.NamespacedName{Namespace: "default", Name: "bar"}},
+	}, requests)
+}
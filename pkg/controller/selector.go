@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/pkg/errors"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// AnnotationIngressClass is the deprecated annotation for setting the class
+// of an ingress. It is still honored by most ingress controllers and takes
+// precedence over spec.ingressClassName.
+const AnnotationIngressClass = "kubernetes.io/ingress.class"
+
+// IngressSelector selects the ingresses that are managed by the controller
+// instance based on the --ingress-selector and --ingress-class flags. The
+// label selector applies to HTTPRoutes as well, while ingress classes only
+// apply to ingresses. The zero value selects all ingresses and HTTPRoutes.
+type IngressSelector struct {
+	labels  labels.Selector
+	classes []string
+}
+
+// NewIngressSelector creates a new *IngressSelector from options. Returns an
+// error if the label selector is invalid.
+func NewIngressSelector(options *config.Options) (*IngressSelector, error) {
+	selector, err := labels.Parse(options.IngressSelector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid ingress selector %q", options.IngressSelector)
+	}
+
+	return &IngressSelector{
+		labels:  selector,
+		classes: options.IngressClasses,
+	}, nil
+}
+
+// Matches returns true if obj, which is either an ingress or an HTTPRoute, is
+// selected.
+func (s *IngressSelector) Matches(obj client.Object) bool {
+	if s.labels != nil && !s.labels.Matches(labels.Set(obj.GetLabels())) {
+		return false
+	}
+
+	ingress, ok := obj.(*networkingv1.Ingress)
+	if !ok || len(s.classes) == 0 {
+		return true
+	}
+
+	class := ingressClass(ingress)
+
+	for _, c := range s.classes {
+		if c == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Predicate returns a predicate that filters out events for ingresses and
+// HTTPRoutes which are not selected. Update events pass if either the old or
+// the new object is selected, so that the monitor of an object which leaves
+// the selection is deleted by the reconciler.
+func (s *IngressSelector) Predicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return s.Matches(e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return s.Matches(e.ObjectOld) || s.Matches(e.ObjectNew)
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return s.Matches(e.Object)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return s.Matches(e.Object)
+		},
+	}
+}
+
+// ingressClass returns the class of ingress. The deprecated annotation takes
+// precedence over spec.ingressClassName.
+func ingressClass(ingress *networkingv1.Ingress) string {
+	if class, found := ingress.Annotations[AnnotationIngressClass]; found {
+		return class
+	}
+
+	if ingress.Spec.IngressClassName != nil {
+		return *ingress.Spec.IngressClassName
+	}
+
+	return ""
+}
@@ -0,0 +1,132 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func newSelectorTestIngress(labels map[string]string, className *string, annotations map[string]string) *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "kube-system",
+			Labels:      labels,
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: className,
+		},
+	}
+}
+
+func TestIngressSelector_Matches(t *testing.T) {
+	public, internal := "public", "internal"
+
+	tests := []struct {
+		name     string
+		options  *config.Options
+		ingress  *networkingv1.Ingress
+		expected bool
+	}{
+		{
+			name:     "selects all ingresses by default",
+			options:  &config.Options{},
+			ingress:  newSelectorTestIngress(nil, nil, nil),
+			expected: true,
+		},
+		{
+			name:     "matching labels",
+			options:  &config.Options{IngressSelector: "team=foo,env!=dev"},
+			ingress:  newSelectorTestIngress(map[string]string{"team": "foo"}, nil, nil),
+			expected: true,
+		},
+		{
+			name:     "labels not matching",
+			options:  &config.Options{IngressSelector: "team=foo"},
+			ingress:  newSelectorTestIngress(map[string]string{"team": "bar"}, nil, nil),
+			expected: false,
+		},
+		{
+			name:     "matching ingress class name",
+			options:  &config.Options{IngressClasses: []string{"public"}},
+			ingress:  newSelectorTestIngress(nil, &public, nil),
+			expected: true,
+		},
+		{
+			name:     "ingress class name not matching",
+			options:  &config.Options{IngressClasses: []string{"public"}},
+			ingress:  newSelectorTestIngress(nil, &internal, nil),
+			expected: false,
+		},
+		{
+			name:     "ingress without class",
+			options:  &config.Options{IngressClasses: []string{"public"}},
+			ingress:  newSelectorTestIngress(nil, nil, nil),
+			expected: false,
+		},
+		{
+			name:     "class annotation takes precedence",
+			options:  &config.Options{IngressClasses: []string{"public"}},
+			ingress:  newSelectorTestIngress(nil, &internal, map[string]string{AnnotationIngressClass: "public"}),
+			expected: true,
+		},
+		{
+			name:     "labels and class must match",
+			options:  &config.Options{IngressSelector: "team=foo", IngressClasses: []string{"public"}},
+			ingress:  newSelectorTestIngress(map[string]string{"team": "bar"}, &public, nil),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selector, err := NewIngressSelector(test.options)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, selector.Matches(test.ingress))
+		})
+	}
+}
+
+func TestIngressSelector_Matches_HTTPRoute(t *testing.T) {
+	selector, err := NewIngressSelector(&config.Options{IngressSelector: "team=foo", IngressClasses: []string{"public"}})
+	require.NoError(t, err)
+
+	route := newTestRoute("kube-system", "foo", nil)
+	assert.False(t, selector.Matches(route))
+
+	route.SetLabels(map[string]string{"team": "foo"})
+	assert.True(t, selector.Matches(route), "ingress classes must not apply to HTTPRoutes")
+}
+
+func TestIngressSelector_Predicate(t *testing.T) {
+	selector, err := NewIngressSelector(&config.Options{IngressSelector: "team=foo"})
+	require.NoError(t, err)
+
+	p := selector.Predicate()
+
+	selected := newSelectorTestIngress(map[string]string{"team": "foo"}, nil, nil)
+	unselected := newSelectorTestIngress(map[string]string{"team": "bar"}, nil, nil)
+
+	assert.True(t, p.Create(event.CreateEvent{Object: selected}))
+	assert.False(t, p.Create(event.CreateEvent{Object: unselected}))
+	assert.True(t, p.Delete(event.DeleteEvent{Object: selected}))
+	assert.False(t, p.Delete(event.DeleteEvent{Object: unselected}))
+
+	// Ingresses leaving the selection must be reconciled to delete the
+	// monitor.
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: selected, ObjectNew: unselected}))
+	assert.True(t, p.Update(event.UpdateEvent{ObjectOld: unselected, ObjectNew: selected}))
+	assert.False(t, p.Update(event.UpdateEvent{ObjectOld: unselected, ObjectNew: unselected}))
+}
+
+func TestNewIngressSelector_Invalid(t *testing.T) {
+	_, err := NewIngressSelector(&config.Options{IngressSelector: "foo in (bar"})
+	require.Error(t, err)
+}
@@ -0,0 +1,231 @@
+// Package gateway contains helpers for Gateway API resources. The resources
+// are handled as unstructured objects, so that the controller does not
+// depend on the Gateway API types and works with any cluster that has the
+// gateway.networking.k8s.io/v1 CRDs installed.
+package gateway
+
+import (
+	"strings"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/ingress"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Group is the API group of the Gateway API resources.
+const Group = "gateway.networking.k8s.io"
+
+// HTTPRouteGVK is the GroupVersionKind of the Gateway API HTTPRoute
+// resource.
+var HTTPRouteGVK = schema.GroupVersionKind{
+	Group:   Group,
+	Version: "v1",
+	Kind:    "HTTPRoute",
+}
+
+// GatewayGVK is the GroupVersionKind of the Gateway API Gateway resource.
+var GatewayGVK = schema.GroupVersionKind{
+	Group:   Group,
+	Version: "v1",
+	Kind:    "Gateway",
+}
+
+// NewHTTPRoute creates a new empty HTTPRoute.
+func NewHTTPRoute() *unstructured.Unstructured {
+	return newObject(HTTPRouteGVK)
+}
+
+// NewHTTPRouteList creates a new empty HTTPRoute list.
+func NewHTTPRouteList() *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(HTTPRouteGVK.GroupVersion().WithKind(HTTPRouteGVK.Kind + "List"))
+	return list
+}
+
+// NewGateway creates a new empty Gateway.
+func NewGateway() *unstructured.Unstructured {
+	return newObject(GatewayGVK)
+}
+
+func newObject(gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	return obj
+}
+
+// IsHTTPRoute returns true if obj is an HTTPRoute.
+func IsHTTPRoute(obj runtime.Object) bool {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+
+	return gvk.Group == HTTPRouteGVK.Group && gvk.Kind == HTTPRouteGVK.Kind
+}
+
+// ParentRef is a reference from an HTTPRoute to a parent Gateway.
+type ParentRef struct {
+	types.NamespacedName
+
+	// SectionName is the name of the Gateway listener the route attaches
+	// to. If empty, the route attaches to all listeners.
+	SectionName string
+}
+
+// ParentGateways returns the Gateways referenced in the spec.parentRefs of
+// route. References to parents of other kinds are ignored. References
+// without namespace default to the namespace of route.
+func ParentGateways(route *unstructured.Unstructured) []ParentRef {
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+
+	refs := make([]ParentRef, 0, len(parentRefs))
+
+	for _, parentRef := range parentRefs {
+		ref, ok := parentRef.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		group := stringField(ref, "group", Group)
+		kind := stringField(ref, "kind", GatewayGVK.Kind)
+
+		if group != Group || kind != GatewayGVK.Kind {
+			continue
+		}
+
+		refs = append(refs, ParentRef{
+			NamespacedName: types.NamespacedName{
+				Namespace: stringField(ref, "namespace", route.GetNamespace()),
+				Name:      stringField(ref, "name", ""),
+			},
+			SectionName: stringField(ref, "sectionName", ""),
+		})
+	}
+
+	return refs
+}
+
+// Validate checks if route fulfills all criteria for a monitor and returns
+// an error on any violation. That is, the route must have at least one
+// hostname and the first hostname must not contain wildcards.
+func Validate(route *unstructured.Unstructured) error {
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+
+	if len(hostnames) == 0 {
+		return errors.New("HTTPRoute does not have any hostnames")
+	}
+
+	if strings.Contains(hostnames[0], "*") {
+		return errors.Errorf("HTTPRoute hostname %q contains wildcards", hostnames[0])
+	}
+
+	return nil
+}
+
+// ValidateHostnames checks if route has at least one hostname that a monitor
+// can be created for. Unlike Validate it does not reject routes with wildcard
+// hostnames, as those are skipped by EndpointsFor.
+func ValidateHostnames(route *unstructured.Unstructured) error {
+	if len(hostnames(route)) == 0 {
+		return errors.New("HTTPRoute does not have any hostnames without wildcards")
+	}
+
+	return nil
+}
+
+// EndpointFor returns the endpoint of route. The host is the first hostname
+// of the route. It is served via TLS if any listener of the parent gateways
+// that the route attaches to uses the HTTPS protocol. The gateways are looked
+// up in gateways by namespace and name, parents that are missing are
+// ignored. Unvalidated routes may cause EndpointFor to panic.
+func EndpointFor(route *unstructured.Unstructured, gateways map[types.NamespacedName]*unstructured.Unstructured) ingress.Endpoint {
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+
+	return endpointFor(route, gateways, hostnames[0])
+}
+
+// EndpointsFor returns the endpoints of all distinct hostnames of route
+// which do not contain wildcards. See EndpointFor for how TLS is detected.
+func EndpointsFor(route *unstructured.Unstructured, gateways map[types.NamespacedName]*unstructured.Unstructured) []ingress.Endpoint {
+	hosts := hostnames(route)
+
+	endpoints := make([]ingress.Endpoint, len(hosts))
+	for i, host := range hosts {
+		endpoints[i] = endpointFor(route, gateways, host)
+	}
+
+	return endpoints
+}
+
+func endpointFor(route *unstructured.Unstructured, gateways map[types.NamespacedName]*unstructured.Unstructured, host string) ingress.Endpoint {
+	endpoint := ingress.Endpoint{Host: host}
+
+	for _, ref := range ParentGateways(route) {
+		gateway, found := gateways[ref.NamespacedName]
+		if !found {
+			continue
+		}
+
+		if servesHTTPS(gateway, ref.SectionName, host) {
+			endpoint.TLS = true
+			break
+		}
+	}
+
+	return endpoint
+}
+
+// hostnames returns the distinct hostnames of route without wildcards.
+func hostnames(route *unstructured.Unstructured) []string {
+	all, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+
+	seen := make(map[string]bool)
+	hosts := make([]string, 0, len(all))
+
+	for _, host := range all {
+		if host == "" || strings.Contains(host, "*") || seen[host] {
+			continue
+		}
+
+		seen[host] = true
+		hosts = append(hosts, host)
+	}
+
+	return hosts
+}
+
+// servesHTTPS returns true if gateway has an HTTPS listener for host. If
+// sectionName is not empty, only the listener with that name is considered.
+func servesHTTPS(gateway *unstructured.Unstructured, sectionName, host string) bool {
+	listeners, _, _ := unstructured.NestedSlice(gateway.Object, "spec", "listeners")
+
+	for _, l := range listeners {
+		listener, ok := l.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if sectionName != "" && stringField(listener, "name", "") != sectionName {
+			continue
+		}
+
+		if stringField(listener, "protocol", "") != "HTTPS" {
+			continue
+		}
+
+		if ingress.MatchesHostname(stringField(listener, "hostname", ""), host) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func stringField(obj map[string]interface{}, field, defaultValue string) string {
+	value, ok := obj[field].(string)
+	if !ok || value == "" {
+		return defaultValue
+	}
+
+	return value
+}
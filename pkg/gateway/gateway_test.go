@@ -0,0 +1,191 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/ingress"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func newRoute(spec map[string]interface{}) *unstructured.Unstructured {
+	route := NewHTTPRoute()
+	route.SetNamespace("kube-system")
+	route.SetName("foo")
+	route.Object["spec"] = spec
+	return route
+}
+
+func newGateway(namespace, name string, listeners ...interface{}) *unstructured.Unstructured {
+	gateway := NewGateway()
+	gateway.SetNamespace(namespace)
+	gateway.SetName(name)
+	gateway.Object["spec"] = map[string]interface{}{
+		"listeners": listeners,
+	}
+	return gateway
+}
+
+func TestIsHTTPRoute(t *testing.T) {
+	assert.True(t, IsHTTPRoute(NewHTTPRoute()))
+	assert.False(t, IsHTTPRoute(NewGateway()))
+	assert.False(t, IsHTTPRoute(&unstructured.Unstructured{}))
+}
+
+func TestParentGateways(t *testing.T) {
+	route := newRoute(map[string]interface{}{
+		"parentRefs": []interface{}{
+			map[string]interface{}{"name": "gw"},
+			map[string]interface{}{"name": "gw", "namespace": "infra", "sectionName": "https"},
+			map[string]interface{}{"name": "svc", "group": "", "kind": "Service"},
+			map[string]interface{}{"name": "other", "group": "example.com", "kind": "Gateway"},
+		},
+	})
+
+	expected := []ParentRef{
+		{NamespacedName: types.NamespacedName{Namespace: "kube-system", Name: "gw"}},
+		{NamespacedName: types.NamespacedName{Namespace: "infra", Name: "gw"}, SectionName: "https"},
+	}
+
+	assert.Equal(t, expected, ParentGateways(route))
+	assert.Empty(t, ParentGateways(newRoute(nil)))
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		route    *unstructured.Unstructured
+		expected error
+	}{
+		{
+			name: "valid route",
+			route: newRoute(map[string]interface{}{
+				"hostnames": []interface{}{"foo.bar.baz"},
+			}),
+		},
+		{
+			name:     "route without hostnames",
+			route:    newRoute(map[string]interface{}{}),
+			expected: errors.New("HTTPRoute does not have any hostnames"),
+		},
+		{
+			name: "wildcard hostnames are not supported",
+			route: newRoute(map[string]interface{}{
+				"hostnames": []interface{}{"*.bar.baz"},
+			}),
+			expected: errors.New(`HTTPRoute hostname "*.bar.baz" contains wildcards`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Validate(test.route)
+			if test.expected != nil {
+				assert.EqualError(t, err, test.expected.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestEndpointFor(t *testing.T) {
+	httpListener := map[string]interface{}{"name": "http", "protocol": "HTTP", "port": int64(80)}
+	httpsListener := map[string]interface{}{"name": "https", "protocol": "HTTPS", "port": int64(443)}
+
+	tests := []struct {
+		name       string
+		parentRefs []interface{}
+		gateways   []*unstructured.Unstructured
+		expected   ingress.Endpoint
+	}{
+		{
+			name:       "http only gateway",
+			parentRefs: []interface{}{map[string]interface{}{"name": "gw"}},
+			gateways:   []*unstructured.Unstructured{newGateway("kube-system", "gw", httpListener)},
+			expected:   ingress.Endpoint{Host: "foo.bar.baz"},
+		},
+		{
+			name:       "gateway with https listener",
+			parentRefs: []interface{}{map[string]interface{}{"name": "gw"}},
+			gateways:   []*unstructured.Unstructured{newGateway("kube-system", "gw", httpListener, httpsListener)},
+			expected:   ingress.Endpoint{Host: "foo.bar.baz", TLS: true},
+		},
+		{
+			name:       "route attached to http listener only",
+			parentRefs: []interface{}{map[string]interface{}{"name": "gw", "sectionName": "http"}},
+			gateways:   []*unstructured.Unstructured{newGateway("kube-system", "gw", httpListener, httpsListener)},
+			expected:   ingress.Endpoint{Host: "foo.bar.baz"},
+		},
+		{
+			name:       "gateway in other namespace",
+			parentRefs: []interface{}{map[string]interface{}{"name": "gw", "namespace": "infra"}},
+			gateways:   []*unstructured.Unstructured{newGateway("infra", "gw", httpsListener)},
+			expected:   ingress.Endpoint{Host: "foo.bar.baz", TLS: true},
+		},
+		{
+			name:       "missing gateway",
+			parentRefs: []interface{}{map[string]interface{}{"name": "gw"}},
+			expected:   ingress.Endpoint{Host: "foo.bar.baz"},
+		},
+		{
+			name:       "https listener with matching wildcard hostname",
+			parentRefs: []interface{}{map[string]interface{}{"name": "gw"}},
+			gateways: []*unstructured.Unstructured{newGateway("kube-system", "gw",
+				map[string]interface{}{"name": "https", "protocol": "HTTPS", "hostname": "*.bar.baz"},
+			)},
+			expected: ingress.Endpoint{Host: "foo.bar.baz", TLS: true},
+		},
+		{
+			name:       "https listener for other hostname",
+			parentRefs: []interface{}{map[string]interface{}{"name": "gw"}},
+			gateways: []*unstructured.Unstructured{newGateway("kube-system", "gw",
+				map[string]interface{}{"name": "https", "protocol": "HTTPS", "hostname": "*.example.com"},
+			)},
+			expected: ingress.Endpoint{Host: "foo.bar.baz"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			route := newRoute(map[string]interface{}{
+				"hostnames":  []interface{}{"foo.bar.baz", "other.bar.baz"},
+				"parentRefs": test.parentRefs,
+			})
+
+			gateways := make(map[types.NamespacedName]*unstructured.Unstructured)
+			for _, gateway := range test.gateways {
+				gateways[types.NamespacedName{Namespace: gateway.GetNamespace(), Name: gateway.GetName()}] = gateway
+			}
+
+			assert.Equal(t, test.expected, EndpointFor(route, gateways))
+		})
+	}
+}
+
+func TestEndpointsFor(t *testing.T) {
+	route := newRoute(map[string]interface{}{
+		"hostnames":  []interface{}{"foo.bar.baz", "*.bar.baz", "foo.example.com", "foo.bar.baz"},
+		"parentRefs": []interface{}{map[string]interface{}{"name": "gw"}},
+	})
+
+	gateways := map[types.NamespacedName]*unstructured.Unstructured{
+		{Namespace: "kube-system", Name: "gw"}: newGateway("kube-system", "gw",
+			map[string]interface{}{"name": "http", "protocol": "HTTP"},
+			map[string]interface{}{"name": "https", "protocol": "HTTPS", "hostname": "*.bar.baz"},
+		),
+	}
+
+	expected := []ingress.Endpoint{
+		{Host: "foo.bar.baz", TLS: true},
+		{Host: "foo.example.com"},
+	}
+
+	assert.Equal(t, expected, EndpointsFor(route, gateways))
+	assert.NoError(t, ValidateHostnames(route))
+	assert.EqualError(t, ValidateHostnames(newRoute(map[string]interface{}{
+		"hostnames": []interface{}{"*.bar.baz"},
+	})), "HTTPRoute does not have any hostnames without wildcards")
+}
@@ -3,6 +3,7 @@ package ingress
 import (
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
@@ -12,6 +13,10 @@ import (
 
 const (
 	nginxForceSSLRedirectAnnotation = "nginx.ingress.kubernetes.io/force-ssl-redirect"
+
+	// regexChars are characters which indicate that a path is a regular
+	// expression.
+	regexChars = `^$*+?()[]{}|\`
 )
 
 // Validate checks if ingress fulfills all criteria for an ingress
@@ -35,44 +40,329 @@ func Validate(ingress *networkingv1.Ingress) error {
 	return nil
 }
 
+// Endpoint is the host of a resource that is monitored together with the
+// information whether it is served via TLS. It abstracts over the kinds of
+// resources monitors can be created for, e.g. Ingresses and Gateway API
+// HTTPRoutes.
+type Endpoint struct {
+	// Host is the host name the resource is served on.
+	Host string
+
+	// TLS is true if the host is served via https.
+	TLS bool
+
+	// Path is the monitored path. If empty, the root path or the path
+	// override of the resource is monitored.
+	Path string
+
+	// URL is the complete URL to monitor. If set, it is used as is instead
+	// of building it from the other fields.
+	URL string
+}
+
+// EndpointFor returns the endpoint of ingress. The first TLS host takes
+// precedence over the host of the first rule. Unvalidated ingresses may cause
+// EndpointFor to panic.
+func EndpointFor(ingress *networkingv1.Ingress) Endpoint {
+	if supportsTLS(ingress) {
+		return Endpoint{Host: ingress.Spec.TLS[0].Hosts[0], TLS: true}
+	}
+
+	forceSSLRedirect := config.Annotations(ingress.Annotations).BoolValue(nginxForceSSLRedirectAnnotation)
+
+	return Endpoint{Host: ingress.Spec.Rules[0].Host, TLS: forceSSLRedirect}
+}
+
+// ValidateHosts checks if ingress has at least one host that a monitor can be
+// created for. Unlike Validate it does not reject ingresses with wildcard
+// hosts, as those are skipped by EndpointsFor.
+func ValidateHosts(ingress *networkingv1.Ingress) error {
+	if len(EndpointsFor(ingress)) == 0 {
+		return errors.New("ingress does not have any hosts without wildcards")
+	}
+
+	return nil
+}
+
+// EndpointsFor returns the endpoints of all distinct hosts of ingress. TLS
+// hosts come first, followed by the hosts of the rules. Hosts are served via
+// TLS if they are matched by any TLS host. Empty hosts and hosts containing
+// wildcards are skipped.
+func EndpointsFor(ingress *networkingv1.Ingress) []Endpoint {
+	return endpointsFor(ingress, allHosts(ingress))
+}
+
+// ProbeHosts returns the hosts listed in the AnnotationProbeHosts annotation
+// of ingress.
+func ProbeHosts(ingress *networkingv1.Ingress) []string {
+	var hosts []string
+
+	for _, host := range config.Annotations(ingress.Annotations).StringSliceValue(config.AnnotationProbeHosts) {
+		host = strings.TrimSpace(host)
+		if host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts
+}
+
+// ValidateProbeHosts checks that all probe hosts of ingress are concrete
+// host names which are matched by at least one host of the ingress, e.g. by a
+// wildcard host. Returns an error if the ingress does not have any probe
+// hosts.
+func ValidateProbeHosts(ingress *networkingv1.Ingress) error {
+	probeHosts := ProbeHosts(ingress)
+	if len(probeHosts) == 0 {
+		return errors.Errorf("annotation %s does not contain any hosts", config.AnnotationProbeHosts)
+	}
+
+	hosts := allHosts(ingress)
+
+	for _, probeHost := range probeHosts {
+		if containsWildcard(probeHost) {
+			return errors.Errorf("probe host %q is not a concrete host name", probeHost)
+		}
+
+		if !matchesAny(hosts, probeHost) {
+			return errors.Errorf("probe host %q does not match any host of the ingress", probeHost)
+		}
+	}
+
+	return nil
+}
+
+// ProbeEndpointsFor returns the endpoints of all distinct probe hosts of
+// ingress. See EndpointsFor for how TLS is detected. Unvalidated ingresses
+// may contain probe hosts which are not served by the ingress.
+func ProbeEndpointsFor(ingress *networkingv1.Ingress) []Endpoint {
+	return endpointsFor(ingress, ProbeHosts(ingress))
+}
+
+// allHosts returns the TLS hosts of ingress followed by the hosts of its
+// rules.
+func allHosts(ingress *networkingv1.Ingress) []string {
+	var hosts []string
+
+	for _, tls := range ingress.Spec.TLS {
+		hosts = append(hosts, tls.Hosts...)
+	}
+
+	for _, rule := range ingress.Spec.Rules {
+		hosts = append(hosts, rule.Host)
+	}
+
+	return hosts
+}
+
+// endpointsFor returns the endpoints for the distinct hosts of ingress.
+// Empty hosts and hosts containing wildcards are skipped.
+func endpointsFor(ingress *networkingv1.Ingress, hosts []string) []Endpoint {
+	var tlsHosts []string
+
+	for _, tls := range ingress.Spec.TLS {
+		tlsHosts = append(tlsHosts, tls.Hosts...)
+	}
+
+	forceSSLRedirect := config.Annotations(ingress.Annotations).BoolValue(nginxForceSSLRedirectAnnotation)
+
+	seen := make(map[string]bool)
+	endpoints := make([]Endpoint, 0, len(hosts))
+
+	for _, host := range hosts {
+		if host == "" || containsWildcard(host) || seen[host] {
+			continue
+		}
+
+		seen[host] = true
+
+		endpoints = append(endpoints, Endpoint{
+			Host: host,
+			TLS:  forceSSLRedirect || matchesAny(tlsHosts, host),
+		})
+	}
+
+	return endpoints
+}
+
+// MatchesHost returns true if the ingress host pattern matches host. Empty
+// patterns match all hosts. As defined for ingress rules, wildcard patterns
+// like *.example.com only match a single DNS label, e.g. foo.example.com but
+// not foo.bar.example.com.
+func MatchesHost(pattern, host string) bool {
+	if !MatchesHostname(pattern, host) {
+		return false
+	}
+
+	if !strings.HasPrefix(pattern, "*.") {
+		return true
+	}
+
+	return !strings.Contains(host[:len(host)-len(pattern)+1], ".")
+}
+
+// MatchesHostname returns true if the Gateway API listener hostname pattern
+// matches host. Empty patterns match all hosts, wildcard patterns like
+// *.example.com match all subdomains, including those with multiple labels.
+// Use MatchesHost for ingress hosts.
+func MatchesHostname(pattern, host string) bool {
+	if pattern == "" || pattern == host {
+		return true
+	}
+
+	if !strings.HasPrefix(pattern, "*.") {
+		return false
+	}
+
+	return strings.HasSuffix(host, pattern[1:]) && len(host) > len(pattern)-1
+}
+
+// PathEndpoints expands endpoints into one endpoint per path of the ingress
+// rules matching the endpoint's host if path monitoring is enabled via the
+// AnnotationMonitorPaths annotation. Otherwise endpoints are returned as is.
+// Paths of type ImplementationSpecific and paths containing regular
+// expressions cannot be probed and are skipped. Endpoints without any
+// monitored paths are kept, so that the host is still monitored.
+func PathEndpoints(ingress *networkingv1.Ingress, endpoints []Endpoint) []Endpoint {
+	all, selected := monitoredPaths(ingress.Annotations)
+	if !all && len(selected) == 0 {
+		return endpoints
+	}
+
+	result := make([]Endpoint, 0, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		n := len(result)
+
+		for _, path := range probeablePaths(ingress, endpoint.Host) {
+			if all || containsString(selected, path) {
+				result = append(result, Endpoint{Host: endpoint.Host, TLS: endpoint.TLS, Path: path})
+			}
+		}
+
+		if len(result) == n {
+			result = append(result, endpoint)
+		}
+	}
+
+	return result
+}
+
+// monitoredPaths parses the value of the AnnotationMonitorPaths annotation.
+// If all is true, all paths are monitored, otherwise only the selected ones.
+func monitoredPaths(annotations map[string]string) (all bool, selected []string) {
+	value := strings.TrimSpace(annotations[config.AnnotationMonitorPaths])
+
+	if enabled, err := strconv.ParseBool(value); err == nil {
+		return enabled, nil
+	}
+
+	for _, path := range strings.Split(value, ",") {
+		path = strings.TrimSpace(path)
+		if path != "" {
+			selected = append(selected, path)
+		}
+	}
+
+	return false, selected
+}
+
+// probeablePaths returns the distinct paths of all ingress rules matching
+// host which can be probed.
+func probeablePaths(ingress *networkingv1.Ingress, host string) []string {
+	seen := make(map[string]bool)
+
+	var paths []string
+
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil || !MatchesHost(rule.Host, host) {
+			continue
+		}
+
+		for _, path := range rule.HTTP.Paths {
+			if !isProbeable(path) || seen[path.Path] {
+				continue
+			}
+
+			seen[path.Path] = true
+			paths = append(paths, path.Path)
+		}
+	}
+
+	return paths
+}
+
+// isProbeable returns true if path is an Exact or Prefix path without
+// regular expression characters. The meaning of ImplementationSpecific paths
+// depends on the ingress controller, e.g. ingress-nginx treats them as
+// regular expressions, so they are not probed.
+func isProbeable(path networkingv1.HTTPIngressPath) bool {
+	if path.PathType == nil || *path.PathType == networkingv1.PathTypeImplementationSpecific {
+		return false
+	}
+
+	return strings.HasPrefix(path.Path, "/") && !strings.ContainsAny(path.Path, regexChars)
+}
+
+// matchesAny returns true if host is matched by any of the non-empty ingress
+// host patterns.
+func matchesAny(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if pattern != "" && MatchesHost(pattern, host) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // BuildMonitorURL builds the url that should be monitored on the ingress.
 // Unvalidated ingresses may cause BuildMonitorURL to panic.
 func BuildMonitorURL(ingress *networkingv1.Ingress) (string, error) {
-	host := buildHostURL(ingress)
+	return BuildURL(EndpointFor(ingress), ingress.Annotations)
+}
 
-	url, err := url.Parse(host)
-	if err != nil {
-		return "", err
+// BuildURL builds the url that should be monitored for endpoint. The
+// annotations of the monitored resource may force https and override the
+// path. The path of endpoint takes precedence over the path override. If
+// endpoint has a URL, it is returned as is.
+func BuildURL(endpoint Endpoint, annotations map[string]string) (string, error) {
+	if endpoint.URL != "" {
+		return endpoint.URL, nil
 	}
 
-	path, found := ingress.Annotations[config.AnnotationPathOverride]
-	if found {
-		url.Path = path
+	scheme := "http"
+	if endpoint.TLS || config.Annotations(annotations).BoolValue(config.AnnotationForceHTTPS) {
+		scheme = "https"
 	}
 
-	return url.String(), nil
-}
-
-func buildHostURL(ingress *networkingv1.Ingress) string {
-	if supportsTLS(ingress) {
-		return fmt.Sprintf("https://%s", ingress.Spec.TLS[0].Hosts[0])
+	url, err := url.Parse(fmt.Sprintf("%s://%s", scheme, endpoint.Host))
+	if err != nil {
+		return "", err
 	}
 
-	if forceHTTPS(ingress) {
-		return fmt.Sprintf("https://%s", ingress.Spec.Rules[0].Host)
+	path, found := annotations[config.AnnotationPathOverride]
+	if endpoint.Path != "" {
+		url.Path = endpoint.Path
+	} else if found {
+		url.Path = path
 	}
 
-	return fmt.Sprintf("http://%s", ingress.Spec.Rules[0].Host)
+	return url.String(), nil
 }
 
 func supportsTLS(ingress *networkingv1.Ingress) bool {
 	return len(ingress.Spec.TLS) > 0 && len(ingress.Spec.TLS[0].Hosts) > 0 && len(ingress.Spec.TLS[0].Hosts[0]) > 0
 }
 
-func forceHTTPS(ingress *networkingv1.Ingress) bool {
-	annotations := config.Annotations(ingress.Annotations)
+func containsString(s []string, e string) bool {
+	for _, v := range s {
+		if v == e {
+			return true
+		}
+	}
 
-	return annotations.BoolValue(config.AnnotationForceHTTPS) || annotations.BoolValue(nginxForceSSLRedirectAnnotation)
+	return false
 }
 
 func containsWildcard(hostName string) bool {
@@ -175,3 +175,372 @@ func TestBuildMonitorURL(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		endpoint    Endpoint
+		annotations map[string]string
+		expected    string
+	}{
+		{
+			name:     "http endpoint",
+			endpoint: Endpoint{Host: "foo.bar.baz"},
+			expected: "http://foo.bar.baz",
+		},
+		{
+			name:     "TLS endpoint",
+			endpoint: Endpoint{Host: "foo.bar.baz", TLS: true},
+			expected: "https://foo.bar.baz",
+		},
+		{
+			name:     "force https annotation",
+			endpoint: Endpoint{Host: "foo.bar.baz"},
+			annotations: map[string]string{
+				config.AnnotationForceHTTPS: "true",
+			},
+			expected: "https://foo.bar.baz",
+		},
+		{
+			name:     "nginx ingress redirect annotation is ignored",
+			endpoint: Endpoint{Host: "foo.bar.baz"},
+			annotations: map[string]string{
+				nginxForceSSLRedirectAnnotation: "true",
+			},
+			expected: "http://foo.bar.baz",
+		},
+		{
+			name:     "path override annotation",
+			endpoint: Endpoint{Host: "foo.bar.baz", TLS: true},
+			annotations: map[string]string{
+				config.AnnotationPathOverride: "/health",
+			},
+			expected: "https://foo.bar.baz/health",
+		},
+		{
+			name:     "endpoint path takes precedence over path override",
+			endpoint: Endpoint{Host: "foo.bar.baz", Path: "/api"},
+			annotations: map[string]string{
+				config.AnnotationPathOverride: "/health",
+			},
+			expected: "http://foo.bar.baz/api",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			url, err := BuildURL(test.endpoint, test.annotations)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, url)
+		})
+	}
+}
+
+func TestMatchesHost(t *testing.T) {
+	assert.True(t, MatchesHost("", "foo.bar.baz"))
+	assert.True(t, MatchesHost("foo.bar.baz", "foo.bar.baz"))
+	assert.True(t, MatchesHost("*.bar.baz", "foo.bar.baz"))
+	assert.False(t, MatchesHost("*.baz", "foo.bar.baz"))
+	assert.False(t, MatchesHost("*.bar.baz", "a.foo.bar.baz"))
+	assert.False(t, MatchesHost("*.bar.baz", "bar.baz"))
+	assert.False(t, MatchesHost("other.bar.baz", "foo.bar.baz"))
+}
+
+func TestMatchesHostname(t *testing.T) {
+	assert.True(t, MatchesHostname("", "foo.bar.baz"))
+	assert.True(t, MatchesHostname("foo.bar.baz", "foo.bar.baz"))
+	assert.True(t, MatchesHostname("*.bar.baz", "foo.bar.baz"))
+	assert.True(t, MatchesHostname("*.baz", "foo.bar.baz"))
+	assert.True(t, MatchesHostname("*.bar.baz", "a.foo.bar.baz"))
+	assert.False(t, MatchesHostname("*.bar.baz", "bar.baz"))
+	assert.False(t, MatchesHostname("other.bar.baz", "foo.bar.baz"))
+}
+
+func TestEndpointsFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		ingress  *networkingv1.Ingress
+		expected []Endpoint
+	}{
+		{
+			name: "multiple rules",
+			ingress: &networkingv1.Ingress{
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{Host: "foo.bar.baz"},
+						{Host: "*.bar.baz"},
+						{Host: ""},
+						{Host: "bar.bar.baz"},
+						{Host: "foo.bar.baz"},
+					},
+				},
+			},
+			expected: []Endpoint{
+				{Host: "foo.bar.baz"},
+				{Host: "bar.bar.baz"},
+			},
+		},
+		{
+			name: "TLS hosts come first",
+			ingress: &networkingv1.Ingress{
+				Spec: networkingv1.IngressSpec{
+					TLS: []networkingv1.IngressTLS{
+						{Hosts: []string{"bar.bar.baz"}},
+						{Hosts: []string{"tls-only.bar.baz"}},
+					},
+					Rules: []networkingv1.IngressRule{
+						{Host: "foo.bar.baz"},
+						{Host: "bar.bar.baz"},
+					},
+				},
+			},
+			expected: []Endpoint{
+				{Host: "bar.bar.baz", TLS: true},
+				{Host: "tls-only.bar.baz", TLS: true},
+				{Host: "foo.bar.baz"},
+			},
+		},
+		{
+			name: "wildcard TLS hosts",
+			ingress: &networkingv1.Ingress{
+				Spec: networkingv1.IngressSpec{
+					TLS: []networkingv1.IngressTLS{
+						{Hosts: []string{"*.bar.baz"}},
+					},
+					Rules: []networkingv1.IngressRule{
+						{Host: "foo.bar.baz"},
+						{Host: "foo.example.com"},
+						{Host: "a.foo.bar.baz"},
+					},
+				},
+			},
+			expected: []Endpoint{
+				{Host: "foo.bar.baz", TLS: true},
+				{Host: "foo.example.com"},
+				{Host: "a.foo.bar.baz"},
+			},
+		},
+		{
+			name: "nginx ingress redirect annotation",
+			ingress: &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						nginxForceSSLRedirectAnnotation: "true",
+					},
+				},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{Host: "foo.bar.baz"},
+					},
+				},
+			},
+			expected: []Endpoint{
+				{Host: "foo.bar.baz", TLS: true},
+			},
+		},
+		{
+			name: "only wildcards",
+			ingress: &networkingv1.Ingress{
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{Host: "*.bar.baz"},
+					},
+				},
+			},
+			expected: []Endpoint{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			endpoints := EndpointsFor(test.ingress)
+			assert.Equal(t, test.expected, endpoints)
+
+			if len(test.expected) == 0 {
+				assert.EqualError(t, ValidateHosts(test.ingress), "ingress does not have any hosts without wildcards")
+			} else {
+				assert.NoError(t, ValidateHosts(test.ingress))
+			}
+		})
+	}
+}
+
+func TestPathEndpoints(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	exact := networkingv1.PathTypeExact
+	implementationSpecific := networkingv1.PathTypeImplementationSpecific
+
+	rules := []networkingv1.IngressRule{
+		{
+			Host: "foo.bar.baz",
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/api", PathType: &prefix},
+						{Path: "/auth/health", PathType: &exact},
+						{Path: "/legacy", PathType: &implementationSpecific},
+						{Path: "/images(/|$)(.*)", PathType: &prefix},
+						{Path: "/untyped"},
+						{Path: "/api", PathType: &exact},
+					},
+				},
+			},
+		},
+		{
+			Host: "other.bar.baz",
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/other", PathType: &prefix},
+					},
+				},
+			},
+		},
+		{
+			Host: "regex.bar.baz",
+			IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/(.*)", PathType: &implementationSpecific},
+					},
+				},
+			},
+		},
+	}
+
+	endpoints := []Endpoint{
+		{Host: "foo.bar.baz", TLS: true},
+		{Host: "regex.bar.baz"},
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		expected []Endpoint
+	}{
+		{
+			name:     "path monitoring disabled",
+			value:    "false",
+			expected: endpoints,
+		},
+		{
+			name:  "all probeable paths",
+			value: "true",
+			expected: []Endpoint{
+				{Host: "foo.bar.baz", TLS: true, Path: "/api"},
+				{Host: "foo.bar.baz", TLS: true, Path: "/auth/health"},
+				{Host: "regex.bar.baz"},
+			},
+		},
+		{
+			name:  "selected paths",
+			value: "/auth/health, /other",
+			expected: []Endpoint{
+				{Host: "foo.bar.baz", TLS: true, Path: "/auth/health"},
+				{Host: "regex.bar.baz"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						config.AnnotationMonitorPaths: test.value,
+					},
+				},
+				Spec: networkingv1.IngressSpec{Rules: rules},
+			}
+
+			assert.Equal(t, test.expected, PathEndpoints(ing, endpoints))
+		})
+	}
+}
+
+func TestValidateProbeHosts(t *testing.T) {
+	tests := []struct {
+		name       string
+		probeHosts string
+		expected   error
+	}{
+		{
+			name:       "hosts matching wildcard host",
+			probeHosts: "shop.bar.baz, blog.bar.baz",
+		},
+		{
+			name:       "host matching concrete host",
+			probeHosts: "foo.example.com",
+		},
+		{
+			name:     "no hosts",
+			expected: errors.New("annotation ingress-monitor.bonial.com/probe-hosts does not contain any hosts"),
+		},
+		{
+			name:       "wildcard probe host",
+			probeHosts: "*.bar.baz",
+			expected:   errors.New(`probe host "*.bar.baz" is not a concrete host name`),
+		},
+		{
+			name:       "probe host not served by the ingress",
+			probeHosts: "shop.bar.baz,shop.example.com",
+			expected:   errors.New(`probe host "shop.example.com" does not match any host of the ingress`),
+		},
+		{
+			name:       "probe host with multiple labels below wildcard host",
+			probeHosts: "a.shop.bar.baz",
+			expected:   errors.New(`probe host "a.shop.bar.baz" does not match any host of the ingress`),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ing := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{
+						config.AnnotationProbeHosts: test.probeHosts,
+					},
+				},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{Host: "*.bar.baz"},
+						{Host: "foo.example.com"},
+					},
+				},
+			}
+
+			err := ValidateProbeHosts(ing)
+			if test.expected != nil {
+				assert.EqualError(t, err, test.expected.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProbeEndpointsFor(t *testing.T) {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				config.AnnotationProbeHosts: "shop.bar.baz, foo.example.com,shop.bar.baz",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{Hosts: []string{"*.bar.baz"}},
+			},
+			Rules: []networkingv1.IngressRule{
+				{Host: "*.bar.baz"},
+				{Host: "foo.example.com"},
+			},
+		},
+	}
+
+	expected := []Endpoint{
+		{Host: "shop.bar.baz", TLS: true},
+		{Host: "foo.example.com"},
+	}
+
+	assert.Equal(t, expected, ProbeEndpointsFor(ing))
+}
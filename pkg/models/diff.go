@@ -0,0 +1,103 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+)
+
+const redactedValue = "<redacted>"
+
+// FieldDiff describes a configuration field of a monitor whose current value
+// differs from the desired value.
+type FieldDiff struct {
+	// Field is the name of the field.
+	Field string
+
+	// Current is the value of the field in the existing monitor.
+	Current interface{}
+
+	// Desired is the value of the field in the monitor built from the
+	// ingress.
+	Desired interface{}
+}
+
+// String implements fmt.Stringer.
+func (d FieldDiff) String() string {
+	return fmt.Sprintf("%s: %+v -> %+v", d.Field, d.Current, d.Desired)
+}
+
+// DiffFields compares the exported fields of current and desired, which must
+// be structs or pointers to structs of the same type, and returns a FieldDiff
+// for every field that differs. Nil and empty slices and maps are considered
+// equal. The fields named in redacted are compared, but their values are not
+// included in the result.
+func DiffFields(current, desired interface{}, redacted ...string) []FieldDiff {
+	currentValue := reflect.Indirect(reflect.ValueOf(current))
+	desiredValue := reflect.Indirect(reflect.ValueOf(desired))
+
+	var diffs []FieldDiff
+
+	for i := 0; i < currentValue.NumField(); i++ {
+		field := currentValue.Type().Field(i)
+		if field.PkgPath != "" {
+			// Unexported field.
+			continue
+		}
+
+		a, b := currentValue.Field(i), desiredValue.Field(i)
+		if isEmptyCollection(a) && isEmptyCollection(b) {
+			continue
+		}
+
+		if reflect.DeepEqual(a.Interface(), b.Interface()) {
+			continue
+		}
+
+		diff := FieldDiff{
+			Field:   field.Name,
+			Current: indirectInterface(a),
+			Desired: indirectInterface(b),
+		}
+
+		if contains(redacted, field.Name) {
+			diff.Current, diff.Desired = redactedValue, redactedValue
+		}
+
+		diffs = append(diffs, diff)
+	}
+
+	return diffs
+}
+
+// indirectInterface returns the value v points to if v is a pointer, so that
+// the values of pointer fields are printed instead of their addresses.
+func indirectInterface(v reflect.Value) interface{} {
+	if v.Kind() != reflect.Ptr {
+		return v.Interface()
+	}
+
+	if v.IsNil() {
+		return nil
+	}
+
+	return v.Elem().Interface()
+}
+
+func isEmptyCollection(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	default:
+		return false
+	}
+}
+
+func contains(s []string, e string) bool {
+	for _, v := range s {
+		if v == e {
+			return true
+		}
+	}
+
+	return false
+}
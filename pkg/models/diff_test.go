@@ -0,0 +1,76 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type diffTestValue struct {
+	Value string
+}
+
+type diffTestConfig struct {
+	Name     string
+	Password string
+	Timeout  int
+	Groups   []string
+	Headers  map[string]string
+	Keyword  *diffTestValue
+	internal string
+}
+
+func TestDiffFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		current  *diffTestConfig
+		desired  *diffTestConfig
+		expected []FieldDiff
+	}{
+		{
+			name:    "equal",
+			current: &diffTestConfig{Name: "foo", Timeout: 10, Groups: []string{"a"}, Keyword: &diffTestValue{Value: "ok"}},
+			desired: &diffTestConfig{Name: "foo", Timeout: 10, Groups: []string{"a"}, Keyword: &diffTestValue{Value: "ok"}},
+		},
+		{
+			name:    "nil and empty collections are equal",
+			current: &diffTestConfig{Groups: nil, Headers: map[string]string{}},
+			desired: &diffTestConfig{Groups: []string{}, Headers: nil},
+		},
+		{
+			name:    "unexported fields are ignored",
+			current: &diffTestConfig{internal: "foo"},
+			desired: &diffTestConfig{internal: "bar"},
+		},
+		{
+			name:    "changed fields",
+			current: &diffTestConfig{Name: "foo", Timeout: 10, Groups: []string{"a"}},
+			desired: &diffTestConfig{Name: "foo", Timeout: 30, Groups: []string{"a", "b"}, Keyword: &diffTestValue{Value: "ok"}},
+			expected: []FieldDiff{
+				{Field: "Timeout", Current: 10, Desired: 30},
+				{Field: "Groups", Current: []string{"a"}, Desired: []string{"a", "b"}},
+				{Field: "Keyword", Current: nil, Desired: diffTestValue{Value: "ok"}},
+			},
+		},
+		{
+			name:    "redacted fields",
+			current: &diffTestConfig{Password: "secret"},
+			desired: &diffTestConfig{Password: "new-secret"},
+			expected: []FieldDiff{
+				{Field: "Password", Current: "<redacted>", Desired: "<redacted>"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, DiffFields(test.current, test.desired, "Password"))
+		})
+	}
+}
+
+func TestFieldDiff_String(t *testing.T) {
+	diff := FieldDiff{Field: "Keyword", Current: nil, Desired: diffTestValue{Value: "ok"}}
+
+	assert.Equal(t, "Keyword: <nil> -> {Value:ok}", diff.String())
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"net/url"
 
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
 )
@@ -10,6 +11,14 @@ import (
 // be found.
 var ErrMonitorNotFound = errors.New("monitor not found")
 
+// ErrMonitorNotOwned is returned if a monitor exists but is not owned by the
+// controller instance and thus must not be modified.
+var ErrMonitorNotOwned = errors.New("monitor is not owned by this controller instance")
+
+// ErrDiffNotSupported is returned by providers which are not able to detect
+// configuration drift of a monitor.
+var ErrDiffNotSupported = errors.New("provider does not support drift detection")
+
 // Monitor is a container for a website monitor.
 type Monitor struct {
 	// ID is the provider specific ID of a monitor.
@@ -25,4 +34,77 @@ type Monitor struct {
 	// These can be used by providers to set custom provider specific
 	// configuration.
 	Annotations config.Annotations
+
+	// Owner is the ingress the monitor belongs to. It is nil for monitors
+	// which are not managed by the controller.
+	Owner *Owner
+
+	// Config is the provider specific effective configuration of the
+	// monitor. It is only set on monitors returned by Get of providers that
+	// support drift detection and is used to compare the existing monitor
+	// with the desired one.
+	Config interface{}
+}
+
+// Owner identifies the controller instance and the ingress or other resource
+// a monitor was created for. Monitors without owner were not created by the
+// controller and must never be modified unless they are adopted explicitly.
+type Owner struct {
+	// ClusterID identifies the controller instance, see the --cluster-id
+	// flag.
+	ClusterID string
+
+	// Kind is the kind of the resource, e.g. HTTPRoute. It is empty for
+	// ingresses, so that owners of monitors created before other kinds were
+	// supported stay valid.
+	Kind string
+
+	// Namespace is the namespace of the ingress.
+	Namespace string
+
+	// Name is the name of the ingress.
+	Name string
+
+	// UID is the UID of the ingress. It is informational only as it changes
+	// if an ingress is recreated.
+	UID string
+}
+
+// String implements fmt.Stringer. The result is URL query encoded, e.g.
+// "cluster=prod&name=foo&namespace=kube-system&uid=1234".
+func (o Owner) String() string {
+	v := url.Values{}
+	v.Set("cluster", o.ClusterID)
+	v.Set("namespace", o.Namespace)
+	v.Set("name", o.Name)
+	v.Set("uid", o.UID)
+
+	if o.Kind != "" {
+		v.Set("kind", o.Kind)
+	}
+
+	return v.Encode()
+}
+
+// ParseOwner parses an owner from its string representation. Returns nil if
+// s is not a valid owner.
+func ParseOwner(s string) *Owner {
+	v, err := url.ParseQuery(s)
+	if err != nil {
+		return nil
+	}
+
+	owner := &Owner{
+		ClusterID: v.Get("cluster"),
+		Kind:      v.Get("kind"),
+		Namespace: v.Get("namespace"),
+		Name:      v.Get("name"),
+		UID:       v.Get("uid"),
+	}
+
+	if owner.Namespace == "" || owner.Name == "" {
+		return nil
+	}
+
+	return owner
 }
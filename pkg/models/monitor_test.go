@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwner(t *testing.T) {
+	tests := []struct {
+		name     string
+		owner    Owner
+		expected string
+	}{
+		{
+			name:     "ingress",
+			owner:    Owner{ClusterID: "prod", Namespace: "kube-system", Name: "foo", UID: "1234"},
+			expected: "cluster=prod&name=foo&namespace=kube-system&uid=1234",
+		},
+		{
+			name:     "other kind",
+			owner:    Owner{ClusterID: "prod", Kind: "HTTPRoute", Namespace: "kube-system", Name: "foo", UID: "1234"},
+			expected: "cluster=prod&kind=HTTPRoute&name=foo&namespace=kube-system&uid=1234",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.owner.String())
+			assert.Equal(t, &test.owner, ParseOwner(test.expected))
+		})
+	}
+}
+
+func TestParseOwner_Invalid(t *testing.T) {
+	assert.Nil(t, ParseOwner(""))
+	assert.Nil(t, ParseOwner("cluster=prod&name=foo"))
+	assert.Nil(t, ParseOwner("%zz"))
+}
@@ -1,16 +1,18 @@
 package monitor
 
 import (
+	"context"
 	"strings"
 
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 )
 
 const nginxWhitelistSourceRangeAnnotation = "nginx.ingress.kubernetes.io/whitelist-source-range"
 
 // AnnotateIngress implements Service.
-func (s *service) AnnotateIngress(ingress *networkingv1.Ingress) (bool, error) {
+func (s *service) AnnotateIngress(ctx context.Context, ingress *networkingv1.Ingress) (bool, error) {
 	log := log.WithValues("namespace", ingress.Namespace, "name", ingress.Name)
 
 	if !shouldPatchSourceRangeWhitelist(ingress) {
@@ -18,14 +20,19 @@ func (s *service) AnnotateIngress(ingress *networkingv1.Ingress) (bool, error) {
 		return false, nil
 	}
 
-	providerSourceRanges, err := s.GetProviderIPSourceRanges(ingress)
+	// If some providers fail, the source ranges of the healthy ones are
+	// still added, so that a single failing provider does not block the
+	// checks of all others. The error is returned nonetheless to allow the
+	// caller to retry.
+	providerSourceRanges, err := s.GetProviderIPSourceRanges(ctx, ingress)
 	if err != nil {
-		return false, err
+		log.Error(err, "failed to get provider source ranges")
+		s.recordEvent(ingress, corev1.EventTypeWarning, ReasonSourceRangesFailed, "Failed to get provider source ranges: %v", err)
 	}
 
 	if len(providerSourceRanges) == 0 {
 		log.V(1).Info("no provider source ranges available for ingress")
-		return false, nil
+		return false, err
 	}
 
 	sourceRanges := strings.Split(ingress.Annotations[nginxWhitelistSourceRangeAnnotation], ",")
@@ -33,14 +40,14 @@ func (s *service) AnnotateIngress(ingress *networkingv1.Ingress) (bool, error) {
 	sourceRanges, updated := mergeProviderSourceRanges(sourceRanges, providerSourceRanges)
 	if !updated {
 		log.V(1).Info("no source range update needed for ingress")
-		return false, nil
+		return false, err
 	}
 
 	log.Info("patching ingress")
 
 	ingress.Annotations[nginxWhitelistSourceRangeAnnotation] = strings.Join(sourceRanges, ",")
 
-	return true, nil
+	return true, err
 }
 
 // shouldPatchSourceRangeWhitelist returns true if the source range whitelist
@@ -1,6 +1,7 @@
 package monitor
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -88,9 +89,38 @@ func TestService_AnnotateIngress(t *testing.T) {
 				},
 			},
 			setup: func(p *fake.Provider) {
-				p.On("GetIPSourceRanges", mock.Anything).Return(nil, errors.New("whoops"))
+				p.On("GetIPSourceRanges", mock.Anything, mock.Anything).Return(nil, errors.New("whoops"))
 			},
 			expectedErr: errors.New("whoops"),
+			validate: func(t *testing.T, ingress *networkingv1.Ingress, _ *fake.Provider) {
+				assert.Equal(t, "5.6.7.8/32,1.2.3.4/32,9.10.11.12/32", ingress.Annotations[nginxWhitelistSourceRangeAnnotation])
+			},
+		},
+		{
+			name: `source ranges of healthy providers are added if other providers fail`,
+			ingress: &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "kube-system",
+					Annotations: map[string]string{
+						config.AnnotationEnabled:            "true",
+						nginxWhitelistSourceRangeAnnotation: "1.2.3.4/32",
+					},
+				},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{Host: "foo.bar.baz"},
+					},
+				},
+			},
+			setup: func(p *fake.Provider) {
+				p.On("GetIPSourceRanges", mock.Anything, mock.Anything).Return([]string{"5.6.7.8/32"}, errors.New("provider b: whoops"))
+			},
+			expected:    true,
+			expectedErr: errors.New("provider b: whoops"),
+			validate: func(t *testing.T, ingress *networkingv1.Ingress, _ *fake.Provider) {
+				assert.Equal(t, "1.2.3.4/32,5.6.7.8/32", ingress.Annotations[nginxWhitelistSourceRangeAnnotation])
+			},
 		},
 		{
 			name: `empty provider source ranges do not cause the object to be patched`,
@@ -110,7 +140,7 @@ func TestService_AnnotateIngress(t *testing.T) {
 				},
 			},
 			setup: func(p *fake.Provider) {
-				p.On("GetIPSourceRanges", mock.Anything).Return(nil, nil)
+				p.On("GetIPSourceRanges", mock.Anything, mock.Anything).Return(nil, nil)
 			},
 			validate: func(t *testing.T, ingress *networkingv1.Ingress, _ *fake.Provider) {
 				assert.Equal(t, "5.6.7.8/32,1.2.3.4/32,9.10.11.12/32", ingress.Annotations[nginxWhitelistSourceRangeAnnotation])
@@ -134,7 +164,7 @@ func TestService_AnnotateIngress(t *testing.T) {
 				},
 			},
 			setup: func(p *fake.Provider) {
-				p.On("GetIPSourceRanges", mock.Anything).Return([]string{"5.6.7.8/32"}, nil)
+				p.On("GetIPSourceRanges", mock.Anything, mock.Anything).Return([]string{"5.6.7.8/32"}, nil)
 			},
 			expected: true,
 			validate: func(t *testing.T, ingress *networkingv1.Ingress, _ *fake.Provider) {
@@ -159,7 +189,7 @@ func TestService_AnnotateIngress(t *testing.T) {
 				},
 			},
 			setup: func(p *fake.Provider) {
-				p.On("GetIPSourceRanges", mock.Anything).Return([]string{"5.6.7.8/32", "1.2.3.4/32"}, nil)
+				p.On("GetIPSourceRanges", mock.Anything, mock.Anything).Return([]string{"5.6.7.8/32", "1.2.3.4/32"}, nil)
 			},
 			expected: true,
 			validate: func(t *testing.T, ingress *networkingv1.Ingress, _ *fake.Provider) {
@@ -184,7 +214,7 @@ func TestService_AnnotateIngress(t *testing.T) {
 				},
 			},
 			setup: func(p *fake.Provider) {
-				p.On("GetIPSourceRanges", mock.Anything).Return([]string{"5.6.7.8/32"}, nil)
+				p.On("GetIPSourceRanges", mock.Anything, mock.Anything).Return([]string{"5.6.7.8/32"}, nil)
 			},
 			expected: false,
 			validate: func(t *testing.T, ingress *networkingv1.Ingress, _ *fake.Provider) {
@@ -203,15 +233,16 @@ func TestService_AnnotateIngress(t *testing.T) {
 
 			ingress := test.ingress
 
-			annotated, err := svc.AnnotateIngress(ingress)
+			annotated, err := svc.AnnotateIngress(context.Background(), ingress)
 			if test.expectedErr != nil {
 				require.Error(t, err)
 				assert.Equal(t, test.expectedErr, err)
 			} else {
 				require.NoError(t, err)
-				assert.Equal(t, test.expected, annotated)
 			}
 
+			assert.Equal(t, test.expected, annotated)
+
 			if test.validate != nil {
 				test.validate(t, ingress, provider)
 			}
@@ -1,28 +1,32 @@
 package fake
 
 import (
+	"context"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
 	"github.com/stretchr/testify/mock"
 	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type Service struct {
 	mock.Mock
 }
 
-func (s *Service) EnsureMonitor(ingress *networkingv1.Ingress) error {
-	args := s.Called(ingress)
+func (s *Service) EnsureMonitor(ctx context.Context, obj client.Object) (updated bool, err error) {
+	args := s.Called(ctx, obj)
 
-	return args.Error(0)
+	return args.Bool(0), args.Error(1)
 }
 
-func (s *Service) DeleteMonitor(ingress *networkingv1.Ingress) error {
-	args := s.Called(ingress)
+func (s *Service) DeleteMonitor(ctx context.Context, obj client.Object) error {
+	args := s.Called(ctx, obj)
 
 	return args.Error(0)
 }
 
-func (s *Service) GetProviderIPSourceRanges(ingress *networkingv1.Ingress) ([]string, error) {
-	args := s.Called(ingress)
+func (s *Service) GetProviderIPSourceRanges(ctx context.Context, ingress *networkingv1.Ingress) ([]string, error) {
+	args := s.Called(ctx, ingress)
 
 	var ips []string
 	if arg, ok := args.Get(0).([]string); ok {
@@ -32,8 +36,20 @@ func (s *Service) GetProviderIPSourceRanges(ingress *networkingv1.Ingress) ([]st
 	return ips, args.Error(1)
 }
 
-func (s *Service) AnnotateIngress(ingress *networkingv1.Ingress) (updated bool, err error) {
-	args := s.Called(ingress)
+func (s *Service) AnnotateIngress(ctx context.Context, ingress *networkingv1.Ingress) (updated bool, err error) {
+	args := s.Called(ctx, ingress)
 
 	return args.Bool(0), args.Error(1)
 }
+
+func (s *Service) DeleteOrphanedMonitors(ctx context.Context, isOrphaned func(owner models.Owner) (bool, error)) error {
+	args := s.Called(ctx, isOrphaned)
+
+	return args.Error(0)
+}
+
+func (s *Service) CheckProviders(ctx context.Context) error {
+	args := s.Called(ctx)
+
+	return args.Error(0)
+}
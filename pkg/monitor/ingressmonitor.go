@@ -0,0 +1,199 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/ingress"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// validateIngressMonitor checks that the spec of im either references an
+// ingress or contains a valid http or https URL.
+func validateIngressMonitor(im *v1alpha1.IngressMonitor) error {
+	spec := im.Spec
+
+	if (spec.IngressRef == nil) == (spec.URL == "") {
+		return errors.New("exactly one of spec.ingressRef and spec.url must be set")
+	}
+
+	if spec.IngressRef != nil {
+		if spec.IngressRef.Name == "" {
+			return errors.New("spec.ingressRef.name must not be empty")
+		}
+
+		return nil
+	}
+
+	_, err := urlEndpoint(spec.URL)
+	return err
+}
+
+// urlEndpoint returns the endpoint for the raw URL rawURL.
+func urlEndpoint(rawURL string) (ingress.Endpoint, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ingress.Endpoint{}, errors.Wrapf(err, "invalid spec.url %q", rawURL)
+	}
+
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ingress.Endpoint{}, errors.Errorf("spec.url %q must be an absolute http or https URL", rawURL)
+	}
+
+	return ingress.Endpoint{Host: u.Hostname(), TLS: u.Scheme == "https", URL: rawURL}, nil
+}
+
+// ingressRefEndpoint looks up the ingress referenced by the validated im and
+// returns the endpoint of its first host. The URL of the endpoint is built
+// from the annotations of the ingress.
+func (s *service) ingressRefEndpoint(ctx context.Context, im *v1alpha1.IngressMonitor) (ingress.Endpoint, error) {
+	ref := im.Spec.IngressRef
+	key := types.NamespacedName{Namespace: im.Namespace, Name: ref.Name}
+
+	ing := &networkingv1.Ingress{}
+
+	err := s.client.Get(ctx, key, ing)
+	if apierrors.IsNotFound(err) {
+		return ingress.Endpoint{}, errors.Errorf("referenced ingress %s not found", key)
+	} else if err != nil {
+		return ingress.Endpoint{}, errors.Wrapf(err, "failed to get referenced ingress %s", key)
+	}
+
+	err = ingress.Validate(ing)
+	if err != nil {
+		return ingress.Endpoint{}, errors.Wrapf(err, "referenced ingress %s is not supported", key)
+	}
+
+	endpoint := ingress.EndpointFor(ing)
+	endpoint.Path = ref.Path
+
+	endpoint.URL, err = ingress.BuildURL(endpoint, ing.Annotations)
+	if err != nil {
+		return ingress.Endpoint{}, err
+	}
+
+	// An IngressMonitor defines a single monitor, so the path must not end
+	// up in the monitor name.
+	endpoint.Path = ""
+
+	return endpoint, nil
+}
+
+// annotationsOf returns the annotations that configure the monitors of obj.
+// For IngressMonitors, the typed fields of the spec are converted to the
+// equivalent annotations, which take precedence over the annotations of the
+// IngressMonitor itself. The returned map must not be modified.
+func annotationsOf(obj client.Object) map[string]string {
+	im, ok := obj.(*v1alpha1.IngressMonitor)
+	if !ok {
+		return obj.GetAnnotations()
+	}
+
+	annotations := make(map[string]string, len(im.Annotations))
+	for name, value := range im.Annotations {
+		annotations[name] = value
+	}
+
+	if len(im.Spec.Providers) > 0 {
+		annotations[config.AnnotationProvider] = strings.Join(im.Spec.Providers, ",")
+	}
+
+	if im.Spec.Site24x7 != nil {
+		addSite24x7Annotations(annotations, im.Spec.Site24x7)
+	}
+
+	return annotations
+}
+
+// addSite24x7Annotations adds the annotations equivalent to the fields of
+// spec which are set.
+func addSite24x7Annotations(annotations map[string]string, spec *v1alpha1.Site24x7Spec) {
+	setString(annotations, config.AnnotationSite24x7CheckFrequency, spec.CheckFrequency)
+	setString(annotations, config.AnnotationSite24x7HTTPMethod, spec.HTTPMethod)
+	setString(annotations, config.AnnotationSite24x7UserAgent, spec.UserAgent)
+	setString(annotations, config.AnnotationSite24x7AuthUser, spec.AuthUser)
+	setString(annotations, config.AnnotationSite24x7LocationProfileID, spec.LocationProfileID)
+	setString(annotations, config.AnnotationSite24x7NotificationProfileID, spec.NotificationProfileID)
+	setString(annotations, config.AnnotationSite24x7ThresholdProfileID, spec.ThresholdProfileID)
+	setString(annotations, config.AnnotationSite24x7MonitorGroupIDs, strings.Join(spec.MonitorGroupIDs, ","))
+	setString(annotations, config.AnnotationSite24x7UserGroupIDs, strings.Join(spec.UserGroupIDs, ","))
+	setInt(annotations, config.AnnotationSite24x7Timeout, spec.Timeout)
+
+	if spec.MatchCase != nil {
+		annotations[config.AnnotationSite24x7MatchCase] = strconv.FormatBool(*spec.MatchCase)
+	}
+
+	if spec.UseNameServer != nil {
+		annotations[config.AnnotationSite24x7UseNameServer] = strconv.FormatBool(*spec.UseNameServer)
+	}
+
+	if len(spec.CustomHeaders) > 0 {
+		// The json tags of Header match the format of the annotation.
+		buf, _ := json.Marshal(spec.CustomHeaders)
+		annotations[config.AnnotationSite24x7CustomHeaders] = string(buf)
+	}
+
+	if len(spec.Actions) > 0 {
+		type actionRef struct {
+			ActionID  string `json:"action_id"`
+			AlertType int    `json:"alert_type"`
+		}
+
+		actions := make([]actionRef, len(spec.Actions))
+		for i, action := range spec.Actions {
+			actions[i] = actionRef{ActionID: action.ActionID, AlertType: action.AlertType}
+		}
+
+		buf, _ := json.Marshal(actions)
+		annotations[config.AnnotationSite24x7Actions] = string(buf)
+	}
+}
+
+// addSite24x7AuthPass adds the basic auth password referenced by spec to
+// annotations unless the password is already set. The secret is looked up in
+// namespace.
+func (s *service) addSite24x7AuthPass(ctx context.Context, annotations map[string]string, namespace string, spec *v1alpha1.Site24x7Spec) error {
+	ref := spec.AuthPasswordSecretRef
+	if ref == nil {
+		return nil
+	}
+
+	if _, ok := annotations[config.AnnotationSite24x7AuthPass]; ok {
+		return nil
+	}
+
+	optional := ref.Optional != nil && *ref.Optional
+	key := types.NamespacedName{Namespace: namespace, Name: ref.Name}
+
+	secret := &corev1.Secret{}
+
+	err := s.client.Get(ctx, key, secret)
+	if apierrors.IsNotFound(err) && optional {
+		return nil
+	} else if apierrors.IsNotFound(err) {
+		return errors.Errorf("secret %s not found", key)
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to get secret %s", key)
+	}
+
+	value, ok := secret.Data[ref.Key]
+	if !ok && optional {
+		return nil
+	} else if !ok {
+		return errors.Errorf("secret %s does not contain key %q", key, ref.Key)
+	}
+
+	annotations[config.AnnotationSite24x7AuthPass] = string(value)
+
+	return nil
+}
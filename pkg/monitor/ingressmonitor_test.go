@@ -0,0 +1,314 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/fake"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestService_EnsureMonitor_IngressMonitor(t *testing.T) {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "kube-system",
+			Annotations: map[string]string{
+				config.AnnotationPathOverride: "/health",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{
+				{Hosts: []string{"foo.bar.baz"}},
+			},
+			Rules: []networkingv1.IngressRule{
+				{Host: "foo.bar.baz"},
+			},
+		},
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "auth",
+			Namespace: "kube-system",
+		},
+		Data: map[string][]byte{"password": []byte("s3cr3t")},
+	}
+
+	newIngressMonitor := func(spec v1alpha1.IngressMonitorSpec) *v1alpha1.IngressMonitor {
+		return &v1alpha1.IngressMonitor{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bar",
+				Namespace: "kube-system",
+				UID:       "1234",
+			},
+			Spec: spec,
+		}
+	}
+
+	timeout := 10
+
+	tests := []struct {
+		name                string
+		ingressMonitor      *v1alpha1.IngressMonitor
+		expectedURL         string
+		expectedAnnotations config.Annotations
+		expectedError       string
+	}{
+		{
+			name: "raw url",
+			ingressMonitor: newIngressMonitor(v1alpha1.IngressMonitorSpec{
+				URL: "https://example.com:8443/status?full=1",
+			}),
+			expectedURL:         "https://example.com:8443/status?full=1",
+			expectedAnnotations: config.Annotations{},
+		},
+		{
+			name: "ingress reference",
+			ingressMonitor: newIngressMonitor(v1alpha1.IngressMonitorSpec{
+				IngressRef: &v1alpha1.IngressReference{Name: "foo"},
+			}),
+			expectedURL:         "https://foo.bar.baz/health",
+			expectedAnnotations: config.Annotations{},
+		},
+		{
+			name: "ingress reference with path",
+			ingressMonitor: newIngressMonitor(v1alpha1.IngressMonitorSpec{
+				IngressRef: &v1alpha1.IngressReference{Name: "foo", Path: "/api"},
+			}),
+			expectedURL:         "https://foo.bar.baz/api",
+			expectedAnnotations: config.Annotations{},
+		},
+		{
+			name: "typed site24x7 fields are converted to annotations",
+			ingressMonitor: newIngressMonitor(v1alpha1.IngressMonitorSpec{
+				URL: "https://example.com",
+				Site24x7: &v1alpha1.Site24x7Spec{
+					CheckFrequency:  "5",
+					Timeout:         &timeout,
+					MonitorGroupIDs: []string{"123", "456"},
+					CustomHeaders:   []v1alpha1.Header{{Name: "Accept", Value: "application/json"}},
+					Actions:         []v1alpha1.Site24x7Action{{ActionID: "789", AlertType: 1}},
+				},
+			}),
+			expectedURL: "https://example.com",
+			expectedAnnotations: config.Annotations{
+				config.AnnotationSite24x7CheckFrequency:  "5",
+				config.AnnotationSite24x7Timeout:         "10",
+				config.AnnotationSite24x7MonitorGroupIDs: "123,456",
+				config.AnnotationSite24x7CustomHeaders:   `[{"name":"Accept","value":"application/json"}]`,
+				config.AnnotationSite24x7Actions:         `[{"action_id":"789","alert_type":1}]`,
+			},
+		},
+		{
+			name: "basic auth password is read from secret",
+			ingressMonitor: newIngressMonitor(v1alpha1.IngressMonitorSpec{
+				URL: "https://example.com",
+				Site24x7: &v1alpha1.Site24x7Spec{
+					AuthUser:              "monitor",
+					AuthPasswordSecretRef: newSecretKeySelector("auth", "password", false),
+				},
+			}),
+			expectedURL: "https://example.com",
+			expectedAnnotations: config.Annotations{
+				config.AnnotationSite24x7AuthUser: "monitor",
+				config.AnnotationSite24x7AuthPass: "s3cr3t",
+			},
+		},
+		{
+			name: "optional secret key may be missing",
+			ingressMonitor: newIngressMonitor(v1alpha1.IngressMonitorSpec{
+				URL: "https://example.com",
+				Site24x7: &v1alpha1.Site24x7Spec{
+					AuthPasswordSecretRef: newSecretKeySelector("auth", "missing", true),
+				},
+			}),
+			expectedURL:         "https://example.com",
+			expectedAnnotations: config.Annotations{},
+		},
+		{
+			name: "missing secret",
+			ingressMonitor: newIngressMonitor(v1alpha1.IngressMonitorSpec{
+				URL: "https://example.com",
+				Site24x7: &v1alpha1.Site24x7Spec{
+					AuthPasswordSecretRef: newSecretKeySelector("missing", "password", false),
+				},
+			}),
+			expectedError: "secret kube-system/missing not found",
+		},
+		{
+			name: "missing secret key",
+			ingressMonitor: newIngressMonitor(v1alpha1.IngressMonitorSpec{
+				URL: "https://example.com",
+				Site24x7: &v1alpha1.Site24x7Spec{
+					AuthPasswordSecretRef: newSecretKeySelector("auth", "missing", false),
+				},
+			}),
+			expectedError: `secret kube-system/auth does not contain key "missing"`,
+		},
+		{
+			name:           "missing url and ingress reference",
+			ingressMonitor: newIngressMonitor(v1alpha1.IngressMonitorSpec{}),
+			expectedError:  "exactly one of spec.ingressRef and spec.url must be set",
+		},
+		{
+			name: "relative url",
+			ingressMonitor: newIngressMonitor(v1alpha1.IngressMonitorSpec{
+				URL: "/health",
+			}),
+			expectedError: `spec.url "/health" must be an absolute http or https URL`,
+		},
+		{
+			name: "missing ingress",
+			ingressMonitor: newIngressMonitor(v1alpha1.IngressMonitorSpec{
+				IngressRef: &v1alpha1.IngressReference{Name: "missing"},
+			}),
+			expectedError: "referenced ingress kube-system/missing not found",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc, p := newTestService(t, &config.Options{ClusterID: "prod"})
+			svc.client = fakeclient.NewClientBuilder().WithObjects(ing, secret).Build()
+
+			p.On("Get", mock.Anything, "kube-system-bar").Return(nil, models.ErrMonitorNotFound)
+			p.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+			_, err := svc.EnsureMonitor(context.Background(), test.ingressMonitor)
+
+			status := ParseStatus(test.ingressMonitor)
+			require.NotNil(t, status)
+			assert.Equal(t, test.expectedError, status.LastError)
+
+			if test.expectedURL == "" {
+				p.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				return
+			}
+
+			require.NoError(t, err)
+
+			p.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(monitor *models.Monitor) bool {
+				return monitor.Name == "kube-system-bar" &&
+					monitor.URL == test.expectedURL &&
+					assert.ObjectsAreEqual(test.expectedAnnotations, monitor.Annotations) &&
+					*monitor.Owner == models.Owner{ClusterID: "prod", Kind: "IngressMonitor", Namespace: "kube-system", Name: "bar", UID: "1234"}
+			}))
+		})
+	}
+}
+
+func TestAnnotationsOf_IngressMonitorProviders(t *testing.T) {
+	im := &v1alpha1.IngressMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				config.AnnotationProvider:                  "null",
+				config.AnnotationSite24x7CheckFrequency:    "1",
+				config.AnnotationSite24x7LocationProfileID: "1",
+			},
+		},
+		Spec: v1alpha1.IngressMonitorSpec{
+			Providers: []string{"site24x7", "pingdom"},
+			Site24x7:  &v1alpha1.Site24x7Spec{CheckFrequency: "5"},
+		},
+	}
+
+	expected := map[string]string{
+		config.AnnotationProvider:                  "site24x7,pingdom",
+		config.AnnotationSite24x7CheckFrequency:    "5",
+		config.AnnotationSite24x7LocationProfileID: "1",
+	}
+
+	assert.Equal(t, expected, annotationsOf(im))
+	assert.Equal(t, "null", im.Annotations[config.AnnotationProvider])
+}
+
+func TestService_DeleteMonitor_IngressMonitorCollision(t *testing.T) {
+	namer, err := NewNamer("{{.Namespace}}-{{.IngressName}}")
+	require.NoError(t, err)
+
+	options := &config.Options{ClusterID: "prod"}
+
+	p := &fake.Provider{}
+	p.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{
+		ID:    "1",
+		Name:  "kube-system-foo",
+		Owner: &models.Owner{ClusterID: "prod", Kind: "IngressMonitor", Namespace: "kube-system", Name: "foo"},
+	}, nil)
+
+	svc := newService(map[string]provider.Interface{"fake": provider.NewGuard(p, options.ClusterID, false)}, []string{"fake"}, namer, options)
+
+	// A metadata-only stub of an ingress without monitor must not delete
+	// the monitor of the IngressMonitor with the same name.
+	err = svc.DeleteMonitor(context.Background(), &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "kube-system"},
+	})
+	require.NoError(t, err)
+
+	p.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+
+	p.On("Delete", mock.Anything, "kube-system-foo").Return(nil)
+
+	err = svc.DeleteMonitor(context.Background(), &v1alpha1.IngressMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "kube-system"},
+	})
+	require.NoError(t, err)
+
+	p.AssertCalled(t, "Delete", mock.Anything, "kube-system-foo")
+}
+
+func newSecretKeySelector(name, key string, optional bool) *corev1.SecretKeySelector {
+	return &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: name},
+		Key:                  key,
+		Optional:             &optional,
+	}
+}
+
+func TestService_EnsureMonitor_IngressMonitorRedactsPassword(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "kube-system"},
+		Data:       map[string][]byte{"password": []byte("s3cr3t")},
+	}
+
+	im := &v1alpha1.IngressMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "kube-system", UID: "1234"},
+		Spec: v1alpha1.IngressMonitorSpec{
+			URL: "https://example.com",
+			Site24x7: &v1alpha1.Site24x7Spec{
+				AuthPasswordSecretRef: newSecretKeySelector("auth", "password", false),
+			},
+		},
+	}
+
+	recorder := record.NewFakeRecorder(10)
+
+	svc, p := newTestService(t, &config.Options{})
+	svc.client = fakeclient.NewClientBuilder().WithObjects(secret).Build()
+	svc.recorder = recorder
+
+	p.On("Get", mock.Anything, "kube-system-bar").Return(nil, models.ErrMonitorNotFound)
+	p.On("Create", mock.Anything, mock.Anything).Return(errors.New(`invalid auth_pass "s3cr3t"`))
+
+	_, err := svc.EnsureMonitor(context.Background(), im)
+	require.EqualError(t, err, `invalid auth_pass "[redacted]"`)
+
+	status := ParseStatus(im)
+	require.NotNil(t, status)
+	assert.Equal(t, `invalid auth_pass "[redacted]"`, status.LastError)
+
+	require.Len(t, recorder.Events, 1)
+	assert.NotContains(t, <-recorder.Events, "s3cr3t")
+}
@@ -1,5 +1,6 @@
 // Package metrics provides prometheus metric declarations to collect stats
-// about monitor creations/updates/deletions.
+// about monitor creations/updates/deletions/renames, provider caches, garbage
+// collection and the results of probes performed by the local provider.
 package metrics
 
 import (
@@ -29,6 +30,14 @@ var (
 		Help: "Total number of ingress monitors deleted by monitor",
 	}, []string{"monitor"})
 
+	// MonitorsRenamedTotal is a counter for the total number of successful
+	// ingress monitor rename operations, e.g. after the name template was
+	// changed.
+	MonitorsRenamedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingress_monitor_controller_monitors_renamed_total",
+		Help: "Total number of ingress monitors renamed by monitor",
+	}, []string{"monitor"})
+
 	// IngressValidationErrorsTotal is a counter for the total number of failed
 	// ingress validation events. That is: monitor creation was requested for
 	// an ingress that was not eligible as a target for an ingress monitor. See
@@ -38,6 +47,87 @@ var (
 		Name: "ingress_monitor_controller_ingress_validation_errors_total",
 		Help: "Total number of ingress validation errors by namespace and ingress name",
 	}, []string{"namespace", "name"})
+
+	// ProviderErrorsTotal is a counter for the total number of failed
+	// provider operations when monitors are managed by multiple providers.
+	ProviderErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingress_monitor_controller_provider_errors_total",
+		Help: "Total number of failed provider operations by provider and operation",
+	}, []string{"provider", "operation"})
+
+	// MonitorCacheHitsTotal is a counter for the total number of monitor
+	// lookups that were served from a provider's monitor cache.
+	MonitorCacheHitsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingress_monitor_controller_monitor_cache_hits_total",
+		Help: "Total number of monitor lookups served from the monitor cache by provider",
+	}, []string{"provider"})
+
+	// MonitorCacheMissesTotal is a counter for the total number of monitor
+	// lookups that required a refresh of a provider's monitor cache.
+	MonitorCacheMissesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingress_monitor_controller_monitor_cache_misses_total",
+		Help: "Total number of monitor lookups that required a monitor cache refresh by provider",
+	}, []string{"provider"})
+
+	// MonitorCacheLastRefreshTimestampSeconds is a gauge for the time of the
+	// last successful refresh of a provider's monitor cache. The staleness
+	// of the cache can be computed via time() minus this value.
+	MonitorCacheLastRefreshTimestampSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_monitor_controller_monitor_cache_last_refresh_timestamp_seconds",
+		Help: "Unix timestamp of the last successful monitor cache refresh by provider",
+	}, []string{"provider"})
+
+	// MonitorCacheSize is a gauge for the number of monitors in a provider's
+	// monitor cache.
+	MonitorCacheSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_monitor_controller_monitor_cache_size",
+		Help: "Number of monitors in the monitor cache by provider",
+	}, []string{"provider"})
+
+	// OrphanedMonitors is a gauge for the number of orphaned monitors found
+	// by the last garbage collection run. That is: monitors owned by the
+	// controller whose ingress does not exist or is not enabled anymore.
+	OrphanedMonitors = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_monitor_controller_orphaned_monitors",
+		Help: "Number of orphaned monitors found by the last garbage collection run by provider",
+	}, []string{"provider"})
+
+	// OrphanedMonitorsDeletedTotal is a counter for the total number of
+	// orphaned monitors deleted by garbage collection.
+	OrphanedMonitorsDeletedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "ingress_monitor_controller_orphaned_monitors_deleted_total",
+		Help: "Total number of orphaned monitors deleted by garbage collection by provider",
+	}, []string{"provider"})
+
+	// ProbeUp is a gauge which is 1 if the last probe of a monitor performed
+	// by the local provider succeeded and 0 otherwise.
+	ProbeUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_monitor_controller_probe_up",
+		Help: "Whether the last probe of the monitor succeeded by monitor and url",
+	}, []string{"monitor", "url"})
+
+	// ProbeDurationSeconds is a gauge for the duration of the last probe of a
+	// monitor performed by the local provider.
+	ProbeDurationSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_monitor_controller_probe_duration_seconds",
+		Help: "Duration of the last probe in seconds by monitor and url",
+	}, []string{"monitor", "url"})
+
+	// ProbeStatusCode is a gauge for the HTTP status code returned by the
+	// last probe of a monitor performed by the local provider. It is 0 if no
+	// response was received.
+	ProbeStatusCode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_monitor_controller_probe_status_code",
+		Help: "HTTP status code of the last probe by monitor and url",
+	}, []string{"monitor", "url"})
+
+	// ProbeTLSExpiryTimestampSeconds is a gauge for the expiry of the TLS
+	// certificate observed by the last probe of a monitor performed by the
+	// local provider. It is only set for HTTPS urls.
+	ProbeTLSExpiryTimestampSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "ingress_monitor_controller_probe_tls_expiry_timestamp_seconds",
+		Help: "Unix timestamp of the earliest expiring TLS certificate of the last probe by monitor and url",
+	}, []string{"monitor", "url"})
 )
 
 func init() {
@@ -45,6 +135,18 @@ func init() {
 		MonitorsCreatedTotal,
 		MonitorsUpdatedTotal,
 		MonitorsDeletedTotal,
+		MonitorsRenamedTotal,
 		IngressValidationErrorsTotal,
+		ProviderErrorsTotal,
+		MonitorCacheHitsTotal,
+		MonitorCacheMissesTotal,
+		MonitorCacheLastRefreshTimestampSeconds,
+		MonitorCacheSize,
+		OrphanedMonitors,
+		OrphanedMonitorsDeletedTotal,
+		ProbeUp,
+		ProbeDurationSeconds,
+		ProbeStatusCode,
+		ProbeTLSExpiryTimestampSeconds,
 	)
 }
@@ -4,17 +4,30 @@ import (
 	"bytes"
 	"text/template"
 
-	networkingv1 "k8s.io/api/networking/v1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/ingress"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type templateArgs struct {
+	// IngressName is the name of the ingress or HTTPRoute.
 	IngressName string
 	Namespace   string
+	Kind        string
+
+	// Host is the monitored host. Templates referencing it produce a
+	// separate monitor for every host.
+	Host string
+
+	// Path is the monitored path if path monitoring is enabled for an
+	// ingress, empty otherwise.
+	Path string
 }
 
 // Namer builds names for ingress monitors from a name template.
 type Namer struct {
 	template *template.Template
+	perHost  bool
+	perPath  bool
 }
 
 // NewNamer creates a new *Namer with given name template string. Returns an
@@ -29,21 +42,63 @@ func NewNamer(nameTemplate string) (*Namer, error) {
 		template: tpl,
 	}
 
+	// The template references the host if it renders differently for
+	// different hosts.
+	a, errA := n.execute(templateArgs{IngressName: "name", Namespace: "namespace", Kind: kindIngress, Host: "a.example.com"})
+	b, errB := n.execute(templateArgs{IngressName: "name", Namespace: "namespace", Kind: kindIngress, Host: "b.example.com"})
+	n.perHost = errA == nil && errB == nil && a != b
+
+	a, errA = n.execute(templateArgs{IngressName: "name", Namespace: "namespace", Kind: kindIngress, Path: "/a"})
+	b, errB = n.execute(templateArgs{IngressName: "name", Namespace: "namespace", Kind: kindIngress, Path: "/b"})
+	n.perPath = errA == nil && errB == nil && a != b
+
 	return n, nil
 }
 
-// Name builds a monitor name for given ingress. Returns an error if rendering
-// the name template fails.
-func (n *Namer) Name(ingress *networkingv1.Ingress) (string, error) {
-	var buf bytes.Buffer
+// PerHost returns true if the name template references the .Host field. In
+// this case a separate monitor is created for every host of an ingress or
+// HTTPRoute, otherwise a single monitor is created for its first host.
+func (n *Namer) PerHost() bool {
+	return n.perHost
+}
 
-	err := n.template.Execute(&buf, templateArgs{
-		IngressName: ingress.Name,
-		Namespace:   ingress.Namespace,
+// Name builds a monitor name for given endpoint of an ingress or HTTPRoute.
+// If the endpoint has a path other than "/" and the name template does not
+// reference the .Path field, the path is appended to the name to keep the
+// names of the monitors for different paths distinct. Likewise, the host is
+// appended if an ingress lists multiple probe hosts and the name template
+// does not reference the .Host field. Returns an error if rendering the name
+// template fails.
+func (n *Namer) Name(obj client.Object, endpoint ingress.Endpoint) (string, error) {
+	name, err := n.execute(templateArgs{
+		IngressName: obj.GetName(),
+		Namespace:   obj.GetNamespace(),
+		Kind:        kindOf(obj),
+		Host:        endpoint.Host,
+		Path:        endpoint.Path,
 	})
 	if err != nil {
 		return "", err
 	}
 
+	if !n.perHost && hasMultipleProbeHosts(obj) {
+		name += "-" + endpoint.Host
+	}
+
+	if !n.perPath && endpoint.Path != "" && endpoint.Path != "/" {
+		name += endpoint.Path
+	}
+
+	return name, nil
+}
+
+func (n *Namer) execute(args templateArgs) (string, error) {
+	var buf bytes.Buffer
+
+	err := n.template.Execute(&buf, args)
+	if err != nil {
+		return "", err
+	}
+
 	return buf.String(), nil
 }
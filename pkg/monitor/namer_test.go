@@ -0,0 +1,110 @@
+package monitor
+
+import (
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/ingress"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNamer(t *testing.T) {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "kube-system",
+		},
+	}
+
+	tests := []struct {
+		template        string
+		endpoint        ingress.Endpoint
+		expectedName    string
+		expectedPerHost bool
+	}{
+		{
+			template:     "{{.Namespace}}-{{.IngressName}}",
+			endpoint:     ingress.Endpoint{Host: "foo.bar.baz"},
+			expectedName: "kube-system-foo",
+		},
+		{
+			template:     "{{.Kind}}/{{.IngressName}}",
+			endpoint:     ingress.Endpoint{Host: "foo.bar.baz"},
+			expectedName: "Ingress/foo",
+		},
+		{
+			template:        "{{.IngressName}}-{{.Host}}",
+			endpoint:        ingress.Endpoint{Host: "foo.bar.baz"},
+			expectedName:    "foo-foo.bar.baz",
+			expectedPerHost: true,
+		},
+		{
+			template:     "{{.Namespace}}-{{.IngressName}}",
+			endpoint:     ingress.Endpoint{Host: "foo.bar.baz", Path: "/api"},
+			expectedName: "kube-system-foo/api",
+		},
+		{
+			template:     "{{.Namespace}}-{{.IngressName}}",
+			endpoint:     ingress.Endpoint{Host: "foo.bar.baz", Path: "/"},
+			expectedName: "kube-system-foo",
+		},
+		{
+			template:     "{{.IngressName}}{{.Path}}",
+			endpoint:     ingress.Endpoint{Host: "foo.bar.baz", Path: "/api"},
+			expectedName: "foo/api",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.template, func(t *testing.T) {
+			namer, err := NewNamer(test.template)
+			require.NoError(t, err)
+
+			name, err := namer.Name(ing, test.endpoint)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expectedName, name)
+			assert.Equal(t, test.expectedPerHost, namer.PerHost())
+		})
+	}
+}
+
+func TestNamer_MultipleProbeHosts(t *testing.T) {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "kube-system",
+			Annotations: map[string]string{
+				config.AnnotationProbeHosts: "shop.bar.baz,blog.bar.baz",
+			},
+		},
+	}
+
+	namer, err := NewNamer("{{.Namespace}}-{{.IngressName}}")
+	require.NoError(t, err)
+
+	name, err := namer.Name(ing, ingress.Endpoint{Host: "shop.bar.baz", Path: "/api"})
+	require.NoError(t, err)
+	assert.Equal(t, "kube-system-foo-shop.bar.baz/api", name)
+
+	// Templates referencing the host already produce distinct names.
+	namer, err = NewNamer("{{.IngressName}}-{{.Host}}")
+	require.NoError(t, err)
+
+	name, err = namer.Name(ing, ingress.Endpoint{Host: "shop.bar.baz"})
+	require.NoError(t, err)
+	assert.Equal(t, "foo-shop.bar.baz", name)
+
+	// A single probe host keeps the plain name.
+	ing.Annotations[config.AnnotationProbeHosts] = "shop.bar.baz"
+
+	namer, err = NewNamer("{{.Namespace}}-{{.IngressName}}")
+	require.NoError(t, err)
+
+	name, err = namer.Name(ing, ingress.Endpoint{Host: "shop.bar.baz"})
+	require.NoError(t, err)
+	assert.Equal(t, "kube-system-foo", name)
+}
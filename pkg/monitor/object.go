@@ -0,0 +1,191 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/gateway"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/ingress"
+	"github.com/pkg/errors"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// kindIngress is the kind of ingresses. Typed objects retrieved via the
+// client usually do not have their TypeMeta populated, so it cannot be
+// derived from the object itself.
+const kindIngress = "Ingress"
+
+// kindOf returns the kind of obj, which is either an *networkingv1.Ingress,
+// an *v1alpha1.IngressMonitor or an HTTPRoute as *unstructured.Unstructured.
+func kindOf(obj client.Object) string {
+	switch obj.(type) {
+	case *networkingv1.Ingress:
+		return kindIngress
+	case *v1alpha1.IngressMonitor:
+		return v1alpha1.IngressMonitorKind
+	}
+
+	return obj.GetObjectKind().GroupVersionKind().Kind
+}
+
+// ownerKind returns the kind that is recorded in the owner of monitors
+// created for obj. It is empty for ingresses to stay compatible with owners
+// recorded before other kinds were supported.
+func ownerKind(obj client.Object) string {
+	kind := kindOf(obj)
+	if kind == kindIngress {
+		return ""
+	}
+
+	return kind
+}
+
+// validate checks if obj fulfills all criteria for a monitor. If perHost is
+// true, monitors are created for every host without wildcards, so obj only
+// needs to have one of those. Ingresses with probe hosts are validated
+// against those instead.
+func validate(obj client.Object, perHost bool) error {
+	switch o := obj.(type) {
+	case *networkingv1.Ingress:
+		if hasProbeHosts(o) {
+			return ingress.ValidateProbeHosts(o)
+		}
+
+		if perHost {
+			return ingress.ValidateHosts(o)
+		}
+
+		return ingress.Validate(o)
+	case *unstructured.Unstructured:
+		if !gateway.IsHTTPRoute(o) {
+			break
+		}
+
+		if perHost {
+			return gateway.ValidateHostnames(o)
+		}
+
+		return gateway.Validate(o)
+	case *v1alpha1.IngressMonitor:
+		return validateIngressMonitor(o)
+	}
+
+	return errors.Errorf("unsupported resource kind %q", kindOf(obj))
+}
+
+// endpoints returns the monitored endpoints of the validated obj. The parent
+// Gateways of HTTPRoutes are looked up to decide whether the route is served
+// via https. The ingresses referenced by IngressMonitors are looked up as
+// well.
+func (s *service) endpoints(ctx context.Context, obj client.Object) ([]ingress.Endpoint, error) {
+	if im, ok := obj.(*v1alpha1.IngressMonitor); ok && im.Spec.IngressRef != nil {
+		endpoint, err := s.ingressRefEndpoint(ctx, im)
+		if err != nil {
+			return nil, err
+		}
+
+		return []ingress.Endpoint{endpoint}, nil
+	}
+
+	route, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return s.endpointsFor(obj, nil), nil
+	}
+
+	gateways := make(map[types.NamespacedName]*unstructured.Unstructured)
+
+	for _, ref := range gateway.ParentGateways(route) {
+		gw := gateway.NewGateway()
+
+		err := s.client.Get(ctx, ref.NamespacedName, gw)
+		if apierrors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to get parent gateway %s", ref.NamespacedName)
+		}
+
+		gateways[ref.NamespacedName] = gw
+	}
+
+	return s.endpointsFor(route, gateways), nil
+}
+
+// endpointsFor returns the endpoints of the validated obj using gateways to
+// look up the parents of HTTPRoutes. Unless the name template references the
+// host, only the endpoint of the first host is returned. Ingresses with probe
+// hosts have an endpoint for every probe host. If path monitoring
+// is enabled for an ingress, there is an endpoint for every monitored path.
+func (s *service) endpointsFor(obj client.Object, gateways map[types.NamespacedName]*unstructured.Unstructured) []ingress.Endpoint {
+	switch o := obj.(type) {
+	case *networkingv1.Ingress:
+		var endpoints []ingress.Endpoint
+
+		switch {
+		case hasProbeHosts(o):
+			endpoints = ingress.ProbeEndpointsFor(o)
+		case s.namer.PerHost():
+			endpoints = ingress.EndpointsFor(o)
+		default:
+			endpoints = []ingress.Endpoint{ingress.EndpointFor(o)}
+		}
+
+		return ingress.PathEndpoints(o, endpoints)
+	case *unstructured.Unstructured:
+		if s.namer.PerHost() {
+			return gateway.EndpointsFor(o, gateways)
+		}
+
+		return []ingress.Endpoint{gateway.EndpointFor(o, gateways)}
+	case *v1alpha1.IngressMonitor:
+		// The endpoints of referenced ingresses can only be resolved using
+		// the client, see endpoints.
+		if o.Spec.URL == "" {
+			return nil
+		}
+
+		endpoint, _ := urlEndpoint(o.Spec.URL)
+		return []ingress.Endpoint{endpoint}
+	}
+
+	return nil
+}
+
+// nameEndpoints returns the endpoints that the monitor names of obj are
+// derived from. Unlike endpoints it does not require obj to be valid and
+// does not look up any Gateways or ingresses, so TLS may not be accurate. If
+// obj is not valid, e.g. because it is a metadata-only stub of a deleted
+// object, or its endpoints cannot be resolved without lookups, there are no
+// endpoints if the name template references the host and a single empty
+// endpoint otherwise.
+func (s *service) nameEndpoints(obj client.Object) []ingress.Endpoint {
+	if validate(obj, s.namer.PerHost()) == nil {
+		if endpoints := s.endpointsFor(obj, nil); len(endpoints) > 0 {
+			return endpoints
+		}
+	}
+
+	if s.namer.PerHost() {
+		return nil
+	}
+
+	return []ingress.Endpoint{{}}
+}
+
+// hasProbeHosts returns true if ing has the probe hosts annotation. Its
+// hosts are monitored instead of the hosts of ing.
+func hasProbeHosts(ing *networkingv1.Ingress) bool {
+	_, found := ing.Annotations[config.AnnotationProbeHosts]
+	return found
+}
+
+// hasMultipleProbeHosts returns true if obj is an ingress whose probe hosts
+// annotation lists more than one host.
+func hasMultipleProbeHosts(obj client.Object) bool {
+	ing, ok := obj.(*networkingv1.Ingress)
+	return ok && len(ingress.ProbeHosts(ing)) > 1
+}
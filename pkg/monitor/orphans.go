@@ -0,0 +1,106 @@
+package monitor
+
+import (
+	"context"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/metrics"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider"
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+)
+
+// DeleteOrphanedMonitors implements Service.
+func (s *service) DeleteOrphanedMonitors(ctx context.Context, isOrphaned func(owner models.Owner) (bool, error)) error {
+	var errs []error
+
+	for _, providerName := range s.providerNames {
+		err := s.deleteOrphanedMonitors(ctx, providerName, isOrphaned)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "provider %s", providerName))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// deleteOrphanedMonitors deletes the orphaned monitors of a single provider.
+// A failure to delete one monitor does not prevent the deletion of the
+// others.
+func (s *service) deleteOrphanedMonitors(ctx context.Context, providerName string, isOrphaned func(owner models.Owner) (bool, error)) error {
+	p := s.providers[providerName]
+	dryRun := s.options.GCDryRun || s.options.NoDelete
+
+	monitors, err := s.listMonitors(ctx, p)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	orphans := 0
+
+	for _, monitor := range monitors {
+		if monitor.Owner == nil || monitor.Owner.ClusterID != s.options.ClusterID {
+			// Monitors of other clusters are never touched.
+			continue
+		}
+
+		if !s.options.WatchesNamespace(monitor.Owner.Namespace) {
+			// The monitor may be managed by another controller instance
+			// watching a different namespace. Ingresses of namespaces that
+			// are not watched are also missing from the cache, so they would
+			// be wrongly considered orphaned.
+			continue
+		}
+
+		orphaned, err := isOrphaned(*monitor.Owner)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if !orphaned {
+			continue
+		}
+
+		orphans++
+
+		if dryRun {
+			log.Info("found orphaned monitor, not deleting in dry-run mode", "provider", providerName, "monitor", monitor.Name, "owner", monitor.Owner.String())
+			continue
+		}
+
+		err = s.deleteOrphanedMonitor(ctx, p, monitor.Name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		metrics.OrphanedMonitorsDeletedTotal.WithLabelValues(providerName).Inc()
+		log.Info("orphaned monitor deleted", "provider", providerName, "monitor", monitor.Name, "owner", monitor.Owner.String())
+	}
+
+	metrics.OrphanedMonitors.WithLabelValues(providerName).Set(float64(orphans))
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (s *service) listMonitors(ctx context.Context, p provider.Interface) ([]*models.Monitor, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	return p.List(ctx)
+}
+
+func (s *service) deleteOrphanedMonitor(ctx context.Context, p provider.Interface, name string) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	err := p.Delete(ctx, name)
+	if err == models.ErrMonitorNotFound {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,169 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/metrics"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/fake"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_DeleteOrphanedMonitors(t *testing.T) {
+	monitors := []*models.Monitor{
+		{ID: "1", Name: "kube-system-foo", Owner: &models.Owner{Namespace: "kube-system", Name: "foo"}},
+		{ID: "2", Name: "kube-system-bar", Owner: &models.Owner{Namespace: "kube-system", Name: "bar"}},
+		{ID: "3", Name: "default-baz", Owner: &models.Owner{Namespace: "default", Name: "baz"}},
+		{ID: "4", Name: "unowned"},
+		{ID: "5", Name: "other-cluster", Owner: &models.Owner{ClusterID: "staging", Namespace: "kube-system", Name: "qux"}},
+	}
+
+	// Only kube-system/foo still has an enabled ingress.
+	isOrphaned := func(owner models.Owner) (bool, error) {
+		return owner != models.Owner{Namespace: "kube-system", Name: "foo"}, nil
+	}
+
+	tests := []struct {
+		name            string
+		options         config.Options
+		setup           func(*fake.Provider)
+		isOrphaned      func(models.Owner) (bool, error)
+		expectedErr     string
+		expectedOrphans float64
+		validate        func(*testing.T, *fake.Provider)
+	}{
+		{
+			name: "deletes orphaned monitors",
+			setup: func(p *fake.Provider) {
+				p.On("List", mock.Anything).Return(monitors, nil)
+				p.On("Delete", mock.Anything, "kube-system-bar").Return(nil)
+				p.On("Delete", mock.Anything, "default-baz").Return(models.ErrMonitorNotFound)
+			},
+			isOrphaned:      isOrphaned,
+			expectedOrphans: 2,
+			validate: func(t *testing.T, p *fake.Provider) {
+				p.AssertExpectations(t)
+				p.AssertNotCalled(t, "Delete", mock.Anything, "kube-system-foo")
+				p.AssertNotCalled(t, "Delete", mock.Anything, "unowned")
+				p.AssertNotCalled(t, "Delete", mock.Anything, "other-cluster")
+			},
+		},
+		{
+			name:    "only reports orphaned monitors in dry-run mode",
+			options: config.Options{GCDryRun: true},
+			setup: func(p *fake.Provider) {
+				p.On("List", mock.Anything).Return(monitors, nil)
+			},
+			isOrphaned:      isOrphaned,
+			expectedOrphans: 2,
+			validate: func(t *testing.T, p *fake.Provider) {
+				p.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name:    "only reports orphaned monitors if deletion is disabled",
+			options: config.Options{NoDelete: true},
+			setup: func(p *fake.Provider) {
+				p.On("List", mock.Anything).Return(monitors, nil)
+			},
+			isOrphaned:      isOrphaned,
+			expectedOrphans: 2,
+			validate: func(t *testing.T, p *fake.Provider) {
+				p.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name:    "ignores monitors of other namespaces if namespace is set",
+			options: config.Options{Namespaces: []string{"kube-system"}},
+			setup: func(p *fake.Provider) {
+				p.On("List", mock.Anything).Return(monitors, nil)
+				p.On("Delete", mock.Anything, "kube-system-bar").Return(nil)
+			},
+			isOrphaned:      isOrphaned,
+			expectedOrphans: 1,
+			validate: func(t *testing.T, p *fake.Provider) {
+				p.AssertExpectations(t)
+				p.AssertNotCalled(t, "Delete", mock.Anything, "default-baz")
+			},
+		},
+		{
+			name:    "ignores monitors of excluded namespaces",
+			options: config.Options{ExcludedNamespaces: []string{"kube-system"}},
+			setup: func(p *fake.Provider) {
+				p.On("List", mock.Anything).Return(monitors, nil)
+				p.On("Delete", mock.Anything, "default-baz").Return(nil)
+			},
+			isOrphaned:      isOrphaned,
+			expectedOrphans: 1,
+			validate: func(t *testing.T, p *fake.Provider) {
+				p.AssertExpectations(t)
+				p.AssertNotCalled(t, "Delete", mock.Anything, "kube-system-bar")
+			},
+		},
+		{
+			name: "continues after deletion errors",
+			setup: func(p *fake.Provider) {
+				p.On("List", mock.Anything).Return(monitors, nil)
+				p.On("Delete", mock.Anything, "kube-system-bar").Return(errors.New("whoops"))
+				p.On("Delete", mock.Anything, "default-baz").Return(nil)
+			},
+			isOrphaned:      isOrphaned,
+			expectedErr:     "provider fake: whoops",
+			expectedOrphans: 2,
+			validate: func(t *testing.T, p *fake.Provider) {
+				p.AssertExpectations(t)
+			},
+		},
+		{
+			name: "does not delete monitors if ownership cannot be checked",
+			setup: func(p *fake.Provider) {
+				p.On("List", mock.Anything).Return(monitors[:1], nil)
+			},
+			isOrphaned: func(models.Owner) (bool, error) {
+				return false, errors.New("cache unavailable")
+			},
+			expectedErr: "provider fake: cache unavailable",
+			validate: func(t *testing.T, p *fake.Provider) {
+				p.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "returns list errors",
+			setup: func(p *fake.Provider) {
+				p.On("List", mock.Anything).Return(nil, errors.New("whoops"))
+			},
+			isOrphaned:  isOrphaned,
+			expectedErr: "provider fake: whoops",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			metrics.OrphanedMonitors.Reset()
+
+			s, p := newTestService(t, &test.options)
+
+			if test.setup != nil {
+				test.setup(p)
+			}
+
+			err := s.DeleteOrphanedMonitors(context.Background(), test.isOrphaned)
+			if test.expectedErr != "" {
+				require.EqualError(t, err, test.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedOrphans, testutil.ToFloat64(metrics.OrphanedMonitors.WithLabelValues("fake")))
+			}
+
+			if test.validate != nil {
+				test.validate(t, p)
+			}
+		})
+	}
+}
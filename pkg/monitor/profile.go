@@ -0,0 +1,169 @@
+package monitor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// resolveAnnotations returns the annotations that configure the monitors of
+// obj like annotationsOf. If MonitorProfiles are enabled and obj references
+// one, the annotations equivalent to the profile are added unless obj sets
+// them itself. Passwords referenced via secrets are resolved in the namespace
+// of obj. Returns an error if the profile or a secret cannot be retrieved,
+// which is a *profileNotFoundError if the profile does not exist.
+func (s *service) resolveAnnotations(ctx context.Context, obj client.Object) (map[string]string, error) {
+	annotations := annotationsOf(obj)
+
+	// annotationsOf returns a copy for IngressMonitors, so it can be
+	// modified safely.
+	if im, ok := obj.(*v1alpha1.IngressMonitor); ok && im.Spec.Site24x7 != nil {
+		err := s.addSite24x7AuthPass(ctx, annotations, im.Namespace, im.Spec.Site24x7)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	name := strings.TrimSpace(annotations[config.AnnotationProfile])
+	if !s.options.MonitorProfiles || name == "" {
+		return annotations, nil
+	}
+
+	profile := &v1alpha1.MonitorProfile{}
+
+	err := s.client.Get(ctx, client.ObjectKey{Name: name}, profile)
+	if apierrors.IsNotFound(err) {
+		return nil, &profileNotFoundError{name: name}
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to get monitor profile %q", name)
+	}
+
+	resolved := profileAnnotations(profile)
+	for name, value := range annotations {
+		resolved[name] = value
+	}
+
+	if profile.Spec.Site24x7 != nil {
+		err = s.addSite24x7AuthPass(ctx, resolved, obj.GetNamespace(), profile.Spec.Site24x7)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
+
+// profileNotFoundError is returned if a referenced MonitorProfile does not
+// exist.
+type profileNotFoundError struct {
+	name string
+}
+
+func (e *profileNotFoundError) Error() string {
+	return fmt.Sprintf("monitor profile %q not found", e.name)
+}
+
+// isProfileNotFound returns true if err was caused by a missing
+// MonitorProfile.
+func isProfileNotFound(err error) bool {
+	_, ok := errors.Cause(err).(*profileNotFoundError)
+	return ok
+}
+
+// profileAnnotations returns the annotations equivalent to the fields of
+// profile which are set.
+func profileAnnotations(profile *v1alpha1.MonitorProfile) map[string]string {
+	annotations := make(map[string]string)
+	spec := profile.Spec
+
+	if spec.Site24x7 != nil {
+		addSite24x7Annotations(annotations, spec.Site24x7)
+	}
+
+	if spec.UptimeRobot != nil {
+		addUptimeRobotAnnotations(annotations, spec.UptimeRobot)
+	}
+
+	if spec.Pingdom != nil {
+		addPingdomAnnotations(annotations, spec.Pingdom)
+	}
+
+	if spec.Blackbox != nil {
+		setString(annotations, config.AnnotationBlackboxInterval, spec.Blackbox.Interval)
+		setString(annotations, config.AnnotationBlackboxModule, spec.Blackbox.Module)
+		setString(annotations, config.AnnotationBlackboxScrapeTimeout, spec.Blackbox.ScrapeTimeout)
+	}
+
+	if spec.Local != nil {
+		if spec.Local.Interval != nil {
+			annotations[config.AnnotationLocalInterval] = spec.Local.Interval.Duration.String()
+		}
+
+		if spec.Local.Timeout != nil {
+			annotations[config.AnnotationLocalTimeout] = spec.Local.Timeout.Duration.String()
+		}
+	}
+
+	return annotations
+}
+
+// addUptimeRobotAnnotations adds the annotations equivalent to the fields of
+// spec which are set.
+func addUptimeRobotAnnotations(annotations map[string]string, spec *v1alpha1.UptimeRobotSpec) {
+	setString(annotations, config.AnnotationUptimeRobotAlertContacts, strings.Join(spec.AlertContacts, ","))
+	setString(annotations, config.AnnotationUptimeRobotHTTPMethod, spec.HTTPMethod)
+	setInt(annotations, config.AnnotationUptimeRobotInterval, spec.Interval)
+	setInt(annotations, config.AnnotationUptimeRobotTimeout, spec.Timeout)
+
+	if len(spec.CustomHTTPHeaders) > 0 {
+		buf, _ := json.Marshal(spec.CustomHTTPHeaders)
+		annotations[config.AnnotationUptimeRobotCustomHTTPHeaders] = string(buf)
+	}
+}
+
+// addPingdomAnnotations adds the annotations equivalent to the fields of spec
+// which are set.
+func addPingdomAnnotations(annotations map[string]string, spec *v1alpha1.PingdomSpec) {
+	setString(annotations, config.AnnotationPingdomIntegrationIDs, joinInts(spec.IntegrationIDs))
+	setString(annotations, config.AnnotationPingdomProbeFilters, strings.Join(spec.ProbeFilters, ","))
+	setString(annotations, config.AnnotationPingdomTags, strings.Join(spec.Tags, ","))
+	setString(annotations, config.AnnotationPingdomTeamIDs, joinInts(spec.TeamIDs))
+	setString(annotations, config.AnnotationPingdomUserIDs, joinInts(spec.UserIDs))
+	setInt(annotations, config.AnnotationPingdomResolution, spec.Resolution)
+
+	if len(spec.RequestHeaders) > 0 {
+		buf, _ := json.Marshal(spec.RequestHeaders)
+		annotations[config.AnnotationPingdomRequestHeaders] = string(buf)
+	}
+}
+
+// setString sets the annotation name to value unless value is empty.
+func setString(annotations map[string]string, name, value string) {
+	if value != "" {
+		annotations[name] = value
+	}
+}
+
+// setInt sets the annotation name to value unless value is nil.
+func setInt(annotations map[string]string, name string, value *int) {
+	if value != nil {
+		annotations[name] = strconv.Itoa(*value)
+	}
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(value)
+	}
+
+	return strings.Join(parts, ",")
+}
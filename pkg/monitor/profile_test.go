@@ -0,0 +1,271 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/apis/v1alpha1"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestService_EnsureMonitor_MonitorProfile(t *testing.T) {
+	timeout := 20
+
+	profile := &v1alpha1.MonitorProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "critical-api"},
+		Spec: v1alpha1.MonitorProfileSpec{
+			Site24x7: &v1alpha1.Site24x7Spec{
+				CheckFrequency: "5",
+				Timeout:        &timeout,
+			},
+		},
+	}
+
+	newIngress := func(annotations map[string]string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "foo",
+				Namespace:   "kube-system",
+				UID:         "1234",
+				Annotations: annotations,
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{
+					{Host: "foo.bar.baz"},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name                string
+		ingress             *networkingv1.Ingress
+		monitorProfiles     bool
+		expectedAnnotations map[string]string
+		expectedError       string
+	}{
+		{
+			name: "profile settings are added",
+			ingress: newIngress(map[string]string{
+				config.AnnotationProfile: "critical-api",
+			}),
+			monitorProfiles: true,
+			expectedAnnotations: map[string]string{
+				config.AnnotationSite24x7CheckFrequency: "5",
+				config.AnnotationSite24x7Timeout:        "20",
+			},
+		},
+		{
+			name: "annotations take precedence over the profile",
+			ingress: newIngress(map[string]string{
+				config.AnnotationProfile:                "critical-api",
+				config.AnnotationSite24x7CheckFrequency: "1",
+			}),
+			monitorProfiles: true,
+			expectedAnnotations: map[string]string{
+				config.AnnotationSite24x7CheckFrequency: "1",
+				config.AnnotationSite24x7Timeout:        "20",
+			},
+		},
+		{
+			name: "profile is ignored if profiles are disabled",
+			ingress: newIngress(map[string]string{
+				config.AnnotationProfile: "critical-api",
+			}),
+			expectedAnnotations: map[string]string{
+				config.AnnotationSite24x7CheckFrequency: "",
+				config.AnnotationSite24x7Timeout:        "",
+			},
+		},
+		{
+			name: "missing profile",
+			ingress: newIngress(map[string]string{
+				config.AnnotationProfile: "missing",
+			}),
+			monitorProfiles: true,
+			expectedError:   `monitor profile "missing" not found`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			require.NoError(t, clientgoscheme.AddToScheme(scheme))
+			require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+			svc, p := newTestService(t, &config.Options{MonitorProfiles: test.monitorProfiles})
+			svc.client = fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(profile).Build()
+
+			recorder := record.NewFakeRecorder(10)
+			svc.recorder = recorder
+
+			p.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+			p.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+			_, err := svc.EnsureMonitor(context.Background(), test.ingress)
+
+			status := ParseStatus(test.ingress)
+			require.NotNil(t, status)
+			assert.Equal(t, test.expectedError, status.LastError)
+
+			require.NoError(t, err)
+
+			if test.expectedError != "" {
+				// Missing profiles are not retried, but reported.
+				p.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				require.Len(t, recorder.Events, 1)
+				assert.Equal(t, "Warning SyncFailed Failed to sync monitor: "+test.expectedError, <-recorder.Events)
+				return
+			}
+
+			p.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(monitor *models.Monitor) bool {
+				for name, value := range test.expectedAnnotations {
+					if monitor.Annotations[name] != value {
+						return false
+					}
+				}
+
+				return true
+			}))
+		})
+	}
+}
+
+func TestService_GetProviderIPSourceRanges_MissingProfile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	svc, p := newTestService(t, &config.Options{MonitorProfiles: true})
+	svc.client = fakeclient.NewClientBuilder().WithScheme(scheme).Build()
+
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "kube-system",
+			Annotations: map[string]string{config.AnnotationProfile: "missing"},
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{Host: "foo.bar.baz"},
+			},
+		},
+	}
+
+	// The missing profile is reported by EnsureMonitor, so the source ranges
+	// are skipped without requeueing.
+	ranges, err := svc.GetProviderIPSourceRanges(context.Background(), ing)
+	require.NoError(t, err)
+	assert.Nil(t, ranges)
+
+	p.AssertNotCalled(t, "GetIPSourceRanges", mock.Anything, mock.Anything)
+}
+
+func TestService_ResolveAnnotations_ProfileSecret(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1alpha1.AddToScheme(scheme))
+
+	profile := &v1alpha1.MonitorProfile{
+		ObjectMeta: metav1.ObjectMeta{Name: "basic-auth"},
+		Spec: v1alpha1.MonitorProfileSpec{
+			Site24x7: &v1alpha1.Site24x7Spec{
+				AuthUser:              "monitor",
+				AuthPasswordSecretRef: newSecretKeySelector("auth", "password", false),
+			},
+		},
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "auth", Namespace: "kube-system"},
+		Data:       map[string][]byte{"password": []byte("s3cr3t")},
+	}
+
+	svc, _ := newTestService(t, &config.Options{MonitorProfiles: true})
+	svc.client = fakeclient.NewClientBuilder().WithScheme(scheme).WithObjects(profile, secret).Build()
+
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "kube-system",
+			Annotations: map[string]string{config.AnnotationProfile: "basic-auth"},
+		},
+	}
+
+	annotations, err := svc.resolveAnnotations(context.Background(), ing)
+	require.NoError(t, err)
+	assert.Equal(t, "monitor", annotations[config.AnnotationSite24x7AuthUser])
+	assert.Equal(t, "s3cr3t", annotations[config.AnnotationSite24x7AuthPass])
+	assert.NotContains(t, ing.Annotations, config.AnnotationSite24x7AuthPass)
+
+	// The password annotation of the ingress takes precedence, so the
+	// secret is not needed.
+	ing.Namespace = "default"
+	ing.Annotations[config.AnnotationSite24x7AuthPass] = "other"
+
+	annotations, err = svc.resolveAnnotations(context.Background(), ing)
+	require.NoError(t, err)
+	assert.Equal(t, "other", annotations[config.AnnotationSite24x7AuthPass])
+}
+
+func TestProfileAnnotations(t *testing.T) {
+	interval := 60
+	resolution := 5
+
+	profile := &v1alpha1.MonitorProfile{
+		Spec: v1alpha1.MonitorProfileSpec{
+			Site24x7: &v1alpha1.Site24x7Spec{
+				HTTPMethod: "H",
+			},
+			UptimeRobot: &v1alpha1.UptimeRobotSpec{
+				AlertContacts:     []string{"123_0_0", "456_5_0"},
+				CustomHTTPHeaders: map[string]string{"Accept": "application/json"},
+				Interval:          &interval,
+			},
+			Pingdom: &v1alpha1.PingdomSpec{
+				IntegrationIDs: []int{1, 2},
+				ProbeFilters:   []string{"region: EU"},
+				Resolution:     &resolution,
+				Tags:           []string{"kubernetes", "critical"},
+				UserIDs:        []int{3},
+			},
+			Blackbox: &v1alpha1.BlackboxSpec{
+				Module: "http_post_2xx",
+			},
+			Local: &v1alpha1.LocalSpec{
+				Interval: &metav1.Duration{Duration: time.Minute},
+			},
+		},
+	}
+
+	expected := map[string]string{
+		config.AnnotationSite24x7HTTPMethod:           "H",
+		config.AnnotationUptimeRobotAlertContacts:     "123_0_0,456_5_0",
+		config.AnnotationUptimeRobotCustomHTTPHeaders: `{"Accept":"application/json"}`,
+		config.AnnotationUptimeRobotInterval:          "60",
+		config.AnnotationPingdomIntegrationIDs:        "1,2",
+		config.AnnotationPingdomProbeFilters:          "region: EU",
+		config.AnnotationPingdomResolution:            "5",
+		config.AnnotationPingdomTags:                  "kubernetes,critical",
+		config.AnnotationPingdomUserIDs:               "3",
+		config.AnnotationBlackboxModule:               "http_post_2xx",
+		config.AnnotationLocalInterval:                "1m0s",
+	}
+
+	annotations := profileAnnotations(profile)
+
+	assert.Equal(t, expected, annotations)
+	assert.Empty(t, config.Annotations(annotations).Validate())
+}
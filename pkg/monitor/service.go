@@ -1,12 +1,24 @@
 package monitor
 
 import (
+	"context"
+	"maps"
+	"sort"
+	"strings"
+	"time"
+
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/ingress"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/metrics"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -15,37 +27,82 @@ var log = logf.Log.WithName("monitor-service")
 // Service defines the interface for a service that takes care of creating,
 // updating or deleting monitors.
 type Service interface {
-	// EnsureMonitor ensures that a monitor is in sync with the current ingress
-	// configuration. If the monitor does not exist, it will be created.
-	EnsureMonitor(ingress *networkingv1.Ingress) error
-
-	// DeleteMonitor deletes the monitor for an ingress. It must not be treated
-	// as an error if the monitor was already deleted.
-	DeleteMonitor(ingress *networkingv1.Ingress) error
+	// EnsureMonitor ensures that a monitor is in sync with the current
+	// configuration of obj, which is either an *networkingv1.Ingress, an
+	// *v1alpha1.IngressMonitor or a Gateway API HTTPRoute as
+	// *unstructured.Unstructured. If the monitor
+	// does not exist, it will be created. If the monitor name changed since
+	// the last call, the existing monitor is renamed. The monitor name and ID
+	// as well as the status of the sync are recorded in the annotations of
+	// obj. If annotations were added or updated, the return value will be
+	// true, even if an error is returned, and the caller is responsible for
+	// persisting them.
+	EnsureMonitor(ctx context.Context, obj client.Object) (updated bool, err error)
+
+	// DeleteMonitor deletes the monitor for an ingress or HTTPRoute. It must
+	// not be treated as an error if the monitor was already deleted.
+	DeleteMonitor(ctx context.Context, obj client.Object) error
 
 	// GetProviderIPSourceRanges retrieves the IP source ranges that the
 	// monitor provider is using to perform checks from. It is a list of CIDR
 	// blocks. These source ranges can be used to update the IP whitelist (if
 	// one is defined) of an ingress to allow checks by the monitor provider.
-	GetProviderIPSourceRanges(ingress *networkingv1.Ingress) ([]string, error)
+	GetProviderIPSourceRanges(ctx context.Context, ingress *networkingv1.Ingress) ([]string, error)
 
 	// AnnotateIngress updates annotations of ingress if needed. If annotations
-	// were added, updated or deleted, the return value will be true.
-	AnnotateIngress(ingress *networkingv1.Ingress) (updated bool, err error)
+	// were added, updated or deleted, the return value will be true. If the
+	// source ranges of some providers could not be retrieved, ingress is
+	// still updated with the source ranges of the others and the error is
+	// returned alongside.
+	AnnotateIngress(ctx context.Context, ingress *networkingv1.Ingress) (updated bool, err error)
+
+	// DeleteOrphanedMonitors deletes all monitors owned by the controller for
+	// which isOrphaned returns true. Monitors not created by the controller
+	// are never touched. If garbage collection is configured to run in
+	// dry-run mode, orphaned monitors are only reported.
+	DeleteOrphanedMonitors(ctx context.Context, isOrphaned func(owner models.Owner) (bool, error)) error
+
+	// CheckProviders verifies that all enabled providers are usable, e.g.
+	// that their credentials are valid. Returns an aggregate of the errors of
+	// all providers that are not usable.
+	CheckProviders(ctx context.Context) error
 }
 
 type service struct {
-	provider provider.Interface
-	namer    *Namer
-	options  *config.Options
+	client           client.Client
+	providers        map[string]provider.Interface
+	providerNames    []string
+	defaultProviders []string
+	namer            *Namer
+	options          *config.Options
+	recorder         record.EventRecorder
+
+	// now is replaced in tests.
+	now func() time.Time
 }
 
-// NewService creates a new Service with options. Returns an error if service
-// initialization fails.
-func NewService(options *config.Options) (Service, error) {
-	provider, err := provider.New(options.ProviderName, options.ProviderConfig)
-	if err != nil {
-		return nil, err
+// NewService creates a new Service with options. The client is used to look
+// up the parent Gateways of HTTPRoutes, the ingresses referenced by
+// IngressMonitors and MonitorProfiles. It is passed to providers that manage
+// resources inside of the cluster. Events about the monitor lifecycle are
+// recorded for ingresses using recorder, which may be nil. Returns an error
+// if service initialization fails.
+func NewService(client client.Client, recorder record.EventRecorder, options *config.Options) (Service, error) {
+	providers := make(map[string]provider.Interface)
+
+	names := append(append([]string{}, options.ProviderNames...), options.ProviderConfig.EnabledProviders...)
+
+	for _, name := range names {
+		if _, found := providers[name]; found {
+			continue
+		}
+
+		p, err := provider.New(name, options.ProviderConfig, client)
+		if err != nil {
+			return nil, err
+		}
+
+		providers[name] = provider.NewGuard(p, options.ClusterID, options.AdoptUnowned)
 	}
 
 	namer, err := NewNamer(options.NameTemplate)
@@ -53,128 +110,631 @@ func NewService(options *config.Options) (Service, error) {
 		return nil, err
 	}
 
-	s := &service{
-		provider: provider,
-		namer:    namer,
-		options:  options,
+	svc := newService(providers, options.ProviderNames, namer, options)
+	svc.client = client
+	svc.recorder = recorder
+
+	return svc, nil
+}
+
+func newService(providers map[string]provider.Interface, defaultProviders []string, namer *Namer, options *config.Options) *service {
+	providerNames := make([]string, 0, len(providers))
+	for name := range providers {
+		providerNames = append(providerNames, name)
+	}
+
+	sort.Strings(providerNames)
+
+	return &service{
+		providers:        providers,
+		providerNames:    providerNames,
+		defaultProviders: defaultProviders,
+		namer:            namer,
+		options:          options,
+		now:              time.Now,
 	}
+}
+
+// EnsureMonitor implements Service. Besides the monitor name and ID, the
+// status of the sync is recorded in the annotations of obj. The return value
+// is true if any of these annotations changed.
+func (s *service) EnsureMonitor(ctx context.Context, obj client.Object) (bool, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	previous := maps.Clone(obj.GetAnnotations())
+	err := s.syncMonitors(ctx, obj)
 
-	return s, nil
+	return !maps.Equal(previous, obj.GetAnnotations()), err
 }
 
-// EnsureMonitor implements Service.
-func (s *service) EnsureMonitor(ing *networkingv1.Ingress) error {
-	err := ingress.Validate(ing)
+// syncMonitors ensures the monitors of obj and records the status of the
+// sync in its annotations.
+func (s *service) syncMonitors(ctx context.Context, obj client.Object) error {
+	status := &Status{LastSyncTime: metav1.NewTime(s.now())}
+
+	annotations, err := s.resolveAnnotations(ctx, obj)
+	if isProfileNotFound(err) {
+		// Retrying is pointless, the creation of the profile triggers the
+		// reconciliation of all resources referencing it.
+		log.Info("monitor profile not found", "kind", kindOf(obj), "namespace", obj.GetNamespace(), "name", obj.GetName(), "error", err.Error())
+		s.recordEvent(obj, corev1.EventTypeWarning, ReasonSyncFailed, "Failed to sync monitor: %v", err)
+		status.LastError = err.Error()
+		recordStatus(obj, status)
+		return nil
+	} else if err != nil {
+		s.recordEvent(obj, corev1.EventTypeWarning, ReasonSyncFailed, "Failed to sync monitor: %v", err)
+		status.LastError = err.Error()
+		recordStatus(obj, status)
+		return err
+	}
+
+	for _, err := range config.Annotations(annotations).Validate() {
+		s.recordEvent(obj, corev1.EventTypeWarning, ReasonInvalidAnnotation, err.Error())
+	}
+
+	err = validate(obj, s.namer.PerHost())
 	if err != nil {
-		metrics.IngressValidationErrorsTotal.WithLabelValues(ing.Namespace, ing.Name).Inc()
-		log.V(1).Info("ignoring unsupported ingress", "namespace", ing.Namespace, "name", ing.Name, "error", err)
+		metrics.IngressValidationErrorsTotal.WithLabelValues(obj.GetNamespace(), obj.GetName()).Inc()
+		log.V(1).Info("ignoring unsupported resource", "kind", kindOf(obj), "namespace", obj.GetNamespace(), "name", obj.GetName(), "error", err)
+		s.recordEvent(obj, corev1.EventTypeWarning, ReasonValidationFailed, "%s is not supported: %v", kindOf(obj), err)
+		status.LastError = err.Error()
+		recordStatus(obj, status)
 		return nil
 	}
 
-	newMonitor, err := s.buildMonitorModel(ing)
+	providerNames, err := s.selectProviders(annotations)
+	if err != nil {
+		metrics.IngressValidationErrorsTotal.WithLabelValues(obj.GetNamespace(), obj.GetName()).Inc()
+		log.Info("ignoring resource with invalid provider selection", "kind", kindOf(obj), "namespace", obj.GetNamespace(), "name", obj.GetName(), "error", err)
+		s.recordEvent(obj, corev1.EventTypeWarning, ReasonValidationFailed, "Invalid provider selection: %v", err)
+		status.LastError = err.Error()
+		recordStatus(obj, status)
+		return nil
+	}
+
+	status.Providers = providerNames
+
+	err = s.ensureMonitors(ctx, obj, annotations, providerNames)
+	if err != nil {
+		err = redactPassword(err, annotations)
+		s.recordEvent(obj, corev1.EventTypeWarning, ReasonSyncFailed, "Failed to sync monitor: %v", err)
+		status.LastError = err.Error()
+	}
+
+	status.MonitorID = obj.GetAnnotations()[config.AnnotationMonitorID]
+	recordStatus(obj, status)
+
+	return err
+}
+
+// redactPassword replaces the basic auth password of annotations in the
+// message of err, so that passwords read from secrets do not end up in the
+// status annotation, events or logs if a provider echoes them in an error.
+func redactPassword(err error, annotations map[string]string) error {
+	password := annotations[config.AnnotationSite24x7AuthPass]
+	if password == "" || !strings.Contains(err.Error(), password) {
+		return err
+	}
+
+	return errors.New(strings.ReplaceAll(err.Error(), password, "[redacted]"))
+}
+
+// ensureMonitors creates, renames or updates the monitors for obj in the
+// selected providers and deletes them from all others. The monitors are
+// configured using the resolved annotations. Monitors for hosts or paths
+// which were removed from obj are deleted. On success, the monitor names and
+// ID are recorded in the annotations of obj.
+func (s *service) ensureMonitors(ctx context.Context, obj client.Object, annotations map[string]string, providerNames []string) error {
+	newMonitors, err := s.buildMonitorModels(ctx, obj, annotations)
 	if err != nil {
 		return err
 	}
 
-	oldMonitor, err := s.provider.Get(newMonitor.Name)
-	if err == models.ErrMonitorNotFound {
-		return s.createMonitor(newMonitor)
-	} else if err != nil {
+	p := s.provider(providerNames)
+	previousNames := recordedNames(obj)
+
+	// A monitor can only be renamed unambiguously if there is exactly one.
+	// Otherwise monitors with stale names are deleted below once the
+	// monitors with the new names are in place.
+	var previousName string
+	if len(previousNames) == 1 && len(newMonitors) == 1 {
+		previousName = previousNames[0]
+	}
+
+	var id string
+
+	names := make([]string, len(newMonitors))
+
+	for i, newMonitor := range newMonitors {
+		id, err = s.ensureMonitor(ctx, obj, p, previousName, newMonitor)
+		if err != nil {
+			return err
+		}
+
+		names[i] = newMonitor.Name
+	}
+
+	err = s.deleteStaleMonitors(ctx, obj, previousNames, previousName, names)
+	if err != nil {
+		return err
+	}
+
+	err = s.deleteUnselectedMonitors(ctx, obj, providerNames, names)
+	if err != nil {
 		return err
 	}
 
-	return s.updateMonitor(oldMonitor, newMonitor)
+	if len(providerNames) > 1 || len(newMonitors) > 1 {
+		// Monitor IDs are provider specific and there is one per host or
+		// path, so there is no single ID we could record.
+		id = ""
+	}
+
+	recordMonitors(obj, names, id)
+
+	return nil
+}
+
+// ensureMonitor creates, renames or updates newMonitor in p and returns its
+// ID. If previousName is not empty, the monitor with that name is renamed.
+// The ID of a created monitor is only known if the provider stores it in
+// newMonitor.
+func (s *service) ensureMonitor(ctx context.Context, obj client.Object, p provider.Interface, previousName string, newMonitor *models.Monitor) (string, error) {
+	var id string
+
+	oldMonitor, err := p.Get(ctx, newMonitor.Name)
+	if err == models.ErrMonitorNotFound {
+		err = s.renameOrCreateMonitor(ctx, obj, p, previousName, newMonitor)
+		if err == nil {
+			id = newMonitor.ID
+		}
+	} else if err == nil {
+		id, err = s.updateMonitor(ctx, obj, p, oldMonitor, newMonitor)
+		if err == nil && previousName != "" && previousName != newMonitor.Name && !s.options.NoDelete {
+			// A monitor with the new name already exists, e.g. because a
+			// previous rename was interrupted. Clean up the monitor with
+			// the previous name.
+			err = s.deleteMonitor(ctx, obj, p, previousName)
+		}
+	}
+
+	return id, err
+}
+
+// deleteStaleMonitors deletes the monitors with previousNames which are not
+// part of names anymore, e.g. because their host was removed from obj. The
+// monitor renamed was already taken care of by ensureMonitor and is skipped.
+// Stale monitors are deleted from all providers.
+func (s *service) deleteStaleMonitors(ctx context.Context, obj client.Object, previousNames []string, renamed string, names []string) error {
+	if s.options.NoDelete {
+		return nil
+	}
+
+	for _, name := range previousNames {
+		if name == renamed || contains(names, name) {
+			continue
+		}
+
+		err := s.deleteMonitor(ctx, obj, s.provider(s.providerNames), name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordedNames returns the monitor names recorded in the annotations of obj.
+func recordedNames(obj client.Object) []string {
+	var names []string
+
+	for _, name := range strings.Split(obj.GetAnnotations()[config.AnnotationMonitorName], ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
 }
 
-// DeleteMonitor implements Service.
-func (s *service) DeleteMonitor(ingress *networkingv1.Ingress) error {
-	name, err := s.namer.Name(ingress)
+// recordMonitors records the comma-separated monitor names and the ID in the
+// annotations of obj. An empty id removes a previously recorded ID.
+func recordMonitors(obj client.Object, names []string, id string) {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	annotations[config.AnnotationMonitorName] = strings.Join(names, ",")
+
+	if id == "" {
+		delete(annotations, config.AnnotationMonitorID)
+	} else {
+		annotations[config.AnnotationMonitorID] = id
+	}
+
+	obj.SetAnnotations(annotations)
+}
+
+// DeleteMonitor implements Service. Besides the monitors for the current
+// hosts of obj, the monitors recorded in its annotations are deleted.
+func (s *service) DeleteMonitor(ctx context.Context, obj client.Object) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	names, err := s.monitorNames(obj)
 	if err != nil {
 		return err
 	}
 
 	if s.options.NoDelete {
-		log.V(1).Info("monitor deletion is disabled, not deleting", "monitor", name)
+		log.V(1).Info("monitor deletion is disabled, not deleting", "monitors", names)
+		return nil
+	}
+
+	// The annotations of obj may have changed since the monitor was
+	// created, so we have to delete it from every provider.
+	p := s.provider(s.providerNames)
+
+	// The monitors may not have been renamed yet after the name template
+	// was changed, or hosts may have been removed in the meantime.
+	for _, previousName := range recordedNames(obj) {
+		if contains(names, previousName) {
+			continue
+		}
+
+		err = s.deleteMonitor(ctx, obj, p, previousName)
+		if err != nil {
+			s.recordEvent(obj, corev1.EventTypeWarning, ReasonDeleteFailed, "Failed to delete monitor %q: %v", previousName, err)
+			return err
+		}
+	}
+
+	for _, name := range names {
+		err = s.deleteMonitor(ctx, obj, p, name)
+		if err != nil {
+			s.recordEvent(obj, corev1.EventTypeWarning, ReasonDeleteFailed, "Failed to delete monitor %q: %v", name, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// monitorNames returns the names of the monitors for the current hosts and
+// paths of obj. If the name template does not reference the host, there is
+// exactly one name for metadata-only stubs of deleted objects.
+func (s *service) monitorNames(obj client.Object) ([]string, error) {
+	endpoints := s.nameEndpoints(obj)
+
+	names := make([]string, 0, len(endpoints))
+
+	for _, endpoint := range endpoints {
+		name, err := s.namer.Name(obj, endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		if !contains(names, name) {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// deleteUnselectedMonitors deletes the monitors from all providers that are
+// not selected for obj anymore. This takes care of cleaning up after the
+// provider of an ingress was changed. Monitors without ownership marker are
+// never deleted, even if unowned monitors are adopted, as a monitor with the
+// same name in another provider was most likely not created for obj.
+func (s *service) deleteUnselectedMonitors(ctx context.Context, obj client.Object, selected []string, names []string) error {
+	if s.options.NoDelete {
 		return nil
 	}
 
-	return s.deleteMonitor(name)
+	var errs []error
+
+	for _, providerName := range s.providerNames {
+		if contains(selected, providerName) {
+			continue
+		}
+
+		p := s.providers[providerName]
+
+		for _, name := range names {
+			monitor, err := p.Get(ctx, name)
+			if err == models.ErrMonitorNotFound {
+				continue
+			} else if err != nil {
+				errs = append(errs, errors.Wrapf(err, "provider %s", providerName))
+				continue
+			}
+
+			if monitor.Owner == nil {
+				log.V(1).Info("not deleting monitor without ownership marker from unselected provider", "provider", providerName, "monitor", name)
+				continue
+			}
+
+			err = s.deleteMonitor(ctx, obj, p, name)
+			if err != nil {
+				errs = append(errs, errors.Wrapf(err, "provider %s", providerName))
+			}
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
 }
 
-func (s *service) createMonitor(monitor *models.Monitor) error {
-	err := s.provider.Create(monitor)
+// selectProviders returns the names of the providers that are responsible
+// for a monitor with the resolved annotations. Returns an error if the
+// annotations select providers that are not enabled.
+func (s *service) selectProviders(annotations map[string]string) ([]string, error) {
+	names := config.Annotations(annotations).StringSliceValue(config.AnnotationProvider, s.defaultProviders)
+
+	selected := make([]string, 0, len(names))
+
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+
+		if _, found := s.providers[name]; !found {
+			return nil, errors.Errorf("provider %q is not enabled", name)
+		}
+
+		if !contains(selected, name) {
+			selected = append(selected, name)
+		}
+	}
+
+	if len(selected) == 0 {
+		return nil, errors.Errorf("annotation %s must not be empty", config.AnnotationProvider)
+	}
+
+	return selected, nil
+}
+
+// provider returns the provider for given provider names. If there is more
+// than one name, a composite provider is returned.
+func (s *service) provider(names []string) provider.Interface {
+	if len(names) == 1 {
+		return s.providers[names[0]]
+	}
+
+	providers := make(map[string]provider.Interface, len(names))
+	for _, name := range names {
+		providers[name] = s.providers[name]
+	}
+
+	return provider.NewComposite(providers)
+}
+
+func (s *service) createMonitor(ctx context.Context, obj client.Object, p provider.Interface, monitor *models.Monitor) error {
+	err := p.Create(ctx, monitor)
 	if err != nil {
 		return err
 	}
 
 	metrics.MonitorsCreatedTotal.WithLabelValues(monitor.Name).Inc()
 	log.Info("monitor created", "monitor", monitor.Name)
+	s.recordEvent(obj, corev1.EventTypeNormal, ReasonMonitorCreated, "Created monitor %q", monitor.Name)
 
 	return nil
 }
 
-func (s *service) updateMonitor(oldMonitor, newMonitor *models.Monitor) error {
+// updateMonitor updates oldMonitor based on newMonitor and returns its ID.
+// If the provider supports drift detection, the update is skipped if the
+// monitor is already up to date. The ID is empty if the monitor is not owned
+// by the controller instance.
+func (s *service) updateMonitor(ctx context.Context, obj client.Object, p provider.Interface, oldMonitor, newMonitor *models.Monitor) (string, error) {
 	newMonitor.ID = oldMonitor.ID
 
-	err := s.provider.Update(newMonitor)
-	if err != nil {
-		return err
+	diffs, err := provider.Diff(ctx, p, oldMonitor, newMonitor)
+	if err == nil && len(diffs) == 0 {
+		log.V(1).Info("monitor is up to date", "monitor", newMonitor.Name)
+		return newMonitor.ID, nil
+	} else if err == nil {
+		log.Info("monitor configuration drifted", "monitor", newMonitor.Name, "diff", formatDiffs(diffs))
+	} else if err != models.ErrDiffNotSupported {
+		return "", err
+	}
+
+	err = p.Update(ctx, newMonitor)
+	if provider.IsNotOwned(err) {
+		log.Info("not updating monitor which is not owned by this controller instance, set the adopt annotation to take it over", "monitor", newMonitor.Name, "error", err.Error())
+		s.recordEvent(obj, corev1.EventTypeWarning, ReasonMonitorNotOwned, "Not updating monitor %q which is not owned by this %s, set the %s annotation to take it over", newMonitor.Name, kindOf(obj), config.AnnotationAdopt)
+		return "", nil
+	} else if err != nil {
+		return "", err
 	}
 
 	metrics.MonitorsUpdatedTotal.WithLabelValues(newMonitor.Name).Inc()
 	log.Info("monitor updated", "monitor", newMonitor.Name)
+	s.recordEvent(obj, corev1.EventTypeNormal, ReasonMonitorUpdated, "Updated monitor %q", newMonitor.Name)
+
+	return newMonitor.ID, nil
+}
+
+// renameOrCreateMonitor renames the monitor with previousName to the name of
+// newMonitor. If there is no monitor with previousName or it is not owned by
+// the controller instance, a new monitor is created instead.
+func (s *service) renameOrCreateMonitor(ctx context.Context, obj client.Object, p provider.Interface, previousName string, newMonitor *models.Monitor) error {
+	if previousName == "" || previousName == newMonitor.Name {
+		return s.createMonitor(ctx, obj, p, newMonitor)
+	}
+
+	err := provider.Rename(ctx, p, previousName, newMonitor)
+	if err == models.ErrMonitorNotFound {
+		log.V(1).Info("monitor with previous name is not present", "monitor", newMonitor.Name, "previous", previousName)
+		return s.createMonitor(ctx, obj, p, newMonitor)
+	} else if provider.IsNotOwned(err) {
+		log.Info("not renaming monitor which is not owned by this controller instance", "monitor", newMonitor.Name, "previous", previousName)
+		return s.createMonitor(ctx, obj, p, newMonitor)
+	} else if err != nil {
+		return err
+	}
+
+	metrics.MonitorsRenamedTotal.WithLabelValues(newMonitor.Name).Inc()
+	log.Info("monitor renamed", "monitor", newMonitor.Name, "previous", previousName)
+	s.recordEvent(obj, corev1.EventTypeNormal, ReasonMonitorRenamed, "Renamed monitor %q to %q", previousName, newMonitor.Name)
 
 	return nil
 }
 
-func (s *service) deleteMonitor(name string) error {
-	err := s.provider.Delete(name)
+// deleteMonitor deletes the monitor name of obj from p. Monitors owned by
+// another resource are not deleted, e.g. if an IngressMonitor or HTTPRoute
+// with the same monitor name took over the monitor of an ingress.
+func (s *service) deleteMonitor(ctx context.Context, obj client.Object, p provider.Interface, name string) error {
+	err := provider.DeleteOwned(ctx, p, name, s.ownerOf(obj))
 	if err == models.ErrMonitorNotFound {
 		log.V(1).Info("monitor is not present", "monitor", name)
 		return nil
+	} else if provider.IsNotOwned(err) && !contains(recordedNames(obj), name) {
+		// The monitor was never managed for obj, so there is nothing the
+		// user needs to be told about.
+		log.V(1).Info("not deleting monitor owned by another resource", "monitor", name, "error", err.Error())
+		return nil
+	} else if provider.IsNotOwned(err) {
+		log.Info("not deleting monitor which is not owned by this controller instance", "monitor", name)
+		s.recordEvent(obj, corev1.EventTypeWarning, ReasonMonitorNotOwned, "Not deleting monitor %q which is not owned by this controller instance", name)
+		return nil
 	} else if err != nil {
 		return err
 	}
 
 	metrics.MonitorsDeletedTotal.WithLabelValues(name).Inc()
 	log.Info("monitor deleted", "monitor", name)
+	s.recordEvent(obj, corev1.EventTypeNormal, ReasonMonitorDeleted, "Deleted monitor %q", name)
 
 	return nil
 }
 
-func (s *service) buildMonitorModel(ing *networkingv1.Ingress) (*models.Monitor, error) {
-	name, err := s.namer.Name(ing)
-	if err != nil {
-		return nil, err
+// ownerOf returns the owner that is recorded for the monitors of obj.
+func (s *service) ownerOf(obj client.Object) *models.Owner {
+	return &models.Owner{
+		ClusterID: s.options.ClusterID,
+		Kind:      ownerKind(obj),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		UID:       string(obj.GetUID()),
 	}
+}
 
-	url, err := ingress.BuildMonitorURL(ing)
+// buildMonitorModels builds the monitor models for the endpoints of obj. The
+// monitors are configured using the resolved annotations.
+func (s *service) buildMonitorModels(ctx context.Context, obj client.Object, annotations map[string]string) ([]*models.Monitor, error) {
+	endpoints, err := s.endpoints(ctx, obj)
 	if err != nil {
 		return nil, err
 	}
 
-	monitor := &models.Monitor{
-		URL:         url,
-		Name:        name,
-		Annotations: ing.Annotations,
+	monitors := make([]*models.Monitor, len(endpoints))
+
+	for i, endpoint := range endpoints {
+		name, err := s.namer.Name(obj, endpoint)
+		if err != nil {
+			return nil, err
+		}
+
+		url, err := ingress.BuildURL(endpoint, annotations)
+		if err != nil {
+			return nil, err
+		}
+
+		monitors[i] = &models.Monitor{
+			URL:         url,
+			Name:        name,
+			Annotations: annotations,
+			Owner:       s.ownerOf(obj),
+		}
 	}
 
-	return monitor, nil
+	return monitors, nil
 }
 
 // GetProviderIPSourceRanges implements Service.
-func (s *service) GetProviderIPSourceRanges(ing *networkingv1.Ingress) ([]string, error) {
-	err := ingress.Validate(ing)
+func (s *service) GetProviderIPSourceRanges(ctx context.Context, ing *networkingv1.Ingress) ([]string, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	err := validate(ing, s.namer.PerHost())
 	if err != nil {
 		metrics.IngressValidationErrorsTotal.WithLabelValues(ing.Namespace, ing.Name).Inc()
 		log.V(1).Info("ignoring unsupported ingress", "namespace", ing.Namespace, "name", ing.Name, "error", err)
 		return nil, nil
 	}
 
-	monitor, err := s.buildMonitorModel(ing)
+	annotations, err := s.resolveAnnotations(ctx, ing)
+	if isProfileNotFound(err) {
+		// The missing profile is reported by EnsureMonitor. Retrying is
+		// pointless, the creation of the profile triggers the reconciliation
+		// of all resources referencing it.
+		log.V(1).Info("ignoring ingress with missing monitor profile", "namespace", ing.Namespace, "name", ing.Name, "error", err.Error())
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	providerNames, err := s.selectProviders(annotations)
+	if err != nil {
+		metrics.IngressValidationErrorsTotal.WithLabelValues(ing.Namespace, ing.Name).Inc()
+		log.Info("ignoring ingress with invalid provider selection", "namespace", ing.Namespace, "name", ing.Name, "error", err)
+		return nil, nil
+	}
+
+	monitors, err := s.buildMonitorModels(ctx, ing, annotations)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.provider.GetIPSourceRanges(monitor)
+	// The IP source ranges only depend on the provider, so any of the
+	// monitors will do.
+	return s.provider(providerNames).GetIPSourceRanges(ctx, monitors[0])
+}
+
+// CheckProviders implements Service.
+func (s *service) CheckProviders(ctx context.Context) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+
+	var errs []error
+
+	for _, providerName := range s.providerNames {
+		err := provider.Check(ctx, s.providers[providerName])
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "provider %s", providerName))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// withTimeout applies the configured provider timeout to ctx. If no timeout
+// is configured, ctx is returned as is.
+func (s *service) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.options.ProviderTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, s.options.ProviderTimeout)
+}
+
+func formatDiffs(diffs []models.FieldDiff) []string {
+	result := make([]string, len(diffs))
+	for i, diff := range diffs {
+		result[i] = diff.String()
+	}
+
+	return result
+}
+
+func contains(s []string, e string) bool {
+	for _, v := range s {
+		if v == e {
+			return true
+		}
+	}
+
+	return false
 }
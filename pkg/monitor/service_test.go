@@ -1,17 +1,29 @@
 package monitor
 
 import (
+	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/gateway"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/metrics"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/fake"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/record"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestService_EnsureMonitor(t *testing.T) {
@@ -52,13 +64,14 @@ func TestService_EnsureMonitor(t *testing.T) {
 				},
 			},
 			setup: func(p *fake.Provider) {
-				p.On("Get", "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
-				p.On("Create", &models.Monitor{
+				p.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+				p.On("Create", mock.Anything, &models.Monitor{
 					URL:  "http://foo.bar.baz",
 					Name: "kube-system-foo",
 					Annotations: config.Annotations{
 						config.AnnotationEnabled: "true",
 					},
+					Owner: &models.Owner{Namespace: "kube-system", Name: "foo"},
 				}).Return(nil)
 			},
 		},
@@ -79,18 +92,19 @@ func TestService_EnsureMonitor(t *testing.T) {
 				},
 			},
 			setup: func(p *fake.Provider) {
-				p.On("Get", "kube-system-foo").Return(&models.Monitor{
+				p.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{
 					ID:   "123",
 					Name: "kube-system-foo",
 					URL:  "http://bar.baz",
 				}, nil)
-				p.On("Update", &models.Monitor{
+				p.On("Update", mock.Anything, &models.Monitor{
 					ID:   "123",
 					URL:  "http://foo.bar.baz",
 					Name: "kube-system-foo",
 					Annotations: config.Annotations{
 						config.AnnotationEnabled: "true",
 					},
+					Owner: &models.Owner{Namespace: "kube-system", Name: "foo"},
 				}).Return(nil)
 			},
 		},
@@ -111,11 +125,11 @@ func TestService_EnsureMonitor(t *testing.T) {
 				},
 			},
 			setup: func(p *fake.Provider) {
-				p.On("Get", "kube-system-foo").Return(nil, errors.New("error"))
+				p.On("Get", mock.Anything, "kube-system-foo").Return(nil, errors.New("error"))
 			},
 			validate: func(t *testing.T, p *fake.Provider) {
-				p.AssertNotCalled(t, "Create", mock.Anything)
-				p.AssertNotCalled(t, "Update", mock.Anything)
+				p.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				p.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
 			},
 			expected: errors.New("error"),
 		},
@@ -129,7 +143,7 @@ func TestService_EnsureMonitor(t *testing.T) {
 				test.setup(provider)
 			}
 
-			err := svc.EnsureMonitor(test.ingress)
+			_, err := svc.EnsureMonitor(context.Background(), test.ingress)
 			if test.expected != nil {
 				require.Error(t, err)
 				assert.Equal(t, test.expected.Error(), err.Error())
@@ -144,6 +158,814 @@ func TestService_EnsureMonitor(t *testing.T) {
 	}
 }
 
+func TestService_EnsureMonitor_Rename(t *testing.T) {
+	newIngress := func(previousName string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "kube-system",
+				Annotations: map[string]string{
+					config.AnnotationEnabled:     "true",
+					config.AnnotationMonitorName: previousName,
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{
+					{Host: "foo.bar.baz"},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		ingress       *networkingv1.Ingress
+		setup         func(*fake.Provider)
+		expectRenamed bool
+		expectedID    string
+	}{
+		{
+			name:    "records name and ID of existing monitor",
+			ingress: newIngress(""),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{ID: "123", Name: "kube-system-foo"}, nil)
+				p.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedID: "123",
+		},
+		{
+			name:    "records ID of created monitor",
+			ingress: newIngress(""),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+				p.On("Create", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+					args.Get(1).(*models.Monitor).ID = "456"
+				}).Return(nil)
+			},
+			expectedID: "456",
+		},
+		{
+			name:    "renames monitor if the name changed",
+			ingress: newIngress("foo"),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+				p.On("Get", mock.Anything, "foo").Return(&models.Monitor{ID: "123", Name: "foo"}, nil)
+				p.On("Create", mock.Anything, mock.MatchedBy(func(m *models.Monitor) bool {
+					return m.Name == "kube-system-foo"
+				})).Return(nil)
+				p.On("Delete", mock.Anything, "foo").Return(nil)
+			},
+			expectRenamed: true,
+		},
+		{
+			name:    "creates monitor if monitor with previous name does not exist",
+			ingress: newIngress("foo"),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+				p.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+				p.On("Create", mock.Anything, mock.Anything).Return(nil)
+			},
+		},
+		{
+			name:    "deletes monitor with previous name if monitor with new name exists",
+			ingress: newIngress("foo"),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{ID: "123", Name: "kube-system-foo"}, nil)
+				p.On("Update", mock.Anything, mock.Anything).Return(nil)
+				p.On("Delete", mock.Anything, "foo").Return(nil)
+			},
+			expectedID: "123",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc, p := newTestService(t, &config.Options{})
+
+			test.setup(p)
+
+			renamed := metrics.MonitorsRenamedTotal.WithLabelValues("kube-system-foo")
+			renamedBefore := testutil.ToFloat64(renamed)
+
+			updated, err := svc.EnsureMonitor(context.Background(), test.ingress)
+			require.NoError(t, err)
+			assert.True(t, updated)
+
+			p.AssertExpectations(t)
+
+			assert.Equal(t, "kube-system-foo", test.ingress.Annotations[config.AnnotationMonitorName])
+			assert.Equal(t, test.expectedID, test.ingress.Annotations[config.AnnotationMonitorID])
+
+			if test.expectRenamed {
+				assert.Equal(t, renamedBefore+1, testutil.ToFloat64(renamed))
+			} else {
+				assert.Equal(t, renamedBefore, testutil.ToFloat64(renamed))
+			}
+		})
+	}
+}
+
+func TestService_EnsureMonitor_UnchangedStatus(t *testing.T) {
+	svc, p := newTestService(t, &config.Options{})
+
+	p.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{ID: "123", Name: "kube-system-foo"}, nil)
+	p.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Namespace:   "kube-system",
+			Annotations: map[string]string{config.AnnotationEnabled: "true"},
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{Host: "foo.bar.baz"},
+			},
+		},
+	}
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	updated, err := svc.EnsureMonitor(context.Background(), ing)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	// A sync with the same outcome must not require an update of the
+	// ingress, even though the sync time changed.
+	now = now.Add(time.Minute)
+
+	updated, err = svc.EnsureMonitor(context.Background(), ing)
+	require.NoError(t, err)
+	assert.False(t, updated)
+	assert.Equal(t, "2020-01-02T03:04:05Z", ParseStatus(ing).LastSyncTime.UTC().Format(time.RFC3339))
+
+	p.On("Get", mock.Anything, "kube-system-foo").Unset()
+	p.On("Get", mock.Anything, "kube-system-foo").Return(nil, errors.New("whoops"))
+
+	updated, err = svc.EnsureMonitor(context.Background(), ing)
+	require.Error(t, err)
+	assert.True(t, updated)
+	assert.Equal(t, "whoops", ParseStatus(ing).LastError)
+}
+
+func TestService_EnsureMonitor_Drift(t *testing.T) {
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "kube-system",
+			Annotations: map[string]string{
+				config.AnnotationEnabled: "true",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{Host: "foo.bar.baz"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		setup        func(*fake.DiffingProvider)
+		expectUpdate bool
+		expectError  bool
+	}{
+		{
+			name: "skips update if monitor is up to date",
+			setup: func(p *fake.DiffingProvider) {
+				p.On("Diff", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+			},
+		},
+		{
+			name: "updates monitor on drift",
+			setup: func(p *fake.DiffingProvider) {
+				p.On("Diff", mock.Anything, mock.Anything, mock.Anything).Return([]models.FieldDiff{{Field: "Timeout", Current: 10, Desired: 30}}, nil)
+				p.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectUpdate: true,
+		},
+		{
+			name: "updates monitor if existing monitor cannot be compared",
+			setup: func(p *fake.DiffingProvider) {
+				p.On("Diff", mock.Anything, mock.Anything, mock.Anything).Return(nil, models.ErrDiffNotSupported)
+				p.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectUpdate: true,
+		},
+		{
+			name: "returns diff errors",
+			setup: func(p *fake.DiffingProvider) {
+				p.On("Diff", mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("whoops"))
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			namer, err := NewNamer("{{.Namespace}}-{{.IngressName}}")
+			require.NoError(t, err)
+
+			p := &fake.DiffingProvider{}
+			p.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{ID: "123", Name: "kube-system-foo"}, nil)
+			test.setup(p)
+
+			svc := newService(map[string]provider.Interface{"fake": p}, []string{"fake"}, namer, &config.Options{})
+
+			updatedTotal := metrics.MonitorsUpdatedTotal.WithLabelValues("kube-system-foo")
+			updatedBefore := testutil.ToFloat64(updatedTotal)
+
+			_, err = svc.EnsureMonitor(context.Background(), ing.DeepCopy())
+			if test.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			p.AssertExpectations(t)
+
+			if test.expectUpdate {
+				assert.Equal(t, updatedBefore+1, testutil.ToFloat64(updatedTotal))
+			} else {
+				p.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				assert.Equal(t, updatedBefore, testutil.ToFloat64(updatedTotal))
+			}
+		})
+	}
+}
+
+func TestService_EnsureMonitor_StatusAndEvents(t *testing.T) {
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC).Local()
+
+	newIngress := func(annotations map[string]string, rules ...networkingv1.IngressRule) *networkingv1.Ingress {
+		annotations[config.AnnotationEnabled] = "true"
+
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "foo",
+				Namespace:   "kube-system",
+				UID:         "1234",
+				Annotations: annotations,
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: rules,
+			},
+		}
+	}
+
+	rule := networkingv1.IngressRule{Host: "foo.bar.baz"}
+
+	tests := []struct {
+		name           string
+		ingress        *networkingv1.Ingress
+		setup          func(*fake.Provider)
+		expectedStatus *Status
+		expectedEvents []string
+		expectError    bool
+	}{
+		{
+			name:    "records status and event for created monitor",
+			ingress: newIngress(map[string]string{}, rule),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+				p.On("Create", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedStatus: &Status{
+				Providers:    []string{"fake"},
+				LastSyncTime: metav1.NewTime(now),
+			},
+			expectedEvents: []string{
+				`Normal MonitorCreated Created monitor "kube-system-foo"`,
+			},
+		},
+		{
+			name:    "records monitor ID of updated monitor",
+			ingress: newIngress(map[string]string{}, rule),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{ID: "123", Name: "kube-system-foo"}, nil)
+				p.On("Update", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedStatus: &Status{
+				MonitorID:    "123",
+				Providers:    []string{"fake"},
+				LastSyncTime: metav1.NewTime(now),
+			},
+			expectedEvents: []string{
+				`Normal MonitorUpdated Updated monitor "kube-system-foo"`,
+			},
+		},
+		{
+			name:    "records provider errors",
+			ingress: newIngress(map[string]string{}, rule),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo").Return(nil, errors.New("whoops"))
+			},
+			expectedStatus: &Status{
+				Providers:    []string{"fake"},
+				LastSyncTime: metav1.NewTime(now),
+				LastError:    "whoops",
+			},
+			expectedEvents: []string{
+				"Warning SyncFailed Failed to sync monitor: whoops",
+			},
+			expectError: true,
+		},
+		{
+			name:    "records validation errors",
+			ingress: newIngress(map[string]string{}),
+			expectedStatus: &Status{
+				LastSyncTime: metav1.NewTime(now),
+				LastError:    "ingress does not have any rules",
+			},
+			expectedEvents: []string{
+				"Warning ValidationFailed Ingress is not supported: ingress does not have any rules",
+			},
+		},
+		{
+			name: "records events for invalid annotations",
+			ingress: newIngress(map[string]string{
+				config.AnnotationForceHTTPS: "yes",
+			}, rule),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+				p.On("Create", mock.Anything, mock.Anything).Return(nil)
+			},
+			expectedStatus: &Status{
+				Providers:    []string{"fake"},
+				LastSyncTime: metav1.NewTime(now),
+			},
+			expectedEvents: []string{
+				`Warning InvalidAnnotation invalid bool value in annotation "ingress-monitor.bonial.com/force-https": yes: strconv.ParseBool: parsing "yes": invalid syntax`,
+				`Normal MonitorCreated Created monitor "kube-system-foo"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc, p := newTestService(t, &config.Options{})
+
+			recorder := record.NewFakeRecorder(10)
+			svc.recorder = recorder
+			svc.now = func() time.Time { return now }
+
+			if test.setup != nil {
+				test.setup(p)
+			}
+
+			updated, err := svc.EnsureMonitor(context.Background(), test.ingress)
+			if test.expectError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.True(t, updated)
+			assert.Equal(t, test.expectedStatus, ParseStatus(test.ingress))
+
+			close(recorder.Events)
+
+			events := []string{}
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+
+			assert.Equal(t, test.expectedEvents, events)
+		})
+	}
+}
+
+func TestService_EnsureMonitor_HTTPRoute(t *testing.T) {
+	newRoute := func(hostnames ...interface{}) *unstructured.Unstructured {
+		route := gateway.NewHTTPRoute()
+		route.SetName("foo")
+		route.SetNamespace("kube-system")
+		route.SetUID("1234")
+		route.SetAnnotations(map[string]string{config.AnnotationEnabled: "true"})
+		route.Object["spec"] = map[string]interface{}{
+			"hostnames": hostnames,
+			"parentRefs": []interface{}{
+				map[string]interface{}{"name": "gw", "namespace": "infra"},
+			},
+		}
+		return route
+	}
+
+	newGateway := func(protocol string) *unstructured.Unstructured {
+		gw := gateway.NewGateway()
+		gw.SetName("gw")
+		gw.SetNamespace("infra")
+		gw.Object["spec"] = map[string]interface{}{
+			"listeners": []interface{}{
+				map[string]interface{}{"name": "default", "protocol": protocol},
+			},
+		}
+		return gw
+	}
+
+	tests := []struct {
+		name           string
+		route          *unstructured.Unstructured
+		gateway        *unstructured.Unstructured
+		expectedURL    string
+		expectedEvents []string
+		expectedError  string
+	}{
+		{
+			name:        "https listener",
+			route:       newRoute("foo.bar.baz"),
+			gateway:     newGateway("HTTPS"),
+			expectedURL: "https://foo.bar.baz",
+			expectedEvents: []string{
+				`Normal MonitorCreated Created monitor "kube-system-foo"`,
+			},
+		},
+		{
+			name:        "http listener",
+			route:       newRoute("foo.bar.baz"),
+			gateway:     newGateway("HTTP"),
+			expectedURL: "http://foo.bar.baz",
+			expectedEvents: []string{
+				`Normal MonitorCreated Created monitor "kube-system-foo"`,
+			},
+		},
+		{
+			name:        "missing gateway",
+			route:       newRoute("foo.bar.baz"),
+			expectedURL: "http://foo.bar.baz",
+			expectedEvents: []string{
+				`Normal MonitorCreated Created monitor "kube-system-foo"`,
+			},
+		},
+		{
+			name:          "route without hostnames",
+			route:         newRoute(),
+			expectedError: "HTTPRoute does not have any hostnames",
+			expectedEvents: []string{
+				"Warning ValidationFailed HTTPRoute is not supported: HTTPRoute does not have any hostnames",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			builder := fakeclient.NewClientBuilder()
+			if test.gateway != nil {
+				builder = builder.WithObjects(test.gateway)
+			}
+
+			recorder := record.NewFakeRecorder(10)
+
+			svc, p := newTestService(t, &config.Options{ClusterID: "prod"})
+			svc.client = builder.Build()
+			svc.recorder = recorder
+
+			p.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+			p.On("Create", mock.Anything, mock.Anything).Return(nil)
+
+			updated, err := svc.EnsureMonitor(context.Background(), test.route)
+			require.NoError(t, err)
+			assert.True(t, updated)
+
+			status := ParseStatus(test.route)
+			require.NotNil(t, status)
+			assert.Equal(t, test.expectedError, status.LastError)
+
+			if test.expectedURL != "" {
+				p.AssertCalled(t, "Create", mock.Anything, mock.MatchedBy(func(monitor *models.Monitor) bool {
+					return monitor.URL == test.expectedURL &&
+						*monitor.Owner == models.Owner{ClusterID: "prod", Kind: "HTTPRoute", Namespace: "kube-system", Name: "foo", UID: "1234"}
+				}))
+				assert.Equal(t, "kube-system-foo", test.route.GetAnnotations()[config.AnnotationMonitorName])
+			} else {
+				p.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			}
+
+			close(recorder.Events)
+
+			var events []string
+			for event := range recorder.Events {
+				events = append(events, event)
+			}
+
+			assert.Equal(t, test.expectedEvents, events)
+		})
+	}
+}
+
+func TestService_EnsureMonitor_PerHost(t *testing.T) {
+	newIngress := func(monitorNames string, hosts ...string) *networkingv1.Ingress {
+		ing := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "kube-system",
+				Annotations: map[string]string{
+					config.AnnotationEnabled: "true",
+				},
+			},
+		}
+
+		if monitorNames != "" {
+			ing.Annotations[config.AnnotationMonitorName] = monitorNames
+		}
+
+		for _, host := range hosts {
+			ing.Spec.Rules = append(ing.Spec.Rules, networkingv1.IngressRule{Host: host})
+		}
+
+		return ing
+	}
+
+	tests := []struct {
+		name          string
+		ingress       *networkingv1.Ingress
+		setup         func(*fake.Provider)
+		expectedNames string
+		expectedID    string
+	}{
+		{
+			name:    "creates a monitor for every host",
+			ingress: newIngress("", "foo.bar.baz", "*.bar.baz", "bar.bar.baz"),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "foo-foo.bar.baz").Return(nil, models.ErrMonitorNotFound)
+				p.On("Get", mock.Anything, "foo-bar.bar.baz").Return(nil, models.ErrMonitorNotFound)
+				p.On("Create", mock.Anything, &models.Monitor{
+					Name:        "foo-foo.bar.baz",
+					URL:         "http://foo.bar.baz",
+					Annotations: map[string]string{config.AnnotationEnabled: "true"},
+					Owner:       &models.Owner{Namespace: "kube-system", Name: "foo"},
+				}).Return(nil)
+				p.On("Create", mock.Anything, &models.Monitor{
+					Name:        "foo-bar.bar.baz",
+					URL:         "http://bar.bar.baz",
+					Annotations: map[string]string{config.AnnotationEnabled: "true"},
+					Owner:       &models.Owner{Namespace: "kube-system", Name: "foo"},
+				}).Return(nil)
+			},
+			expectedNames: "foo-foo.bar.baz,foo-bar.bar.baz",
+		},
+		{
+			name:    "deletes monitors of removed hosts",
+			ingress: newIngress("foo-foo.bar.baz,foo-bar.bar.baz", "foo.bar.baz"),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "foo-foo.bar.baz").Return(&models.Monitor{ID: "1", Name: "foo-foo.bar.baz"}, nil)
+				p.On("Update", mock.Anything, mock.Anything).Return(nil)
+				p.On("Delete", mock.Anything, "foo-bar.bar.baz").Return(nil)
+			},
+			expectedNames: "foo-foo.bar.baz",
+			expectedID:    "1",
+		},
+		{
+			name:    "renames a single monitor",
+			ingress: newIngress("kube-system-foo", "foo.bar.baz"),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "foo-foo.bar.baz").Return(nil, models.ErrMonitorNotFound)
+				p.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{ID: "1", Name: "kube-system-foo"}, nil)
+				p.On("Create", mock.Anything, mock.Anything).Return(nil)
+				p.On("Delete", mock.Anything, "kube-system-foo").Return(nil)
+			},
+			expectedNames: "foo-foo.bar.baz",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			namer, err := NewNamer("{{.IngressName}}-{{.Host}}")
+			require.NoError(t, err)
+
+			p := &fake.Provider{}
+			test.setup(p)
+
+			svc := newService(map[string]provider.Interface{"fake": p}, []string{"fake"}, namer, &config.Options{})
+
+			_, err = svc.EnsureMonitor(context.Background(), test.ingress)
+			require.NoError(t, err)
+
+			p.AssertExpectations(t)
+			assert.Equal(t, test.expectedNames, test.ingress.Annotations[config.AnnotationMonitorName])
+			assert.Equal(t, test.expectedID, test.ingress.Annotations[config.AnnotationMonitorID])
+		})
+	}
+}
+
+func TestService_DeleteMonitor_PerHost(t *testing.T) {
+	namer, err := NewNamer("{{.IngressName}}-{{.Host}}")
+	require.NoError(t, err)
+
+	p := &fake.Provider{}
+	p.On("Delete", mock.Anything, "foo-removed.bar.baz").Return(nil)
+	p.On("Delete", mock.Anything, "foo-foo.bar.baz").Return(nil)
+	p.On("Delete", mock.Anything, "foo-bar.bar.baz").Return(models.ErrMonitorNotFound)
+
+	svc := newService(map[string]provider.Interface{"fake": p}, []string{"fake"}, namer, &config.Options{})
+
+	err = svc.DeleteMonitor(context.Background(), &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "kube-system",
+			Annotations: map[string]string{
+				config.AnnotationMonitorName: "foo-foo.bar.baz,foo-removed.bar.baz",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{Host: "foo.bar.baz"},
+				{Host: "bar.bar.baz"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	p.AssertExpectations(t)
+}
+
+func TestService_EnsureMonitor_Paths(t *testing.T) {
+	prefix := networkingv1.PathTypePrefix
+	implementationSpecific := networkingv1.PathTypeImplementationSpecific
+
+	newIngress := func(monitorNames string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "kube-system",
+				Annotations: map[string]string{
+					config.AnnotationMonitorPaths: "true",
+					config.AnnotationMonitorName:  monitorNames,
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{
+					{
+						Host: "foo.bar.baz",
+						IngressRuleValue: networkingv1.IngressRuleValue{
+							HTTP: &networkingv1.HTTPIngressRuleValue{
+								Paths: []networkingv1.HTTPIngressPath{
+									{Path: "/", PathType: &prefix},
+									{Path: "/api", PathType: &prefix},
+									{Path: "/legacy(/|$)(.*)", PathType: &implementationSpecific},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		ingress *networkingv1.Ingress
+		setup   func(*fake.Provider)
+	}{
+		{
+			name:    "creates a monitor for every path",
+			ingress: newIngress("kube-system-foo"),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{ID: "1", Name: "kube-system-foo", URL: "http://foo.bar.baz/"}, nil)
+				p.On("Update", mock.Anything, mock.MatchedBy(func(m *models.Monitor) bool {
+					return m.Name == "kube-system-foo" && m.URL == "http://foo.bar.baz/"
+				})).Return(nil)
+				p.On("Get", mock.Anything, "kube-system-foo/api").Return(nil, models.ErrMonitorNotFound)
+				p.On("Create", mock.Anything, mock.MatchedBy(func(m *models.Monitor) bool {
+					return m.Name == "kube-system-foo/api" && m.URL == "http://foo.bar.baz/api"
+				})).Return(nil)
+			},
+		},
+		{
+			name:    "deletes monitors of removed paths",
+			ingress: newIngress("kube-system-foo,kube-system-foo/api,kube-system-foo/removed"),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{ID: "1", Name: "kube-system-foo"}, nil)
+				p.On("Get", mock.Anything, "kube-system-foo/api").Return(&models.Monitor{ID: "2", Name: "kube-system-foo/api"}, nil)
+				p.On("Update", mock.Anything, mock.Anything).Return(nil)
+				p.On("Delete", mock.Anything, "kube-system-foo/removed").Return(nil)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			namer, err := NewNamer("{{.Namespace}}-{{.IngressName}}")
+			require.NoError(t, err)
+
+			p := &fake.Provider{}
+			test.setup(p)
+
+			svc := newService(map[string]provider.Interface{"fake": p}, []string{"fake"}, namer, &config.Options{})
+
+			_, err = svc.EnsureMonitor(context.Background(), test.ingress)
+			require.NoError(t, err)
+
+			p.AssertExpectations(t)
+			assert.Equal(t, "kube-system-foo,kube-system-foo/api", test.ingress.Annotations[config.AnnotationMonitorName])
+			assert.Empty(t, test.ingress.Annotations[config.AnnotationMonitorID])
+		})
+	}
+}
+
+func TestService_EnsureMonitor_ProbeHosts(t *testing.T) {
+	newIngress := func(probeHosts string) *networkingv1.Ingress {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "foo",
+				Namespace: "kube-system",
+				Annotations: map[string]string{
+					config.AnnotationProbeHosts: probeHosts,
+				},
+			},
+			Spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{
+					{Hosts: []string{"*.bar.baz"}},
+				},
+				Rules: []networkingv1.IngressRule{
+					{Host: "*.bar.baz"},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		nameTemplate  string
+		ingress       *networkingv1.Ingress
+		setup         func(*fake.Provider)
+		expectedNames string
+		expectedError string
+	}{
+		{
+			name:         "creates a monitor for the probe host of a wildcard ingress",
+			nameTemplate: "{{.Namespace}}-{{.IngressName}}",
+			ingress:      newIngress("shop.bar.baz"),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+				p.On("Create", mock.Anything, mock.MatchedBy(func(m *models.Monitor) bool {
+					return m.Name == "kube-system-foo" && m.URL == "https://shop.bar.baz"
+				})).Return(nil)
+			},
+			expectedNames: "kube-system-foo",
+		},
+		{
+			name:         "creates a monitor for every probe host",
+			nameTemplate: "{{.IngressName}}-{{.Host}}",
+			ingress:      newIngress("shop.bar.baz,blog.bar.baz"),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "foo-shop.bar.baz").Return(nil, models.ErrMonitorNotFound)
+				p.On("Get", mock.Anything, "foo-blog.bar.baz").Return(nil, models.ErrMonitorNotFound)
+				p.On("Create", mock.Anything, mock.MatchedBy(func(m *models.Monitor) bool {
+					return m.Name == "foo-shop.bar.baz" && m.URL == "https://shop.bar.baz"
+				})).Return(nil)
+				p.On("Create", mock.Anything, mock.MatchedBy(func(m *models.Monitor) bool {
+					return m.Name == "foo-blog.bar.baz" && m.URL == "https://blog.bar.baz"
+				})).Return(nil)
+			},
+			expectedNames: "foo-shop.bar.baz,foo-blog.bar.baz",
+		},
+		{
+			name:         "appends the host to the names of multiple probe hosts if the name template does not reference the host",
+			nameTemplate: "{{.Namespace}}-{{.IngressName}}",
+			ingress:      newIngress("shop.bar.baz,blog.bar.baz"),
+			setup: func(p *fake.Provider) {
+				p.On("Get", mock.Anything, "kube-system-foo-shop.bar.baz").Return(nil, models.ErrMonitorNotFound)
+				p.On("Get", mock.Anything, "kube-system-foo-blog.bar.baz").Return(nil, models.ErrMonitorNotFound)
+				p.On("Create", mock.Anything, mock.MatchedBy(func(m *models.Monitor) bool {
+					return m.Name == "kube-system-foo-shop.bar.baz" && m.URL == "https://shop.bar.baz"
+				})).Return(nil)
+				p.On("Create", mock.Anything, mock.MatchedBy(func(m *models.Monitor) bool {
+					return m.Name == "kube-system-foo-blog.bar.baz" && m.URL == "https://blog.bar.baz"
+				})).Return(nil)
+			},
+			expectedNames: "kube-system-foo-shop.bar.baz,kube-system-foo-blog.bar.baz",
+		},
+		{
+			name:          "rejects probe hosts that do not match the ingress",
+			nameTemplate:  "{{.Namespace}}-{{.IngressName}}",
+			ingress:       newIngress("shop.example.com"),
+			setup:         func(p *fake.Provider) {},
+			expectedError: `probe host "shop.example.com" does not match any host of the ingress`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			namer, err := NewNamer(test.nameTemplate)
+			require.NoError(t, err)
+
+			p := &fake.Provider{}
+			test.setup(p)
+
+			svc := newService(map[string]provider.Interface{"fake": p}, []string{"fake"}, namer, &config.Options{})
+
+			_, err = svc.EnsureMonitor(context.Background(), test.ingress)
+			require.NoError(t, err)
+
+			p.AssertExpectations(t)
+			assert.Equal(t, test.expectedNames, test.ingress.Annotations[config.AnnotationMonitorName])
+			assert.Equal(t, test.expectedError, ParseStatus(test.ingress).LastError)
+		})
+	}
+}
+
 func TestService_DeleteMonitor(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -162,10 +984,10 @@ func TestService_DeleteMonitor(t *testing.T) {
 				},
 			},
 			setup: func(p *fake.Provider) {
-				p.On("Delete", "kube-system-foo").Return(nil)
+				p.On("Delete", mock.Anything, "kube-system-foo").Return(nil)
 			},
 			validate: func(t *testing.T, p *fake.Provider) {
-				p.AssertCalled(t, "Delete", "kube-system-foo")
+				p.AssertCalled(t, "Delete", mock.Anything, "kube-system-foo")
 			},
 		},
 		{
@@ -177,10 +999,29 @@ func TestService_DeleteMonitor(t *testing.T) {
 				},
 			},
 			setup: func(p *fake.Provider) {
-				p.On("Delete", "kube-system-foo").Return(models.ErrMonitorNotFound)
+				p.On("Delete", mock.Anything, "kube-system-foo").Return(models.ErrMonitorNotFound)
 			},
 			validate: func(t *testing.T, p *fake.Provider) {
-				p.AssertCalled(t, "Delete", "kube-system-foo")
+				p.AssertCalled(t, "Delete", mock.Anything, "kube-system-foo")
+			},
+		},
+		{
+			name: "also deletes monitor with previous name if it was not renamed yet",
+			ingress: &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "foo",
+					Namespace: "kube-system",
+					Annotations: map[string]string{
+						config.AnnotationMonitorName: "foo",
+					},
+				},
+			},
+			setup: func(p *fake.Provider) {
+				p.On("Delete", mock.Anything, "foo").Return(nil)
+				p.On("Delete", mock.Anything, "kube-system-foo").Return(models.ErrMonitorNotFound)
+			},
+			validate: func(t *testing.T, p *fake.Provider) {
+				p.AssertExpectations(t)
 			},
 		},
 		{
@@ -193,7 +1034,7 @@ func TestService_DeleteMonitor(t *testing.T) {
 				},
 			},
 			validate: func(t *testing.T, p *fake.Provider) {
-				p.AssertNotCalled(t, "Delete", mock.Anything)
+				p.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
 			},
 		},
 	}
@@ -206,7 +1047,7 @@ func TestService_DeleteMonitor(t *testing.T) {
 				test.setup(provider)
 			}
 
-			err := svc.DeleteMonitor(test.ingress)
+			err := svc.DeleteMonitor(context.Background(), test.ingress)
 			if test.expected != nil {
 				require.Error(t, err)
 				assert.Equal(t, test.expected.Error(), err.Error())
@@ -269,9 +1110,10 @@ func TestService_GetProviderIPSourceRanges(t *testing.T) {
 			},
 			expected: []string{"1.2.3.4/32", "1.3.3.7/32"},
 			setup: func(p *fake.Provider) {
-				p.On("GetIPSourceRanges", &models.Monitor{
-					Name: "kube-system-foo",
-					URL:  "http://foo.bar.baz",
+				p.On("GetIPSourceRanges", mock.Anything, &models.Monitor{
+					Name:  "kube-system-foo",
+					URL:   "http://foo.bar.baz",
+					Owner: &models.Owner{Namespace: "kube-system", Name: "foo"},
 				}).Return([]string{"1.2.3.4/32", "1.3.3.7/32"}, nil)
 			},
 		},
@@ -285,7 +1127,7 @@ func TestService_GetProviderIPSourceRanges(t *testing.T) {
 				test.setup(provider)
 			}
 
-			result, err := svc.GetProviderIPSourceRanges(test.ingress)
+			result, err := svc.GetProviderIPSourceRanges(context.Background(), test.ingress)
 			if test.expectError {
 				require.Error(t, err)
 			} else {
@@ -296,19 +1138,427 @@ func TestService_GetProviderIPSourceRanges(t *testing.T) {
 	}
 }
 
+func TestService_ProviderSelection(t *testing.T) {
+	newIngress := func(annotations map[string]string) *networkingv1.Ingress {
+		annotations[config.AnnotationEnabled] = "true"
+
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "foo",
+				Namespace:   "kube-system",
+				Annotations: annotations,
+			},
+			Spec: networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{
+					{Host: "foo.bar.baz"},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		ingress  *networkingv1.Ingress
+		options  config.Options
+		setup    func(a, b *fake.Provider)
+		validate func(t *testing.T, a, b *fake.Provider)
+	}{
+		{
+			name:    "default provider is used without annotation and monitor is removed from others",
+			ingress: newIngress(map[string]string{}),
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+				a.On("Create", mock.Anything, mock.Anything).Return(nil)
+				b.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{Name: "kube-system-foo", Owner: &models.Owner{Namespace: "kube-system", Name: "foo"}}, nil)
+				b.On("Delete", mock.Anything, "kube-system-foo").Return(nil)
+			},
+			validate: func(t *testing.T, a, b *fake.Provider) {
+				a.AssertCalled(t, "Create", mock.Anything, mock.Anything)
+				b.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				b.AssertCalled(t, "Delete", mock.Anything, "kube-system-foo")
+			},
+		},
+		{
+			name:    "monitor without ownership marker is not removed from unselected providers",
+			ingress: newIngress(map[string]string{}),
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+				a.On("Create", mock.Anything, mock.Anything).Return(nil)
+				b.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{Name: "kube-system-foo"}, nil)
+			},
+			validate: func(t *testing.T, a, b *fake.Provider) {
+				a.AssertCalled(t, "Create", mock.Anything, mock.Anything)
+				b.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "annotation selects provider and monitor is moved away from default provider",
+			ingress: newIngress(map[string]string{
+				config.AnnotationProvider: "b",
+			}),
+			setup: func(a, b *fake.Provider) {
+				b.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+				b.On("Create", mock.Anything, mock.Anything).Return(nil)
+				a.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{Name: "kube-system-foo", Owner: &models.Owner{Namespace: "kube-system", Name: "foo"}}, nil)
+				a.On("Delete", mock.Anything, "kube-system-foo").Return(nil)
+			},
+			validate: func(t *testing.T, a, b *fake.Provider) {
+				b.AssertCalled(t, "Create", mock.Anything, mock.Anything)
+				a.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+				a.AssertCalled(t, "Delete", mock.Anything, "kube-system-foo")
+			},
+		},
+		{
+			name: "annotation may select multiple providers",
+			ingress: newIngress(map[string]string{
+				config.AnnotationProvider: "a, b",
+			}),
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{ID: "1", Name: "kube-system-foo"}, nil)
+				a.On("Update", mock.Anything, mock.Anything).Return(nil)
+				b.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+				b.On("Create", mock.Anything, mock.Anything).Return(nil)
+			},
+			validate: func(t *testing.T, a, b *fake.Provider) {
+				a.AssertCalled(t, "Update", mock.Anything, mock.Anything)
+				b.AssertCalled(t, "Create", mock.Anything, mock.Anything)
+				a.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+				b.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			},
+		},
+		{
+			name: "ingress selecting a provider that is not enabled is ignored",
+			ingress: newIngress(map[string]string{
+				config.AnnotationProvider: "c",
+			}),
+			validate: func(t *testing.T, a, b *fake.Provider) {
+				assert.Len(t, a.Calls, 0)
+				assert.Len(t, b.Calls, 0)
+			},
+		},
+		{
+			name:    "monitor is not removed from unselected providers if NoDelete is set",
+			ingress: newIngress(map[string]string{}),
+			options: config.Options{NoDelete: true},
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "kube-system-foo").Return(nil, models.ErrMonitorNotFound)
+				a.On("Create", mock.Anything, mock.Anything).Return(nil)
+			},
+			validate: func(t *testing.T, a, b *fake.Provider) {
+				assert.Len(t, b.Calls, 0)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			namer, err := NewNamer("{{.Namespace}}-{{.IngressName}}")
+			require.NoError(t, err)
+
+			a, b := &fake.Provider{}, &fake.Provider{}
+
+			if test.setup != nil {
+				test.setup(a, b)
+			}
+
+			svc := newService(map[string]provider.Interface{"a": a, "b": b}, []string{"a"}, namer, &test.options)
+
+			_, err = svc.EnsureMonitor(context.Background(), test.ingress)
+			require.NoError(t, err)
+
+			test.validate(t, a, b)
+		})
+	}
+}
+
+func TestService_EnsureMonitor_Drift_FailingProvider(t *testing.T) {
+	namer, err := NewNamer("{{.Namespace}}-{{.IngressName}}")
+	require.NoError(t, err)
+
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "kube-system",
+			Annotations: map[string]string{
+				config.AnnotationEnabled:  "true",
+				config.AnnotationProvider: "a,b",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{Host: "foo.bar.baz"},
+			},
+		},
+	}
+
+	a, b := &fake.DiffingProvider{}, &fake.DiffingProvider{}
+	a.On("Get", mock.Anything, "kube-system-foo").Return(nil, errors.New("whoops"))
+	b.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{ID: "2", Name: "kube-system-foo"}, nil)
+	b.On("Diff", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	b.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	svc := newService(map[string]provider.Interface{"a": a, "b": b}, []string{"a"}, namer, &config.Options{})
+
+	// The failure of provider a must not prevent the update of provider b.
+	_, err = svc.EnsureMonitor(context.Background(), ing)
+	require.EqualError(t, err, "provider a: whoops")
+
+	b.AssertCalled(t, "Update", mock.Anything, mock.MatchedBy(func(monitor *models.Monitor) bool {
+		return monitor.ID == "2"
+	}))
+}
+
+func TestService_DeleteMonitor_AllProviders(t *testing.T) {
+	namer, err := NewNamer("{{.Namespace}}-{{.IngressName}}")
+	require.NoError(t, err)
+
+	a, b := &fake.Provider{}, &fake.Provider{}
+	a.On("Delete", mock.Anything, "kube-system-foo").Return(models.ErrMonitorNotFound)
+	b.On("Delete", mock.Anything, "kube-system-foo").Return(nil)
+
+	svc := newService(map[string]provider.Interface{"a": a, "b": b}, []string{"a"}, namer, &config.Options{})
+
+	err = svc.DeleteMonitor(context.Background(), &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "kube-system",
+		},
+	})
+	require.NoError(t, err)
+
+	a.AssertExpectations(t)
+	b.AssertExpectations(t)
+}
+
+func TestService_Ownership(t *testing.T) {
+	namer, err := NewNamer("{{.Namespace}}-{{.IngressName}}")
+	require.NoError(t, err)
+
+	options := &config.Options{ClusterID: "prod"}
+
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "kube-system",
+			UID:       "1234",
+			Annotations: map[string]string{
+				config.AnnotationEnabled: "true",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{Host: "foo.bar.baz"},
+			},
+		},
+	}
+
+	p := &fake.Provider{}
+	p.On("Get", mock.Anything, "kube-system-foo").Return(&models.Monitor{ID: "1", Name: "kube-system-foo"}, nil)
+
+	svc := newService(map[string]provider.Interface{"fake": provider.NewGuard(p, options.ClusterID, false)}, []string{"fake"}, namer, options)
+
+	// Neither updating nor deleting a hand-made monitor is an error, but
+	// the monitor is not touched.
+	_, err = svc.EnsureMonitor(context.Background(), ing)
+	require.NoError(t, err)
+	require.NoError(t, svc.DeleteMonitor(context.Background(), ing))
+
+	p.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	p.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+
+	ing.Annotations[config.AnnotationAdopt] = "true"
+
+	p.On("Update", mock.Anything, mock.Anything).Return(nil)
+
+	_, err = svc.EnsureMonitor(context.Background(), ing)
+	require.NoError(t, err)
+
+	p.AssertCalled(t, "Update", mock.Anything, &models.Monitor{
+		ID:          "1",
+		Name:        "kube-system-foo",
+		URL:         "http://foo.bar.baz",
+		Annotations: ing.Annotations,
+		Owner: &models.Owner{
+			ClusterID: "prod",
+			Namespace: "kube-system",
+			Name:      "foo",
+			UID:       "1234",
+		},
+	})
+}
+
+func TestService_Ownership_RouteMigration(t *testing.T) {
+	namer, err := NewNamer("{{.Namespace}}-{{.IngressName}}")
+	require.NoError(t, err)
+
+	options := &config.Options{ClusterID: "prod"}
+
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "kube-system",
+			UID:       "1234",
+			Annotations: map[string]string{
+				config.AnnotationEnabled:     "true",
+				config.AnnotationMonitorName: "kube-system-foo",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{Host: "foo.bar.baz"},
+			},
+		},
+	}
+
+	route := gateway.NewHTTPRoute()
+	route.SetName("foo")
+	route.SetNamespace("kube-system")
+	route.SetUID("5678")
+	route.SetAnnotations(map[string]string{
+		config.AnnotationEnabled: "true",
+		config.AnnotationAdopt:   "true",
+	})
+	route.Object["spec"] = map[string]interface{}{
+		"hostnames": []interface{}{"foo.bar.baz"},
+	}
+
+	existing := &models.Monitor{
+		ID:    "1",
+		Name:  "kube-system-foo",
+		Owner: &models.Owner{ClusterID: "prod", Namespace: "kube-system", Name: "foo", UID: "1234"},
+	}
+
+	p := &fake.Provider{}
+	p.On("Get", mock.Anything, "kube-system-foo").Return(existing, nil)
+	p.On("Update", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		existing.Owner = args.Get(1).(*models.Monitor).Owner
+	})
+
+	svc := newService(map[string]provider.Interface{"fake": provider.NewGuard(p, options.ClusterID, false)}, []string{"fake"}, namer, options)
+	svc.client = fakeclient.NewClientBuilder().Build()
+
+	// The route takes over the monitor of the ingress it replaces.
+	_, err = svc.EnsureMonitor(context.Background(), route)
+	require.NoError(t, err)
+	require.Equal(t, "HTTPRoute", existing.Owner.Kind)
+
+	// Deleting the old ingress afterwards must leave the monitor alone.
+	require.NoError(t, svc.DeleteMonitor(context.Background(), ing))
+
+	p.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+
+	p.On("Delete", mock.Anything, "kube-system-foo").Return(nil)
+
+	require.NoError(t, svc.DeleteMonitor(context.Background(), route))
+
+	p.AssertCalled(t, "Delete", mock.Anything, "kube-system-foo")
+}
+
+func TestService_ProviderTimeout(t *testing.T) {
+	svc, p := newTestService(t, &config.Options{ProviderTimeout: time.Minute})
+
+	p.On("Delete", mock.Anything, "kube-system-foo").Return(nil)
+
+	err := svc.DeleteMonitor(context.Background(), &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "kube-system",
+		},
+	})
+	require.NoError(t, err)
+
+	ctx := p.Calls[0].Arguments.Get(0).(context.Context)
+
+	_, hasDeadline := ctx.Deadline()
+	assert.True(t, hasDeadline)
+	assert.Error(t, ctx.Err(), "context must be canceled after the call returned")
+}
+
 func newTestService(t *testing.T, options *config.Options) (*service, *fake.Provider) {
 	namer, err := NewNamer("{{.Namespace}}-{{.IngressName}}")
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	provider := &fake.Provider{}
+	p := &fake.Provider{}
+
+	svc := newService(map[string]provider.Interface{"fake": p}, []string{"fake"}, namer, options)
+
+	return svc, p
+}
+
+func TestService_LocalProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
 
-	svc := &service{
-		provider: provider,
-		namer:    namer,
-		options:  options,
+	options := config.NewDefaultOptions()
+	options.ProviderNames = []string{config.ProviderLocal}
+	options.ProviderConfig.Local.MonitorDefaults.Interval.Duration = 10 * time.Millisecond
+
+	svc, err := NewService(nil, nil, options)
+	require.NoError(t, err)
+
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "foo",
+			Namespace: "kube-system",
+			Annotations: map[string]string{
+				config.AnnotationEnabled:      "true",
+				config.AnnotationPathOverride: "/health",
+			},
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{
+				{Host: strings.TrimPrefix(server.URL, "http://")},
+			},
+		},
 	}
 
-	return svc, provider
+	_, err = svc.EnsureMonitor(context.Background(), ingress)
+	require.NoError(t, err)
+
+	probeUp := metrics.ProbeUp.WithLabelValues("kube-system-foo", server.URL+"/health")
+
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(probeUp) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	// Ensuring the monitor again does not create a duplicate.
+	_, err = svc.EnsureMonitor(context.Background(), ingress)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.DeleteMonitor(context.Background(), ingress))
+	assert.Equal(t, 0, testutil.CollectAndCount(metrics.ProbeUp))
+}
+
+type checkingProvider struct {
+	fake.Provider
+
+	err error
+}
+
+func (p *checkingProvider) Check(context.Context) error {
+	return p.err
+}
+
+func TestService_CheckProviders(t *testing.T) {
+	namer, err := NewNamer(config.DefaultNameTemplate)
+	require.NoError(t, err)
+
+	providers := map[string]provider.Interface{
+		"a": &fake.Provider{},
+		"b": &checkingProvider{},
+		"c": &checkingProvider{err: errors.New("unauthorized")},
+		"d": provider.NewGuard(&checkingProvider{err: errors.New("whoops")}, "", false),
+	}
+
+	svc := newService(providers, []string{"a"}, namer, &config.Options{})
+
+	err = svc.CheckProviders(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, "[provider c: unauthorized, provider d: whoops]", err.Error())
 }
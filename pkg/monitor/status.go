@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Reasons of the events that are recorded for ingresses and HTTPRoutes.
+const (
+	ReasonMonitorCreated     = "MonitorCreated"
+	ReasonMonitorUpdated     = "MonitorUpdated"
+	ReasonMonitorRenamed     = "MonitorRenamed"
+	ReasonMonitorDeleted     = "MonitorDeleted"
+	ReasonMonitorNotOwned    = "MonitorNotOwned"
+	ReasonValidationFailed   = "ValidationFailed"
+	ReasonInvalidAnnotation  = "InvalidAnnotation"
+	ReasonSyncFailed         = "SyncFailed"
+	ReasonDeleteFailed       = "DeleteFailed"
+	ReasonSourceRangesFailed = "SourceRangesFailed"
+)
+
+// Status is the status of the last monitor sync of an ingress or HTTPRoute. It is
+// recorded as json in the ingress-monitor.bonial.com/status annotation.
+type Status struct {
+	// MonitorID is the provider specific ID of the monitor. It is empty if
+	// the monitor is managed by multiple providers or if the ID is not known
+	// yet.
+	MonitorID string `json:"monitorID,omitempty"`
+
+	// Providers are the names of the providers that manage the monitor.
+	Providers []string `json:"providers,omitempty"`
+
+	// LastSyncTime is the time of the last sync which changed the status.
+	LastSyncTime metav1.Time `json:"lastSyncTime"`
+
+	// LastError is the error of the last sync. It is empty if the last sync
+	// succeeded.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// ParseStatus parses the status recorded in the annotations of obj. Returns
+// nil if obj does not have a valid status.
+func ParseStatus(obj metav1.Object) *Status {
+	status := &Status{}
+
+	err := json.Unmarshal([]byte(obj.GetAnnotations()[config.AnnotationStatus]), status)
+	if err != nil {
+		return nil
+	}
+
+	return status
+}
+
+// recordStatus records status in the annotations of obj. If the recorded
+// status only differs in the sync time, it is kept as is, so that obj does
+// not need to be updated after every sync.
+func recordStatus(obj metav1.Object, status *Status) {
+	if previous := ParseStatus(obj); previous != nil {
+		previous.LastSyncTime = status.LastSyncTime
+		if reflect.DeepEqual(previous, status) {
+			return
+		}
+	}
+
+	// Marshalling cannot fail as Status only contains marshalable fields.
+	buf, _ := json.Marshal(status)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+
+	annotations[config.AnnotationStatus] = string(buf)
+	obj.SetAnnotations(annotations)
+}
+
+// recordEvent records an event for obj if an event recorder is configured.
+// Objects without UID are skipped as they are only metadata stubs for
+// resources which do not exist anymore.
+func (s *service) recordEvent(obj client.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if s.recorder == nil || obj.GetUID() == "" {
+		return
+	}
+
+	s.recorder.Eventf(obj, eventType, reason, messageFmt, args...)
+}
@@ -0,0 +1,141 @@
+package blackbox
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// annotationMonitorName holds the unmodified monitor name on the Probe
+// resource as it may differ from the sanitized resource name.
+const annotationMonitorName = "blackbox.ingress-monitor.bonial.com/monitor-name"
+
+// annotationOwner marks Probe resources as owned by the object it holds. The
+// value is the query-encoded owner as returned by models.Owner.String().
+const annotationOwner = "blackbox.ingress-monitor.bonial.com/owner"
+
+// probeGVK is the GroupVersionKind of the prometheus-operator Probe resource.
+var probeGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "Probe",
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9.-]+`)
+
+type builder struct {
+	config config.BlackboxConfig
+}
+
+func newBuilder(config config.BlackboxConfig) *builder {
+	return &builder{
+		config: config,
+	}
+}
+
+func (b *builder) FromModel(model *models.Monitor) *unstructured.Unstructured {
+	anno := model.Annotations
+	defaults := b.config.MonitorDefaults
+
+	probe := newProbe()
+	probe.SetName(resourceName(model.Name))
+	probe.SetNamespace(b.config.Namespace)
+
+	annotations := map[string]string{
+		annotationMonitorName: model.Name,
+	}
+
+	if model.Owner != nil {
+		annotations[annotationOwner] = model.Owner.String()
+	}
+
+	probe.SetAnnotations(annotations)
+
+	if len(b.config.Labels) > 0 {
+		probe.SetLabels(b.config.Labels)
+	}
+
+	spec := map[string]interface{}{
+		"jobName":  model.Name,
+		"module":   anno.StringValue(config.AnnotationBlackboxModule, defaults.Module),
+		"interval": anno.StringValue(config.AnnotationBlackboxInterval, defaults.Interval),
+		"prober": map[string]interface{}{
+			"url":    b.config.ProberURL,
+			"scheme": b.config.ProberScheme,
+			"path":   b.config.ProberPath,
+		},
+		"targets": map[string]interface{}{
+			"staticConfig": map[string]interface{}{
+				"static": []interface{}{model.URL},
+				"labels": map[string]interface{}{
+					"monitor": model.Name,
+				},
+			},
+		},
+	}
+
+	scrapeTimeout := anno.StringValue(config.AnnotationBlackboxScrapeTimeout, defaults.ScrapeTimeout)
+	if scrapeTimeout != "" {
+		spec["scrapeTimeout"] = scrapeTimeout
+	}
+
+	probe.Object["spec"] = spec
+
+	return probe
+}
+
+// toModel converts a Probe resource to a *models.Monitor. The second return
+// value is false if the Probe was not created from a monitor.
+func toModel(probe *unstructured.Unstructured) (*models.Monitor, bool) {
+	annotations := probe.GetAnnotations()
+
+	name, ok := annotations[annotationMonitorName]
+	if !ok {
+		return nil, false
+	}
+
+	targets, _, _ := unstructured.NestedStringSlice(probe.Object, "spec", "targets", "staticConfig", "static")
+
+	monitor := &models.Monitor{
+		ID:    probe.GetName(),
+		Name:  name,
+		Owner: models.ParseOwner(annotations[annotationOwner]),
+	}
+
+	if len(targets) > 0 {
+		monitor.URL = targets[0]
+	}
+
+	return monitor, true
+}
+
+func newProbeList() *unstructured.UnstructuredList {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(probeGVK.GroupVersion().WithKind(probeGVK.Kind + "List"))
+
+	return list
+}
+
+func newProbe() *unstructured.Unstructured {
+	probe := &unstructured.Unstructured{}
+	probe.SetGroupVersionKind(probeGVK)
+
+	return probe
+}
+
+// resourceName converts a monitor name into a valid DNS-1123 subdomain that
+// can be used as the name of a Probe resource.
+func resourceName(monitorName string) string {
+	name := invalidNameChars.ReplaceAllString(strings.ToLower(monitorName), "-")
+
+	if len(name) > validation.DNS1123SubdomainMaxLength {
+		name = name[:validation.DNS1123SubdomainMaxLength]
+	}
+
+	return strings.Trim(name, ".-")
+}
@@ -0,0 +1,128 @@
+package blackbox
+
+import (
+	"context"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fieldOwner is the field manager used for server-side applies.
+const fieldOwner = "ingress-monitor-controller"
+
+// Provider manages prometheus-operator Probe resources which are probed by a
+// blackbox exporter running in the cluster. This allows monitoring of
+// ingresses which are not reachable from the outside.
+type Provider struct {
+	client  client.Client
+	config  config.BlackboxConfig
+	builder *builder
+}
+
+// NewProvider creates a new blackbox provider which uses client to manage
+// Probe resources.
+func NewProvider(client client.Client, config config.BlackboxConfig) *Provider {
+	return &Provider{
+		client:  client,
+		config:  config,
+		builder: newBuilder(config),
+	}
+}
+
+// Create implements provider.Interface.
+func (p *Provider) Create(ctx context.Context, model *models.Monitor) error {
+	return p.apply(ctx, model)
+}
+
+// Get implements provider.Interface.
+func (p *Provider) Get(ctx context.Context, name string) (*models.Monitor, error) {
+	probe := newProbe()
+	key := client.ObjectKey{Namespace: p.config.Namespace, Name: resourceName(name)}
+
+	err := p.client.Get(ctx, key, probe)
+	if apierrors.IsNotFound(err) {
+		return nil, models.ErrMonitorNotFound
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to get probe %s", key)
+	}
+
+	monitor, ok := toModel(probe)
+	if !ok || monitor.Name != name {
+		// The probe was not created by us or the sanitized names of two
+		// monitors collide.
+		return nil, models.ErrMonitorNotFound
+	}
+
+	return monitor, nil
+}
+
+// Update implements provider.Interface.
+func (p *Provider) Update(ctx context.Context, model *models.Monitor) error {
+	return p.apply(ctx, model)
+}
+
+// Delete implements provider.Interface.
+func (p *Provider) Delete(ctx context.Context, name string) error {
+	monitor, err := p.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	probe := newProbe()
+	probe.SetNamespace(p.config.Namespace)
+	probe.SetName(monitor.ID)
+
+	err = p.client.Delete(ctx, probe)
+	if apierrors.IsNotFound(err) {
+		return models.ErrMonitorNotFound
+	} else if err != nil {
+		return errors.Wrapf(err, "failed to delete probe %s/%s", probe.GetNamespace(), probe.GetName())
+	}
+
+	return nil
+}
+
+// List implements provider.Interface.
+func (p *Provider) List(ctx context.Context) ([]*models.Monitor, error) {
+	list := newProbeList()
+
+	err := p.client.List(ctx, list, client.InNamespace(p.config.Namespace))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list probes in namespace %s", p.config.Namespace)
+	}
+
+	monitors := make([]*models.Monitor, 0, len(list.Items))
+
+	for i := range list.Items {
+		monitor, ok := toModel(&list.Items[i])
+		if !ok || monitor.Owner == nil {
+			continue
+		}
+
+		monitors = append(monitors, monitor)
+	}
+
+	return monitors, nil
+}
+
+// GetIPSourceRanges implements provider.Interface. It returns the configured
+// source ranges of the blackbox exporter.
+func (p *Provider) GetIPSourceRanges(ctx context.Context, _ *models.Monitor) ([]string, error) {
+	return p.config.SourceRanges, nil
+}
+
+// apply creates or updates the Probe resource for model using server-side
+// apply.
+func (p *Provider) apply(ctx context.Context, model *models.Monitor) error {
+	probe := p.builder.FromModel(model)
+
+	err := p.client.Patch(ctx, probe, client.Apply, client.FieldOwner(fieldOwner), client.ForceOwnership)
+	if err != nil {
+		return errors.Wrapf(err, "failed to apply probe %s/%s", probe.GetNamespace(), probe.GetName())
+	}
+
+	return nil
+}
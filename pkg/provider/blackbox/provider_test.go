@@ -0,0 +1,271 @@
+package blackbox
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+var testConfig = config.BlackboxConfig{
+	Namespace:    "monitoring",
+	Labels:       map[string]string{"release": "prometheus"},
+	ProberURL:    "blackbox-exporter:9115",
+	ProberScheme: "http",
+	ProberPath:   "/probe",
+	SourceRanges: []string{"10.0.0.0/8"},
+	MonitorDefaults: config.BlackboxMonitorDefaults{
+		Interval: "60s",
+		Module:   "http_2xx",
+	},
+}
+
+func TestProvider_Create(t *testing.T) {
+	p, c := newTestProvider()
+
+	err := p.Create(context.Background(), &models.Monitor{
+		Name: "kube-system-Foo",
+		URL:  "https://foo.bar.baz",
+		Annotations: config.Annotations{
+			config.AnnotationBlackboxModule:        "http_post_2xx",
+			config.AnnotationBlackboxScrapeTimeout: "5s",
+		},
+	})
+	require.NoError(t, err)
+
+	probe := getProbe(t, c, "kube-system-foo")
+
+	assert.Equal(t, map[string]string{"release": "prometheus"}, probe.GetLabels())
+	assert.Equal(t, map[string]string{annotationMonitorName: "kube-system-Foo"}, probe.GetAnnotations())
+	assert.Equal(t, map[string]interface{}{
+		"jobName":       "kube-system-Foo",
+		"module":        "http_post_2xx",
+		"interval":      "60s",
+		"scrapeTimeout": "5s",
+		"prober": map[string]interface{}{
+			"url":    "blackbox-exporter:9115",
+			"scheme": "http",
+			"path":   "/probe",
+		},
+		"targets": map[string]interface{}{
+			"staticConfig": map[string]interface{}{
+				"static": []interface{}{"https://foo.bar.baz"},
+				"labels": map[string]interface{}{
+					"monitor": "kube-system-Foo",
+				},
+			},
+		},
+	}, probe.Object["spec"])
+}
+
+func TestProvider_Update(t *testing.T) {
+	p, c := newTestProvider()
+
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{
+		Name: "kube-system-foo",
+		URL:  "https://foo.bar.baz",
+	}))
+
+	require.NoError(t, p.Update(context.Background(), &models.Monitor{
+		ID:   "kube-system-foo",
+		Name: "kube-system-foo",
+		URL:  "https://foo.bar.baz/health",
+		Annotations: config.Annotations{
+			config.AnnotationBlackboxInterval: "30s",
+		},
+	}))
+
+	probe := getProbe(t, c, "kube-system-foo")
+
+	targets, _, _ := unstructured.NestedStringSlice(probe.Object, "spec", "targets", "staticConfig", "static")
+	assert.Equal(t, []string{"https://foo.bar.baz/health"}, targets)
+
+	interval, _, _ := unstructured.NestedString(probe.Object, "spec", "interval")
+	assert.Equal(t, "30s", interval)
+}
+
+func TestProvider_Get(t *testing.T) {
+	tests := []struct {
+		name        string
+		monitorName string
+		objects     []client.Object
+		expected    *models.Monitor
+		expectedErr error
+	}{
+		{
+			name:        "returns models.ErrMonitorNotFound if probe does not exist",
+			monitorName: "kube-system-foo",
+			expectedErr: models.ErrMonitorNotFound,
+		},
+		{
+			name:        "ignores probes which were not created by the provider",
+			monitorName: "kube-system-foo",
+			objects: []client.Object{
+				probeFixture("kube-system-foo", nil, "https://foo.bar.baz"),
+			},
+			expectedErr: models.ErrMonitorNotFound,
+		},
+		{
+			name:        "ignores probes whose sanitized name collides",
+			monitorName: "kube-system-foo",
+			objects: []client.Object{
+				probeFixture("kube-system-foo", map[string]string{annotationMonitorName: "kube-system-Foo"}, "https://foo.bar.baz"),
+			},
+			expectedErr: models.ErrMonitorNotFound,
+		},
+		{
+			name:        "returns monitor",
+			monitorName: "kube-system-Foo",
+			objects: []client.Object{
+				probeFixture("kube-system-foo", map[string]string{annotationMonitorName: "kube-system-Foo"}, "https://foo.bar.baz"),
+			},
+			expected: &models.Monitor{
+				ID:   "kube-system-foo",
+				Name: "kube-system-Foo",
+				URL:  "https://foo.bar.baz",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, _ := newTestProvider(test.objects...)
+
+			monitor, err := p.Get(context.Background(), test.monitorName)
+			if test.expectedErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, test.expectedErr.Error(), err.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, monitor)
+			}
+		})
+	}
+}
+
+func TestProvider_Delete(t *testing.T) {
+	p, c := newTestProvider(
+		probeFixture("kube-system-foo", map[string]string{annotationMonitorName: "kube-system-foo"}, "https://foo.bar.baz"),
+	)
+
+	require.NoError(t, p.Delete(context.Background(), "kube-system-foo"))
+
+	err := c.Get(context.Background(), types.NamespacedName{Namespace: "monitoring", Name: "kube-system-foo"}, newProbe())
+	require.True(t, apierrors.IsNotFound(err))
+
+	require.Equal(t, models.ErrMonitorNotFound, p.Delete(context.Background(), "kube-system-foo"))
+}
+
+func TestProvider_List(t *testing.T) {
+	p, _ := newTestProvider(
+		probeFixture("kube-system-foo", map[string]string{annotationMonitorName: "kube-system-foo", annotationOwner: "cluster=&name=foo&namespace=kube-system&uid="}, "https://foo.bar.baz"),
+		probeFixture("kube-system-bar", map[string]string{annotationMonitorName: "kube-system-bar"}, "https://bar.baz"),
+		probeFixture("unmanaged", nil, "https://baz"),
+	)
+
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{
+		Name:  "default-qux",
+		URL:   "https://qux",
+		Owner: &models.Owner{Namespace: "default", Name: "qux"},
+	}))
+
+	monitors, err := p.List(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*models.Monitor{
+		{
+			ID:    "kube-system-foo",
+			Name:  "kube-system-foo",
+			URL:   "https://foo.bar.baz",
+			Owner: &models.Owner{Namespace: "kube-system", Name: "foo"},
+		},
+		{
+			ID:    "default-qux",
+			Name:  "default-qux",
+			URL:   "https://qux",
+			Owner: &models.Owner{Namespace: "default", Name: "qux"},
+		},
+	}, monitors)
+}
+
+func TestProvider_GetIPSourceRanges(t *testing.T) {
+	p, _ := newTestProvider()
+
+	sourceRanges, err := p.GetIPSourceRanges(context.Background(), &models.Monitor{Name: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8"}, sourceRanges)
+}
+
+func TestResourceName(t *testing.T) {
+	assert.Equal(t, "kube-system-foo", resourceName("kube-system-foo"))
+	assert.Equal(t, "my-ns-my-ingress-https-foo.bar", resourceName("My NS/my_ingress (https://foo.bar)"))
+}
+
+func probeFixture(name string, annotations map[string]string, target string) *unstructured.Unstructured {
+	probe := newProbe()
+	probe.SetNamespace("monitoring")
+	probe.SetName(name)
+	probe.SetAnnotations(annotations)
+	probe.Object["spec"] = map[string]interface{}{
+		"targets": map[string]interface{}{
+			"staticConfig": map[string]interface{}{
+				"static": []interface{}{target},
+			},
+		},
+	}
+
+	return probe
+}
+
+func getProbe(t *testing.T, c client.Client, name string) *unstructured.Unstructured {
+	probe := newProbe()
+
+	err := c.Get(context.Background(), types.NamespacedName{Namespace: "monitoring", Name: name}, probe)
+	require.NoError(t, err)
+
+	return probe
+}
+
+func newTestProvider(objects ...client.Object) (*Provider, client.Client) {
+	scheme := runtime.NewScheme()
+	scheme.AddKnownTypeWithName(probeGVK, &unstructured.Unstructured{})
+	scheme.AddKnownTypeWithName(probeGVK.GroupVersion().WithKind(probeGVK.Kind+"List"), &unstructured.UnstructuredList{})
+
+	c := fakeclient.NewClientBuilder().
+		WithScheme(scheme).
+		WithObjects(objects...).
+		WithInterceptorFuncs(interceptor.Funcs{Patch: applyPatch}).
+		Build()
+
+	return NewProvider(c, testConfig), c
+}
+
+// applyPatch emulates server-side apply as it is not supported by the fake
+// client. The applied object fully replaces the existing object.
+func applyPatch(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.Patch(ctx, obj, patch, opts...)
+	}
+
+	existing := newProbe()
+
+	err := c.Get(ctx, client.ObjectKeyFromObject(obj), existing)
+	if apierrors.IsNotFound(err) {
+		return c.Create(ctx, obj)
+	} else if err != nil {
+		return err
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+
+	return c.Update(ctx, obj)
+}
@@ -0,0 +1,289 @@
+package provider
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/metrics"
+	"github.com/pkg/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("composite-provider")
+
+// Composite fans out monitor operations to multiple providers. A failure of
+// one provider does not prevent the operation from being performed by the
+// other providers, instead all failures are aggregated into a single error.
+type Composite struct {
+	names     []string
+	providers map[string]Interface
+}
+
+// NewComposite creates a new *Composite for the named providers. Operations
+// are performed in the lexical order of the provider names.
+func NewComposite(providers map[string]Interface) *Composite {
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return &Composite{
+		names:     names,
+		providers: providers,
+	}
+}
+
+// Create implements Interface. The monitor is created in every provider it
+// does not exist in yet and updated in all others.
+func (c *Composite) Create(ctx context.Context, model *models.Monitor) error {
+	return c.ensure(ctx, "create", model)
+}
+
+// Get implements Interface. It returns the monitor of the first provider that
+// has it. Since the monitor IDs are provider specific, Create and Update
+// ignore the ID of the monitor returned by Get. Failures of single providers
+// are only logged and recorded, so that a monitor which no healthy provider
+// has yet is reported as models.ErrMonitorNotFound. Create then creates it in
+// the healthy providers and reports the failing ones.
+func (c *Composite) Get(ctx context.Context, name string) (*models.Monitor, error) {
+	for _, providerName := range c.names {
+		monitor, err := c.providers[providerName].Get(ctx, name)
+		if err == nil {
+			return monitor, nil
+		}
+
+		if err != models.ErrMonitorNotFound {
+			_ = c.handleError(providerName, "get", err)
+		}
+	}
+
+	return nil, models.ErrMonitorNotFound
+}
+
+// Update implements Interface. The monitor is updated in every provider it
+// exists in and created in all others.
+func (c *Composite) Update(ctx context.Context, model *models.Monitor) error {
+	return c.ensure(ctx, "update", model)
+}
+
+// Rename implements Renamer. Returns models.ErrMonitorNotFound only if the
+// monitor oldName does not exist in any of the providers. Providers that do
+// not have the monitor oldName get the monitor created or updated under the
+// new name instead. Monitors which are not owned by the controller instance
+// are skipped.
+func (c *Composite) Rename(ctx context.Context, oldName string, model *models.Monitor) error {
+	_, err := c.Get(ctx, oldName)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, providerName := range c.names {
+		p := c.providers[providerName]
+
+		err := Rename(ctx, p, oldName, model)
+		if err == models.ErrMonitorNotFound {
+			err = ensureMonitor(ctx, p, model)
+		}
+
+		if IsNotOwned(err) {
+			log.Info("skipping rename of monitor not owned by this controller instance", "provider", providerName, "monitor", oldName)
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, c.handleError(providerName, "rename", err))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// Diff implements Differ. Since existing only holds the monitor of one
+// provider, the monitor is looked up and compared in every provider. The
+// fields of the result are prefixed with the provider name. A provider that
+// does not have the monitor yet is reported as drift. Providers failing to
+// look up or compare the monitor are reported as drift as well, so that the
+// monitor is still updated in the healthy providers. Returns
+// models.ErrDiffNotSupported if any of the providers does not support drift
+// detection.
+func (c *Composite) Diff(ctx context.Context, _ *models.Monitor, model *models.Monitor) ([]models.FieldDiff, error) {
+	var diffs []models.FieldDiff
+
+	for _, providerName := range c.names {
+		p := c.providers[providerName]
+
+		existing, err := p.Get(ctx, model.Name)
+		if err == models.ErrMonitorNotFound {
+			diffs = append(diffs, models.FieldDiff{Field: providerName, Current: "absent", Desired: "present"})
+			continue
+		} else if err != nil {
+			_ = c.handleError(providerName, "diff", err)
+			diffs = append(diffs, models.FieldDiff{Field: providerName, Current: "unknown", Desired: "present"})
+			continue
+		}
+
+		providerDiffs, err := Diff(ctx, p, existing, model)
+		if err == models.ErrDiffNotSupported {
+			return nil, err
+		} else if err != nil {
+			_ = c.handleError(providerName, "diff", err)
+			diffs = append(diffs, models.FieldDiff{Field: providerName, Current: "unknown", Desired: "present"})
+			continue
+		}
+
+		for _, diff := range providerDiffs {
+			diff.Field = providerName + "." + diff.Field
+			diffs = append(diffs, diff)
+		}
+	}
+
+	return diffs, nil
+}
+
+// Delete implements Interface. Returns models.ErrMonitorNotFound only if the
+// monitor does not exist in any of the providers. Monitors which are not
+// owned by the controller instance are skipped.
+func (c *Composite) Delete(ctx context.Context, name string) error {
+	return c.DeleteOwned(ctx, name, nil)
+}
+
+// DeleteOwned implements OwnerAwareDeleter. It behaves like Delete, but
+// monitors which are owned by a resource other than owner are skipped as
+// well.
+func (c *Composite) DeleteOwned(ctx context.Context, name string, owner *models.Owner) error {
+	var errs []error
+
+	found := false
+
+	for _, providerName := range c.names {
+		err := DeleteOwned(ctx, c.providers[providerName], name, owner)
+		if err == models.ErrMonitorNotFound {
+			continue
+		}
+
+		if IsNotOwned(err) {
+			log.Info("skipping deletion of monitor not owned by this controller instance", "provider", providerName, "monitor", name)
+			continue
+		}
+
+		found = true
+
+		if err != nil {
+			errs = append(errs, c.handleError(providerName, "delete", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+
+	if !found {
+		return models.ErrMonitorNotFound
+	}
+
+	return nil
+}
+
+// List implements Interface. It returns the monitors of all providers. A
+// monitor that exists in multiple providers is returned once per provider.
+func (c *Composite) List(ctx context.Context) ([]*models.Monitor, error) {
+	var errs []error
+
+	monitors := []*models.Monitor{}
+
+	for _, providerName := range c.names {
+		list, err := c.providers[providerName].List(ctx)
+		if err != nil {
+			errs = append(errs, c.handleError(providerName, "list", err))
+			continue
+		}
+
+		monitors = append(monitors, list...)
+	}
+
+	if len(errs) > 0 {
+		return monitors, utilerrors.NewAggregate(errs)
+	}
+
+	return monitors, nil
+}
+
+// GetIPSourceRanges implements Interface. It returns the union of the source
+// ranges of all providers.
+func (c *Composite) GetIPSourceRanges(ctx context.Context, model *models.Monitor) ([]string, error) {
+	var errs []error
+
+	seen := make(map[string]struct{})
+	sourceRanges := []string{}
+
+	for _, providerName := range c.names {
+		ranges, err := c.providers[providerName].GetIPSourceRanges(ctx, model)
+		if err != nil {
+			errs = append(errs, c.handleError(providerName, "get-ip-source-ranges", err))
+			continue
+		}
+
+		for _, sourceRange := range ranges {
+			if _, found := seen[sourceRange]; !found {
+				seen[sourceRange] = struct{}{}
+				sourceRanges = append(sourceRanges, sourceRange)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return sourceRanges, utilerrors.NewAggregate(errs)
+	}
+
+	return sourceRanges, nil
+}
+
+// ensure creates or updates the monitor in every provider. The provider
+// specific monitor ID is looked up before updating. Monitors which are not
+// owned by the controller instance are skipped.
+func (c *Composite) ensure(ctx context.Context, operation string, model *models.Monitor) error {
+	var errs []error
+
+	for _, providerName := range c.names {
+		err := ensureMonitor(ctx, c.providers[providerName], model)
+		if IsNotOwned(err) {
+			log.Info("skipping update of monitor not owned by this controller instance", "provider", providerName, "monitor", model.Name)
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, c.handleError(providerName, operation, err))
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+func (c *Composite) handleError(providerName, operation string, err error) error {
+	metrics.ProviderErrorsTotal.WithLabelValues(providerName, operation).Inc()
+	log.Error(err, "provider operation failed", "provider", providerName, "operation", operation)
+
+	return errors.Wrapf(err, "provider %s", providerName)
+}
+
+func ensureMonitor(ctx context.Context, p Interface, model *models.Monitor) error {
+	monitor := *model
+
+	existing, err := p.Get(ctx, model.Name)
+	if err == models.ErrMonitorNotFound {
+		monitor.ID = ""
+		return p.Create(ctx, &monitor)
+	} else if err != nil {
+		return err
+	}
+
+	monitor.ID = existing.ID
+
+	return p.Update(ctx, &monitor)
+}
@@ -0,0 +1,397 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/fake"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComposite_Create(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(a, b *fake.Provider)
+		expectedErr string
+	}{
+		{
+			name: "creates monitor in all providers",
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+				a.On("Create", mock.Anything, &models.Monitor{Name: "foo", URL: "http://foo"}).Return(nil)
+				b.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+				b.On("Create", mock.Anything, &models.Monitor{Name: "foo", URL: "http://foo"}).Return(nil)
+			},
+		},
+		{
+			name: "updates monitor in providers where it already exists",
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+				a.On("Create", mock.Anything, &models.Monitor{Name: "foo", URL: "http://foo"}).Return(nil)
+				b.On("Get", mock.Anything, "foo").Return(&models.Monitor{ID: "42", Name: "foo"}, nil)
+				b.On("Update", mock.Anything, &models.Monitor{ID: "42", Name: "foo", URL: "http://foo"}).Return(nil)
+			},
+		},
+		{
+			name: "failure of one provider does not block the others",
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+				a.On("Create", mock.Anything, mock.Anything).Return(errors.New("whoops"))
+				b.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+				b.On("Create", mock.Anything, &models.Monitor{Name: "foo", URL: "http://foo"}).Return(nil)
+			},
+			expectedErr: "provider a: whoops",
+		},
+		{
+			name: "aggregates errors of all providers",
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "foo").Return(nil, errors.New("whoops"))
+				b.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+				b.On("Create", mock.Anything, mock.Anything).Return(errors.New("oops"))
+			},
+			expectedErr: "[provider a: whoops, provider b: oops]",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := &fake.Provider{}, &fake.Provider{}
+			test.setup(a, b)
+
+			c := NewComposite(map[string]Interface{"b": b, "a": a})
+
+			err := c.Create(context.Background(), &models.Monitor{Name: "foo", URL: "http://foo"})
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.expectedErr, err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+
+			a.AssertExpectations(t)
+			b.AssertExpectations(t)
+		})
+	}
+}
+
+func TestComposite_Get(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(a, b *fake.Provider)
+		expected    *models.Monitor
+		expectedErr error
+	}{
+		{
+			name: "returns monitor of first provider that has it",
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+				b.On("Get", mock.Anything, "foo").Return(&models.Monitor{ID: "42", Name: "foo"}, nil)
+			},
+			expected: &models.Monitor{ID: "42", Name: "foo"},
+		},
+		{
+			name: "returns ErrMonitorNotFound if no provider has it",
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+				b.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+			},
+			expectedErr: models.ErrMonitorNotFound,
+		},
+		{
+			name: "ignores failing providers",
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "foo").Return(nil, errors.New("whoops"))
+				b.On("Get", mock.Anything, "foo").Return(&models.Monitor{ID: "42", Name: "foo"}, nil)
+			},
+			expected: &models.Monitor{ID: "42", Name: "foo"},
+		},
+		{
+			name: "returns ErrMonitorNotFound if no healthy provider has it",
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "foo").Return(nil, errors.New("whoops"))
+				b.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+			},
+			expectedErr: models.ErrMonitorNotFound,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := &fake.Provider{}, &fake.Provider{}
+			test.setup(a, b)
+
+			c := NewComposite(map[string]Interface{"a": a, "b": b})
+
+			monitor, err := c.Get(context.Background(), "foo")
+			if test.expectedErr != nil {
+				require.Equal(t, test.expectedErr, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, monitor)
+			}
+		})
+	}
+}
+
+func TestComposite_Delete(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(a, b *fake.Provider)
+		expectedErr string
+	}{
+		{
+			name: "deletes monitor in all providers",
+			setup: func(a, b *fake.Provider) {
+				a.On("Delete", mock.Anything, "foo").Return(nil)
+				b.On("Delete", mock.Anything, "foo").Return(nil)
+			},
+		},
+		{
+			name: "ignores providers without the monitor",
+			setup: func(a, b *fake.Provider) {
+				a.On("Delete", mock.Anything, "foo").Return(models.ErrMonitorNotFound)
+				b.On("Delete", mock.Anything, "foo").Return(nil)
+			},
+		},
+		{
+			name: "returns ErrMonitorNotFound if no provider has the monitor",
+			setup: func(a, b *fake.Provider) {
+				a.On("Delete", mock.Anything, "foo").Return(models.ErrMonitorNotFound)
+				b.On("Delete", mock.Anything, "foo").Return(models.ErrMonitorNotFound)
+			},
+			expectedErr: models.ErrMonitorNotFound.Error(),
+		},
+		{
+			name: "skips monitors which are not owned",
+			setup: func(a, b *fake.Provider) {
+				a.On("Delete", mock.Anything, "foo").Return(pkgerrors.Wrap(models.ErrMonitorNotOwned, "refusing to delete"))
+				b.On("Delete", mock.Anything, "foo").Return(nil)
+			},
+		},
+		{
+			name: "failure of one provider does not block the others",
+			setup: func(a, b *fake.Provider) {
+				a.On("Delete", mock.Anything, "foo").Return(errors.New("whoops"))
+				b.On("Delete", mock.Anything, "foo").Return(nil)
+			},
+			expectedErr: "provider a: whoops",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := &fake.Provider{}, &fake.Provider{}
+			test.setup(a, b)
+
+			c := NewComposite(map[string]Interface{"a": a, "b": b})
+
+			err := c.Delete(context.Background(), "foo")
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.expectedErr, err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+
+			a.AssertExpectations(t)
+			b.AssertExpectations(t)
+		})
+	}
+}
+
+func TestComposite_Rename(t *testing.T) {
+	tests := []struct {
+		name        string
+		setup       func(a, b *fake.Provider)
+		expectedErr string
+	}{
+		{
+			name: "renames monitor in all providers",
+			setup: func(a, b *fake.Provider) {
+				for _, p := range []*fake.Provider{a, b} {
+					p.On("Get", mock.Anything, "bar").Return(&models.Monitor{ID: "42", Name: "bar"}, nil)
+					p.On("Create", mock.Anything, &models.Monitor{Name: "foo", URL: "http://foo"}).Return(nil)
+					p.On("Delete", mock.Anything, "bar").Return(nil)
+				}
+			},
+		},
+		{
+			name: "creates monitor in providers without the old monitor",
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "bar").Return(nil, models.ErrMonitorNotFound)
+				a.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+				a.On("Create", mock.Anything, &models.Monitor{Name: "foo", URL: "http://foo"}).Return(nil)
+				b.On("Get", mock.Anything, "bar").Return(&models.Monitor{ID: "42", Name: "bar"}, nil)
+				b.On("Create", mock.Anything, &models.Monitor{Name: "foo", URL: "http://foo"}).Return(nil)
+				b.On("Delete", mock.Anything, "bar").Return(nil)
+			},
+		},
+		{
+			name: "returns ErrMonitorNotFound if no provider has the old monitor",
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "bar").Return(nil, models.ErrMonitorNotFound)
+				b.On("Get", mock.Anything, "bar").Return(nil, models.ErrMonitorNotFound)
+			},
+			expectedErr: models.ErrMonitorNotFound.Error(),
+		},
+		{
+			name: "failure of one provider does not block the others",
+			setup: func(a, b *fake.Provider) {
+				a.On("Get", mock.Anything, "bar").Return(&models.Monitor{ID: "42", Name: "bar"}, nil)
+				a.On("Create", mock.Anything, mock.Anything).Return(errors.New("whoops"))
+				b.On("Get", mock.Anything, "bar").Return(&models.Monitor{ID: "42", Name: "bar"}, nil)
+				b.On("Create", mock.Anything, &models.Monitor{Name: "foo", URL: "http://foo"}).Return(nil)
+				b.On("Delete", mock.Anything, "bar").Return(nil)
+			},
+			expectedErr: "provider a: whoops",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := &fake.Provider{}, &fake.Provider{}
+			test.setup(a, b)
+
+			c := NewComposite(map[string]Interface{"a": a, "b": b})
+
+			err := c.Rename(context.Background(), "bar", &models.Monitor{Name: "foo", URL: "http://foo"})
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.expectedErr, err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+
+			a.AssertExpectations(t)
+			b.AssertExpectations(t)
+		})
+	}
+}
+
+func TestComposite_Diff(t *testing.T) {
+	model := &models.Monitor{Name: "foo", URL: "http://foo"}
+
+	tests := []struct {
+		name        string
+		setup       func(a, b *fake.DiffingProvider)
+		expected    []models.FieldDiff
+		expectedErr error
+	}{
+		{
+			name: "monitor is up to date in all providers",
+			setup: func(a, b *fake.DiffingProvider) {
+				a.On("Get", mock.Anything, "foo").Return(&models.Monitor{ID: "1", Name: "foo"}, nil)
+				a.On("Diff", mock.Anything, &models.Monitor{ID: "1", Name: "foo"}, model).Return(nil, nil)
+				b.On("Get", mock.Anything, "foo").Return(&models.Monitor{ID: "2", Name: "foo"}, nil)
+				b.On("Diff", mock.Anything, &models.Monitor{ID: "2", Name: "foo"}, model).Return(nil, nil)
+			},
+		},
+		{
+			name: "diffs are prefixed with the provider name",
+			setup: func(a, b *fake.DiffingProvider) {
+				a.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+				b.On("Get", mock.Anything, "foo").Return(&models.Monitor{ID: "2", Name: "foo"}, nil)
+				b.On("Diff", mock.Anything, mock.Anything, model).Return([]models.FieldDiff{{Field: "Timeout", Current: 10, Desired: 30}}, nil)
+			},
+			expected: []models.FieldDiff{
+				{Field: "a", Current: "absent", Desired: "present"},
+				{Field: "b.Timeout", Current: 10, Desired: 30},
+			},
+		},
+		{
+			name: "drift detection not supported by one provider",
+			setup: func(a, b *fake.DiffingProvider) {
+				a.On("Get", mock.Anything, "foo").Return(&models.Monitor{ID: "1", Name: "foo"}, nil)
+				a.On("Diff", mock.Anything, mock.Anything, model).Return(nil, models.ErrDiffNotSupported)
+			},
+			expectedErr: models.ErrDiffNotSupported,
+		},
+		{
+			name: "failing providers are reported as drift",
+			setup: func(a, b *fake.DiffingProvider) {
+				a.On("Get", mock.Anything, "foo").Return(nil, errors.New("whoops"))
+				b.On("Get", mock.Anything, "foo").Return(&models.Monitor{ID: "2", Name: "foo"}, nil)
+				b.On("Diff", mock.Anything, mock.Anything, model).Return(nil, errors.New("oops"))
+			},
+			expected: []models.FieldDiff{
+				{Field: "a", Current: "unknown", Desired: "present"},
+				{Field: "b", Current: "unknown", Desired: "present"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a, b := &fake.DiffingProvider{}, &fake.DiffingProvider{}
+			test.setup(a, b)
+
+			c := NewComposite(map[string]Interface{"b": b, "a": a})
+
+			diffs, err := c.Diff(context.Background(), nil, model)
+			if test.expectedErr != nil {
+				require.Equal(t, test.expectedErr, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, diffs)
+			}
+
+			a.AssertExpectations(t)
+			b.AssertExpectations(t)
+		})
+	}
+}
+
+func TestDiff_NotSupported(t *testing.T) {
+	_, err := Diff(context.Background(), &fake.Provider{}, &models.Monitor{}, &models.Monitor{})
+	require.Equal(t, models.ErrDiffNotSupported, err)
+
+	_, err = Diff(context.Background(), NewGuard(&fake.Provider{}, "", false), &models.Monitor{}, &models.Monitor{})
+	require.Equal(t, models.ErrDiffNotSupported, err)
+}
+
+func TestComposite_GetIPSourceRanges(t *testing.T) {
+	a, b := &fake.Provider{}, &fake.Provider{}
+	model := &models.Monitor{Name: "foo"}
+
+	a.On("GetIPSourceRanges", mock.Anything, model).Return([]string{"1.2.3.4/32", "10.0.0.0/8"}, nil)
+	b.On("GetIPSourceRanges", mock.Anything, model).Return([]string{"10.0.0.0/8", "5.6.7.8/32"}, nil)
+
+	c := NewComposite(map[string]Interface{"a": a, "b": b})
+
+	sourceRanges, err := c.GetIPSourceRanges(context.Background(), model)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1.2.3.4/32", "10.0.0.0/8", "5.6.7.8/32"}, sourceRanges)
+}
+
+func TestComposite_GetIPSourceRanges_PartialFailure(t *testing.T) {
+	a, b := &fake.Provider{}, &fake.Provider{}
+	model := &models.Monitor{Name: "foo"}
+
+	a.On("GetIPSourceRanges", mock.Anything, model).Return(nil, errors.New("whoops"))
+	b.On("GetIPSourceRanges", mock.Anything, model).Return([]string{"5.6.7.8/32"}, nil)
+
+	c := NewComposite(map[string]Interface{"a": a, "b": b})
+
+	sourceRanges, err := c.GetIPSourceRanges(context.Background(), model)
+	require.EqualError(t, err, "provider a: whoops")
+	assert.Equal(t, []string{"5.6.7.8/32"}, sourceRanges)
+}
+
+func TestComposite_List(t *testing.T) {
+	a, b := &fake.Provider{}, &fake.Provider{}
+	owner := &models.Owner{Namespace: "kube-system", Name: "foo"}
+
+	a.On("List", mock.Anything).Return([]*models.Monitor{{Name: "foo", Owner: owner}}, nil)
+	b.On("List", mock.Anything).Return(nil, errors.New("whoops"))
+
+	c := NewComposite(map[string]Interface{"a": a, "b": b})
+
+	monitors, err := c.List(context.Background())
+	require.EqualError(t, err, "provider b: whoops")
+	assert.Equal(t, []*models.Monitor{{Name: "foo", Owner: owner}}, monitors)
+}
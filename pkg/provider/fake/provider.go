@@ -1,6 +1,8 @@
 package fake
 
 import (
+	"context"
+
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
 	"github.com/stretchr/testify/mock"
 )
@@ -11,15 +13,15 @@ type Provider struct {
 }
 
 // Create implements provider.Interface.
-func (p *Provider) Create(model *models.Monitor) error {
-	args := p.Called(model)
+func (p *Provider) Create(ctx context.Context, model *models.Monitor) error {
+	args := p.Called(ctx, model)
 
 	return args.Error(0)
 }
 
 // Create implements provider.Interface.
-func (p *Provider) Get(name string) (*models.Monitor, error) {
-	args := p.Called(name)
+func (p *Provider) Get(ctx context.Context, name string) (*models.Monitor, error) {
+	args := p.Called(ctx, name)
 	if obj, ok := args.Get(0).(*models.Monitor); ok {
 		return obj, args.Error(1)
 	}
@@ -28,25 +30,50 @@ func (p *Provider) Get(name string) (*models.Monitor, error) {
 }
 
 // Create implements provider.Interface.
-func (p *Provider) Update(model *models.Monitor) error {
-	args := p.Called(model)
+func (p *Provider) Update(ctx context.Context, model *models.Monitor) error {
+	args := p.Called(ctx, model)
 
 	return args.Error(0)
 }
 
 // Create implements provider.Interface.
-func (p *Provider) Delete(name string) error {
-	args := p.Called(name)
+func (p *Provider) Delete(ctx context.Context, name string) error {
+	args := p.Called(ctx, name)
 
 	return args.Error(0)
 }
 
+// List implements provider.Interface.
+func (p *Provider) List(ctx context.Context) ([]*models.Monitor, error) {
+	args := p.Called(ctx)
+	if obj, ok := args.Get(0).([]*models.Monitor); ok {
+		return obj, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
+
 // GetIPSourceRanges implements provider.Interface.
-func (p *Provider) GetIPSourceRanges(model *models.Monitor) ([]string, error) {
-	args := p.Called(model)
+func (p *Provider) GetIPSourceRanges(ctx context.Context, model *models.Monitor) ([]string, error) {
+	args := p.Called(ctx, model)
 	if obj, ok := args.Get(0).([]string); ok {
 		return obj, args.Error(1)
 	}
 
 	return nil, args.Error(1)
 }
+
+// DiffingProvider is a fake provider that supports drift detection.
+type DiffingProvider struct {
+	Provider
+}
+
+// Diff implements provider.Differ.
+func (p *DiffingProvider) Diff(ctx context.Context, existing, model *models.Monitor) ([]models.FieldDiff, error) {
+	args := p.Called(ctx, existing, model)
+	if obj, ok := args.Get(0).([]models.FieldDiff); ok {
+		return obj, args.Error(1)
+	}
+
+	return nil, args.Error(1)
+}
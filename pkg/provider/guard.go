@@ -0,0 +1,139 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/pkg/errors"
+)
+
+// Guard wraps a provider and refuses to modify monitors which are not owned
+// by the controller instance. This prevents the controller from taking over
+// or deleting monitors which were created manually or by another cluster
+// and happen to share the name of a managed monitor.
+type Guard struct {
+	Interface
+
+	clusterID    string
+	adoptUnowned bool
+}
+
+// NewGuard creates a new *Guard for p. Monitors are owned if their owner has
+// clusterID. If adoptUnowned is true, monitors without any ownership
+// metadata are treated as owned as well.
+func NewGuard(p Interface, clusterID string, adoptUnowned bool) *Guard {
+	return &Guard{
+		Interface:    p,
+		clusterID:    clusterID,
+		adoptUnowned: adoptUnowned,
+	}
+}
+
+// Update implements Interface. Returns models.ErrMonitorNotOwned if the
+// existing monitor is owned by another cluster or another ingress, unless
+// the model carries the adopt annotation.
+func (g *Guard) Update(ctx context.Context, model *models.Monitor) error {
+	existing, err := g.Interface.Get(ctx, model.Name)
+	if err != nil {
+		return err
+	}
+
+	if !g.mayUpdate(existing, model) {
+		return errors.Wrapf(models.ErrMonitorNotOwned, "refusing to update monitor %q owned by %v", model.Name, existing.Owner)
+	}
+
+	return g.Interface.Update(ctx, model)
+}
+
+// Rename implements Renamer. Returns models.ErrMonitorNotOwned if the
+// monitor oldName is owned by another cluster or another ingress, unless the
+// model carries the adopt annotation.
+func (g *Guard) Rename(ctx context.Context, oldName string, model *models.Monitor) error {
+	existing, err := g.Interface.Get(ctx, oldName)
+	if err != nil {
+		return err
+	}
+
+	if !g.mayUpdate(existing, model) {
+		return errors.Wrapf(models.ErrMonitorNotOwned, "refusing to rename monitor %q owned by %v", oldName, existing.Owner)
+	}
+
+	return Rename(ctx, g.Interface, oldName, model)
+}
+
+// Diff implements Differ by delegating to the wrapped provider. Returns
+// models.ErrDiffNotSupported if the wrapped provider does not implement
+// Differ.
+func (g *Guard) Diff(ctx context.Context, existing, model *models.Monitor) ([]models.FieldDiff, error) {
+	return Diff(ctx, g.Interface, existing, model)
+}
+
+// Check implements Checker by delegating to the wrapped provider.
+func (g *Guard) Check(ctx context.Context) error {
+	return Check(ctx, g.Interface)
+}
+
+// Delete implements Interface. Returns models.ErrMonitorNotOwned if the
+// monitor is not owned by the cluster. It does not check which resource owns
+// the monitor, use DeleteOwned when deleting on behalf of a resource.
+func (g *Guard) Delete(ctx context.Context, name string) error {
+	return g.DeleteOwned(ctx, name, nil)
+}
+
+// DeleteOwned implements OwnerAwareDeleter. Returns
+// models.ErrMonitorNotOwned if the monitor is not owned by the cluster or, if
+// owner is not nil, by a resource other than owner. Monitors without
+// ownership metadata can only be deleted if unowned monitors are adopted.
+func (g *Guard) DeleteOwned(ctx context.Context, name string, owner *models.Owner) error {
+	existing, err := g.Interface.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if !g.ownedByCluster(existing.Owner) {
+		return errors.Wrapf(models.ErrMonitorNotOwned, "refusing to delete monitor %q owned by %v", name, existing.Owner)
+	}
+
+	if owner != nil && existing.Owner != nil && !sameResource(existing.Owner, owner) {
+		return errors.Wrapf(models.ErrMonitorNotOwned, "refusing to delete monitor %q owned by %v", name, existing.Owner)
+	}
+
+	return g.Interface.Delete(ctx, name)
+}
+
+func (g *Guard) mayUpdate(existing, model *models.Monitor) bool {
+	if model.Annotations.BoolValue(config.AnnotationAdopt, false) {
+		return true
+	}
+
+	if !g.ownedByCluster(existing.Owner) {
+		return false
+	}
+
+	if existing.Owner == nil || model.Owner == nil {
+		// Unowned monitors are adopted implicitly if adoptUnowned is set.
+		return true
+	}
+
+	return sameResource(existing.Owner, model.Owner)
+}
+
+// sameResource returns true if a and b refer to the same resource. The UID is
+// not compared, so that a recreated resource keeps its monitor.
+func sameResource(a, b *models.Owner) bool {
+	return a.Kind == b.Kind && a.Namespace == b.Namespace && a.Name == b.Name
+}
+
+func (g *Guard) ownedByCluster(owner *models.Owner) bool {
+	if owner == nil {
+		return g.adoptUnowned
+	}
+
+	return owner.ClusterID == g.clusterID
+}
+
+// IsNotOwned returns true if err was caused by models.ErrMonitorNotOwned.
+func IsNotOwned(err error) bool {
+	return errors.Cause(err) == models.ErrMonitorNotOwned
+}
@@ -0,0 +1,233 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuard_Update(t *testing.T) {
+	owner := &models.Owner{ClusterID: "prod", Namespace: "kube-system", Name: "foo", UID: "1234"}
+
+	tests := []struct {
+		name           string
+		existing       *models.Monitor
+		model          *models.Monitor
+		adoptUnowned   bool
+		expectNotOwned bool
+	}{
+		{
+			name:     "updates owned monitor",
+			existing: &models.Monitor{ID: "1", Name: "foo", Owner: owner},
+			model:    &models.Monitor{ID: "1", Name: "foo", Owner: &models.Owner{ClusterID: "prod", Namespace: "kube-system", Name: "foo", UID: "5678"}},
+		},
+		{
+			name:           "refuses to update unowned monitor",
+			existing:       &models.Monitor{ID: "1", Name: "foo"},
+			model:          &models.Monitor{ID: "1", Name: "foo", Owner: owner},
+			expectNotOwned: true,
+		},
+		{
+			name:         "updates unowned monitor if unowned monitors are adopted",
+			existing:     &models.Monitor{ID: "1", Name: "foo"},
+			model:        &models.Monitor{ID: "1", Name: "foo", Owner: owner},
+			adoptUnowned: true,
+		},
+		{
+			name:           "refuses to update monitor of another cluster",
+			existing:       &models.Monitor{ID: "1", Name: "foo", Owner: &models.Owner{ClusterID: "staging", Namespace: "kube-system", Name: "foo"}},
+			model:          &models.Monitor{ID: "1", Name: "foo", Owner: owner},
+			adoptUnowned:   true,
+			expectNotOwned: true,
+		},
+		{
+			name:           "refuses to update monitor of another ingress",
+			existing:       &models.Monitor{ID: "1", Name: "foo", Owner: &models.Owner{ClusterID: "prod", Namespace: "default", Name: "foo"}},
+			model:          &models.Monitor{ID: "1", Name: "foo", Owner: owner},
+			expectNotOwned: true,
+		},
+		{
+			name:           "refuses to update monitor of a resource of another kind",
+			existing:       &models.Monitor{ID: "1", Name: "foo", Owner: &models.Owner{ClusterID: "prod", Kind: "HTTPRoute", Namespace: "kube-system", Name: "foo"}},
+			model:          &models.Monitor{ID: "1", Name: "foo", Owner: owner},
+			expectNotOwned: true,
+		},
+		{
+			name:     "updates monitor of another cluster if adopt annotation is set",
+			existing: &models.Monitor{ID: "1", Name: "foo", Owner: &models.Owner{ClusterID: "staging", Namespace: "kube-system", Name: "foo"}},
+			model: &models.Monitor{
+				ID:    "1",
+				Name:  "foo",
+				Owner: owner,
+				Annotations: config.Annotations{
+					config.AnnotationAdopt: "true",
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &fake.Provider{}
+			p.On("Get", mock.Anything, "foo").Return(test.existing, nil)
+			p.On("Update", mock.Anything, test.model).Return(nil)
+
+			g := NewGuard(p, "prod", test.adoptUnowned)
+
+			err := g.Update(context.Background(), test.model)
+			if test.expectNotOwned {
+				require.Error(t, err)
+				assert.True(t, IsNotOwned(err))
+				p.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+				p.AssertCalled(t, "Update", mock.Anything, test.model)
+			}
+		})
+	}
+}
+
+func TestGuard_Delete(t *testing.T) {
+	tests := []struct {
+		name           string
+		existing       *models.Monitor
+		adoptUnowned   bool
+		expectNotOwned bool
+	}{
+		{
+			name:     "deletes owned monitor",
+			existing: &models.Monitor{ID: "1", Name: "foo", Owner: &models.Owner{ClusterID: "prod", Namespace: "kube-system", Name: "foo"}},
+		},
+		{
+			name:           "refuses to delete unowned monitor",
+			existing:       &models.Monitor{ID: "1", Name: "foo"},
+			expectNotOwned: true,
+		},
+		{
+			name:         "deletes unowned monitor if unowned monitors are adopted",
+			existing:     &models.Monitor{ID: "1", Name: "foo"},
+			adoptUnowned: true,
+		},
+		{
+			name:           "refuses to delete monitor of another cluster",
+			existing:       &models.Monitor{ID: "1", Name: "foo", Owner: &models.Owner{ClusterID: "staging", Namespace: "kube-system", Name: "foo"}},
+			expectNotOwned: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &fake.Provider{}
+			p.On("Get", mock.Anything, "foo").Return(test.existing, nil)
+			p.On("Delete", mock.Anything, "foo").Return(nil)
+
+			g := NewGuard(p, "prod", test.adoptUnowned)
+
+			err := g.Delete(context.Background(), "foo")
+			if test.expectNotOwned {
+				require.Error(t, err)
+				assert.True(t, IsNotOwned(err))
+				p.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+				p.AssertCalled(t, "Delete", mock.Anything, "foo")
+			}
+		})
+	}
+}
+
+func TestGuard_DeleteOwned(t *testing.T) {
+	owner := &models.Owner{ClusterID: "prod", Namespace: "kube-system", Name: "foo", UID: "1234"}
+
+	tests := []struct {
+		name           string
+		existing       *models.Monitor
+		adoptUnowned   bool
+		expectNotOwned bool
+	}{
+		{
+			name:     "deletes monitor of the same resource",
+			existing: &models.Monitor{ID: "1", Name: "foo", Owner: &models.Owner{ClusterID: "prod", Namespace: "kube-system", Name: "foo", UID: "5678"}},
+		},
+		{
+			name:         "deletes unowned monitor if unowned monitors are adopted",
+			existing:     &models.Monitor{ID: "1", Name: "foo"},
+			adoptUnowned: true,
+		},
+		{
+			name:           "refuses to delete monitor of another cluster",
+			existing:       &models.Monitor{ID: "1", Name: "foo", Owner: &models.Owner{ClusterID: "staging", Namespace: "kube-system", Name: "foo"}},
+			expectNotOwned: true,
+		},
+		{
+			name:           "refuses to delete monitor of a resource of another kind",
+			existing:       &models.Monitor{ID: "1", Name: "foo", Owner: &models.Owner{ClusterID: "prod", Kind: "IngressMonitor", Namespace: "kube-system", Name: "foo"}},
+			expectNotOwned: true,
+		},
+		{
+			name:           "refuses to delete monitor of another ingress",
+			existing:       &models.Monitor{ID: "1", Name: "foo", Owner: &models.Owner{ClusterID: "prod", Namespace: "default", Name: "foo"}},
+			expectNotOwned: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := &fake.Provider{}
+			p.On("Get", mock.Anything, "foo").Return(test.existing, nil)
+			p.On("Delete", mock.Anything, "foo").Return(nil)
+
+			g := NewGuard(p, "prod", test.adoptUnowned)
+
+			err := g.DeleteOwned(context.Background(), "foo", owner)
+			if test.expectNotOwned {
+				require.Error(t, err)
+				assert.True(t, IsNotOwned(err))
+				p.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+				p.AssertCalled(t, "Delete", mock.Anything, "foo")
+			}
+		})
+	}
+}
+
+func TestGuard_Delete_NotFound(t *testing.T) {
+	p := &fake.Provider{}
+	p.On("Get", mock.Anything, "foo").Return(nil, models.ErrMonitorNotFound)
+
+	err := NewGuard(p, "prod", false).Delete(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+}
+
+func TestGuard_Rename(t *testing.T) {
+	model := &models.Monitor{Name: "bar", Owner: &models.Owner{ClusterID: "prod", Namespace: "kube-system", Name: "foo"}}
+
+	t.Run("renames owned monitor", func(t *testing.T) {
+		p := &fake.Provider{}
+		p.On("Get", mock.Anything, "foo").Return(&models.Monitor{ID: "1", Name: "foo", Owner: model.Owner}, nil)
+		p.On("Create", mock.Anything, model).Return(nil)
+		p.On("Delete", mock.Anything, "foo").Return(nil)
+
+		err := NewGuard(p, "prod", false).Rename(context.Background(), "foo", model)
+		require.NoError(t, err)
+		p.AssertExpectations(t)
+	})
+
+	t.Run("refuses to rename monitor of another cluster", func(t *testing.T) {
+		p := &fake.Provider{}
+		p.On("Get", mock.Anything, "foo").Return(&models.Monitor{ID: "1", Name: "foo", Owner: &models.Owner{ClusterID: "staging", Namespace: "kube-system", Name: "foo"}}, nil)
+
+		err := NewGuard(p, "prod", false).Rename(context.Background(), "foo", model)
+		require.Error(t, err)
+		assert.True(t, IsNotOwned(err))
+		p.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		p.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,382 @@
+package local
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/metrics"
+	"github.com/pkg/errors"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("local-provider")
+
+// Provider keeps monitors in memory and periodically probes their URLs from
+// the controller itself. The probe results are exposed as prometheus metrics,
+// see pkg/monitor/metrics.
+type Provider struct {
+	config     config.LocalConfig
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	nextID  int
+	targets map[string]*target
+}
+
+// target is a monitor which is probed periodically until it is stopped.
+type target struct {
+	monitor  models.Monitor
+	interval time.Duration
+	timeout  time.Duration
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// probeConfig is the effective configuration of a target. It is exposed as
+// the Config of monitors returned by Get for drift detection.
+type probeConfig struct {
+	Name     string
+	URL      string
+	Interval time.Duration
+	Timeout  time.Duration
+	Owner    *models.Owner
+}
+
+// NewProvider creates a new local provider with given LocalConfig.
+func NewProvider(config config.LocalConfig) *Provider {
+	return &Provider{
+		config: config,
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: config.MonitorDefaults.InsecureSkipVerify,
+				},
+			},
+		},
+		nextID:  1,
+		targets: make(map[string]*target),
+	}
+}
+
+// Create implements provider.Interface.
+func (p *Provider) Create(_ context.Context, model *models.Monitor) error {
+	target, err := p.newTarget(model)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	target.monitor.ID = strconv.Itoa(p.nextID)
+	p.nextID++
+
+	p.start(target)
+
+	return nil
+}
+
+// Get implements provider.Interface.
+func (p *Provider) Get(_ context.Context, name string) (*models.Monitor, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	target, ok := p.findByName(name)
+	if !ok {
+		return nil, models.ErrMonitorNotFound
+	}
+
+	m := &models.Monitor{
+		ID:     target.monitor.ID,
+		Name:   target.monitor.Name,
+		URL:    target.monitor.URL,
+		Config: target.probeConfig(),
+	}
+
+	if target.monitor.Owner != nil {
+		owner := *target.monitor.Owner
+		m.Owner = &owner
+	}
+
+	return m, nil
+}
+
+// Diff implements provider.Differ. Update restarts the probe and resets its
+// metrics, so it is only needed if the configuration of the probe changed.
+func (p *Provider) Diff(_ context.Context, existing, model *models.Monitor) ([]models.FieldDiff, error) {
+	current, ok := existing.Config.(*probeConfig)
+	if !ok {
+		return nil, models.ErrDiffNotSupported
+	}
+
+	desired, err := p.newTarget(model)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.DiffFields(current, desired.probeConfig()), nil
+}
+
+// Update implements provider.Interface.
+func (p *Provider) Update(_ context.Context, model *models.Monitor) error {
+	target, err := p.newTarget(model)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	old, ok := p.targets[model.ID]
+	if !ok {
+		return errors.Errorf("monitor with ID %q does not exist", model.ID)
+	}
+
+	p.stop(old)
+	p.start(target)
+
+	return nil
+}
+
+// Delete implements provider.Interface.
+func (p *Provider) Delete(_ context.Context, name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	target, ok := p.findByName(name)
+	if !ok {
+		return models.ErrMonitorNotFound
+	}
+
+	p.stop(target)
+
+	return nil
+}
+
+// List implements provider.Interface.
+func (p *Provider) List(_ context.Context) ([]*models.Monitor, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	monitors := make([]*models.Monitor, 0, len(p.targets))
+
+	for _, target := range p.targets {
+		if target.monitor.Owner == nil {
+			continue
+		}
+
+		owner := *target.monitor.Owner
+
+		monitors = append(monitors, &models.Monitor{
+			ID:    target.monitor.ID,
+			Name:  target.monitor.Name,
+			URL:   target.monitor.URL,
+			Owner: &owner,
+		})
+	}
+
+	sort.Slice(monitors, func(i, j int) bool {
+		return monitors[i].Name < monitors[j].Name
+	})
+
+	return monitors, nil
+}
+
+// GetIPSourceRanges implements provider.Interface. It returns the configured
+// source ranges of the controller.
+func (p *Provider) GetIPSourceRanges(_ context.Context, _ *models.Monitor) ([]string, error) {
+	return p.config.SourceRanges, nil
+}
+
+func (p *Provider) newTarget(model *models.Monitor) (*target, error) {
+	anno := model.Annotations
+	defaults := p.config.MonitorDefaults
+
+	t := &target{
+		monitor: models.Monitor{
+			ID:    model.ID,
+			Name:  model.Name,
+			URL:   model.URL,
+			Owner: model.Owner,
+		},
+		interval: anno.DurationValue(config.AnnotationLocalInterval, defaults.Interval.Duration),
+		timeout:  anno.DurationValue(config.AnnotationLocalTimeout, defaults.Timeout.Duration),
+	}
+
+	if t.interval <= 0 {
+		return nil, errors.Errorf("invalid probe interval %s for monitor %q", t.interval, model.Name)
+	}
+
+	if t.timeout <= 0 {
+		return nil, errors.Errorf("invalid probe timeout %s for monitor %q", t.timeout, model.Name)
+	}
+
+	return t, nil
+}
+
+// probeConfig returns the effective configuration of t.
+func (t *target) probeConfig() *probeConfig {
+	c := &probeConfig{
+		Name:     t.monitor.Name,
+		URL:      t.monitor.URL,
+		Interval: t.interval,
+		Timeout:  t.timeout,
+	}
+
+	if t.monitor.Owner != nil {
+		owner := *t.monitor.Owner
+		c.Owner = &owner
+	}
+
+	return c
+}
+
+// findByName looks up the target of a monitor by name. Must be called with
+// p.mu held.
+func (p *Provider) findByName(name string) (*target, bool) {
+	for _, target := range p.targets {
+		if target.monitor.Name == name {
+			return target, true
+		}
+	}
+
+	return nil, false
+}
+
+// start registers the target and starts probing it in the background. Must
+// be called with p.mu held.
+func (p *Provider) start(t *target) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	t.cancel = cancel
+	t.done = make(chan struct{})
+
+	p.targets[t.monitor.ID] = t
+
+	go func() {
+		defer close(t.done)
+
+		p.run(ctx, t)
+	}()
+
+	log.V(1).Info("started probing", "monitor", t.monitor.Name, "url", t.monitor.URL, "interval", t.interval)
+}
+
+// stop stops probing the target, unregisters it and removes its metrics.
+// Must be called with p.mu held.
+func (p *Provider) stop(t *target) {
+	t.cancel()
+	<-t.done
+
+	delete(p.targets, t.monitor.ID)
+
+	metrics.ProbeUp.DeleteLabelValues(t.monitor.Name, t.monitor.URL)
+	metrics.ProbeDurationSeconds.DeleteLabelValues(t.monitor.Name, t.monitor.URL)
+	metrics.ProbeStatusCode.DeleteLabelValues(t.monitor.Name, t.monitor.URL)
+	metrics.ProbeTLSExpiryTimestampSeconds.DeleteLabelValues(t.monitor.Name, t.monitor.URL)
+
+	log.V(1).Info("stopped probing", "monitor", t.monitor.Name, "url", t.monitor.URL)
+}
+
+// run probes the target immediately and then on every interval until ctx is
+// canceled.
+func (p *Provider) run(ctx context.Context, t *target) {
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		p.probe(ctx, t)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe performs a single HTTP GET against the target's URL and records the
+// result.
+func (p *Provider) probe(ctx context.Context, t *target) {
+	name, url := t.monitor.Name, t.monitor.URL
+
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		log.Error(err, "failed to create probe request", "monitor", name, "url", url)
+		metrics.ProbeUp.WithLabelValues(name, url).Set(0)
+		return
+	}
+
+	req.Header.Set("User-Agent", "ingress-monitor-controller")
+
+	start := time.Now()
+
+	resp, err := p.httpClient.Do(req)
+	if ctx.Err() == context.Canceled {
+		// The target was stopped while the probe was in flight, do not
+		// record any metrics as they were already removed.
+		return
+	}
+
+	metrics.ProbeDurationSeconds.WithLabelValues(name, url).Set(time.Since(start).Seconds())
+
+	if err != nil {
+		log.V(1).Info("probe failed", "monitor", name, "url", url, "error", err.Error())
+		metrics.ProbeUp.WithLabelValues(name, url).Set(0)
+		metrics.ProbeStatusCode.WithLabelValues(name, url).Set(0)
+		return
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	up := resp.StatusCode >= 200 && resp.StatusCode < 400
+
+	log.V(2).Info("probe finished", "monitor", name, "url", url, "status", resp.StatusCode)
+
+	metrics.ProbeUp.WithLabelValues(name, url).Set(boolToFloat(up))
+	metrics.ProbeStatusCode.WithLabelValues(name, url).Set(float64(resp.StatusCode))
+
+	if expiry, ok := tlsExpiry(resp.TLS); ok {
+		metrics.ProbeTLSExpiryTimestampSeconds.WithLabelValues(name, url).Set(float64(expiry.Unix()))
+	}
+}
+
+// tlsExpiry returns the expiry of the earliest expiring certificate of the
+// connection state. The second return value is false if state is nil or does
+// not contain any peer certificates.
+func tlsExpiry(state *tls.ConnectionState) (time.Time, bool) {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return time.Time{}, false
+	}
+
+	expiry := state.PeerCertificates[0].NotAfter
+
+	for _, cert := range state.PeerCertificates[1:] {
+		if cert.NotAfter.Before(expiry) {
+			expiry = cert.NotAfter
+		}
+	}
+
+	return expiry, true
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,279 @@
+package local
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var testConfig = config.LocalConfig{
+	SourceRanges: []string{"10.0.0.0/8"},
+	MonitorDefaults: config.LocalMonitorDefaults{
+		Interval: metav1Duration(10 * time.Millisecond),
+		Timeout:  metav1Duration(time.Second),
+	},
+}
+
+func TestProvider_Create(t *testing.T) {
+	tests := []struct {
+		name               string
+		handler            http.HandlerFunc
+		expectedUp         float64
+		expectedStatusCode float64
+	}{
+		{
+			name:               "successful probe",
+			handler:            func(w http.ResponseWriter, _ *http.Request) {},
+			expectedUp:         1,
+			expectedStatusCode: 200,
+		},
+		{
+			name: "failed probe",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+			expectedUp:         0,
+			expectedStatusCode: 503,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(test.handler)
+			defer server.Close()
+
+			p := NewProvider(testConfig)
+
+			model := &models.Monitor{Name: t.Name(), URL: server.URL}
+
+			require.NoError(t, p.Create(context.Background(), model))
+			defer p.Delete(context.Background(), model.Name)
+
+			require.Eventually(t, func() bool {
+				return gaugeValue(metrics.ProbeStatusCode, model) == test.expectedStatusCode
+			}, time.Second, 5*time.Millisecond)
+
+			assert.Equal(t, test.expectedUp, gaugeValue(metrics.ProbeUp, model))
+
+			_, found := lookupGauge(metrics.ProbeTLSExpiryTimestampSeconds, model)
+			assert.False(t, found)
+		})
+	}
+}
+
+func TestProvider_Create_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer server.Close()
+
+	cfg := testConfig
+	cfg.MonitorDefaults.InsecureSkipVerify = true
+
+	p := NewProvider(cfg)
+
+	model := &models.Monitor{Name: "tls-monitor", URL: server.URL}
+
+	require.NoError(t, p.Create(context.Background(), model))
+	defer p.Delete(context.Background(), model.Name)
+
+	expectedExpiry := float64(server.Certificate().NotAfter.Unix())
+
+	require.Eventually(t, func() bool {
+		return gaugeValue(metrics.ProbeTLSExpiryTimestampSeconds, model) == expectedExpiry
+	}, time.Second, 5*time.Millisecond)
+
+	assert.Equal(t, float64(1), gaugeValue(metrics.ProbeUp, model))
+}
+
+func TestProvider_Create_InvalidAnnotations(t *testing.T) {
+	p := NewProvider(testConfig)
+
+	err := p.Create(context.Background(), &models.Monitor{
+		Name: "my-monitor",
+		URL:  "http://my-monitor",
+		Annotations: config.Annotations{
+			config.AnnotationLocalInterval: "invalid",
+		},
+	})
+	require.Error(t, err)
+	assert.Equal(t, `invalid probe interval 0s for monitor "my-monitor"`, err.Error())
+
+	_, err = p.Get(context.Background(), "my-monitor")
+	assert.Equal(t, models.ErrMonitorNotFound, err)
+}
+
+func TestProvider_GetUpdateDelete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p := NewProvider(testConfig)
+
+	_, err := p.Get(context.Background(), "my-monitor")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{Name: "my-monitor", URL: server.URL}))
+
+	monitor, err := p.Get(context.Background(), "my-monitor")
+	require.NoError(t, err)
+	require.Equal(t, &models.Monitor{
+		ID:   "1",
+		Name: "my-monitor",
+		URL:  server.URL,
+		Config: &probeConfig{
+			Name:     "my-monitor",
+			URL:      server.URL,
+			Interval: 10 * time.Millisecond,
+			Timeout:  time.Second,
+		},
+	}, monitor)
+
+	require.Eventually(t, func() bool {
+		return gaugeValue(metrics.ProbeStatusCode, monitor) == 404
+	}, time.Second, 5*time.Millisecond)
+
+	updated := &models.Monitor{ID: "1", Name: "my-monitor", URL: server.URL + "/health"}
+	require.NoError(t, p.Update(context.Background(), updated))
+
+	require.Eventually(t, func() bool {
+		return gaugeValue(metrics.ProbeStatusCode, updated) == 200
+	}, time.Second, 5*time.Millisecond)
+
+	// Metrics of the old URL are removed.
+	_, found := lookupGauge(metrics.ProbeUp, monitor)
+	assert.False(t, found)
+
+	require.Error(t, p.Update(context.Background(), &models.Monitor{ID: "42", Name: "foo", URL: server.URL}))
+
+	require.NoError(t, p.Delete(context.Background(), "my-monitor"))
+	require.Equal(t, models.ErrMonitorNotFound, p.Delete(context.Background(), "my-monitor"))
+
+	_, found = lookupGauge(metrics.ProbeUp, updated)
+	assert.False(t, found)
+
+	_, err = p.Get(context.Background(), "my-monitor")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+}
+
+func TestProvider_Diff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer server.Close()
+
+	p := NewProvider(testConfig)
+
+	owner := &models.Owner{Namespace: "kube-system", Name: "foo"}
+	model := &models.Monitor{Name: "my-monitor", URL: server.URL, Owner: owner}
+
+	require.NoError(t, p.Create(context.Background(), model))
+	defer p.Delete(context.Background(), model.Name)
+
+	existing, err := p.Get(context.Background(), model.Name)
+	require.NoError(t, err)
+
+	diffs, err := p.Diff(context.Background(), existing, model)
+	require.NoError(t, err)
+	assert.Empty(t, diffs)
+
+	diffs, err = p.Diff(context.Background(), existing, &models.Monitor{
+		Name:  "my-monitor",
+		URL:   server.URL,
+		Owner: owner,
+		Annotations: config.Annotations{
+			config.AnnotationLocalInterval: "1m",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []models.FieldDiff{
+		{Field: "Interval", Current: 10 * time.Millisecond, Desired: time.Minute},
+	}, diffs)
+
+	diffs, err = p.Diff(context.Background(), existing, &models.Monitor{Name: "my-monitor", URL: server.URL + "/health", Owner: owner})
+	require.NoError(t, err)
+	assert.Equal(t, []models.FieldDiff{
+		{Field: "URL", Current: server.URL, Desired: server.URL + "/health"},
+	}, diffs)
+
+	_, err = p.Diff(context.Background(), &models.Monitor{Name: "my-monitor"}, model)
+	assert.Equal(t, models.ErrDiffNotSupported, err)
+}
+
+func TestProvider_List(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer server.Close()
+
+	p := NewProvider(testConfig)
+
+	owner := &models.Owner{Namespace: "kube-system", Name: "foo"}
+
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{Name: "owned", URL: server.URL, Owner: owner}))
+	defer p.Delete(context.Background(), "owned")
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{Name: "unowned", URL: server.URL}))
+	defer p.Delete(context.Background(), "unowned")
+
+	monitors, err := p.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Monitor{{ID: "1", Name: "owned", URL: server.URL, Owner: owner}}, monitors)
+}
+
+func TestProvider_GetIPSourceRanges(t *testing.T) {
+	p := NewProvider(testConfig)
+
+	sourceRanges, err := p.GetIPSourceRanges(context.Background(), &models.Monitor{Name: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8"}, sourceRanges)
+}
+
+func gaugeValue(vec *prometheus.GaugeVec, monitor *models.Monitor) float64 {
+	value, _ := lookupGauge(vec, monitor)
+	return value
+}
+
+// lookupGauge returns the value of the gauge for monitor without creating it
+// if it does not exist. The second return value is false if the gauge does
+// not exist.
+func lookupGauge(vec *prometheus.GaugeVec, monitor *models.Monitor) (float64, bool) {
+	ch := make(chan prometheus.Metric)
+
+	go func() {
+		vec.Collect(ch)
+		close(ch)
+	}()
+
+	var value float64
+	var found bool
+
+	for metric := range ch {
+		var m dto.Metric
+		if err := metric.Write(&m); err != nil {
+			continue
+		}
+
+		labels := make(map[string]string)
+		for _, label := range m.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+
+		if labels["monitor"] == monitor.Name && labels["url"] == monitor.URL {
+			value, found = m.GetGauge().GetValue(), true
+		}
+	}
+
+	return value, found
+}
+
+func metav1Duration(d time.Duration) metav1.Duration {
+	return metav1.Duration{Duration: d}
+}
@@ -1,6 +1,8 @@
 package null
 
 import (
+	"context"
+
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
 )
 
@@ -8,27 +10,32 @@ import (
 type Provider struct{}
 
 // Create implements provider.Interface.
-func (p *Provider) Create(_ *models.Monitor) error {
+func (p *Provider) Create(_ context.Context, _ *models.Monitor) error {
 	return nil
 }
 
 // Create implements provider.Interface.
-func (p *Provider) Get(_ string) (*models.Monitor, error) {
+func (p *Provider) Get(_ context.Context, _ string) (*models.Monitor, error) {
 	return nil, models.ErrMonitorNotFound
 }
 
 // Create implements provider.Interface.
-func (p *Provider) Update(_ *models.Monitor) error {
+func (p *Provider) Update(_ context.Context, _ *models.Monitor) error {
 	return nil
 }
 
 // Create implements provider.Interface.
-func (p *Provider) Delete(_ string) error {
+func (p *Provider) Delete(_ context.Context, _ string) error {
 	return nil
 }
 
+// List implements provider.Interface.
+func (p *Provider) List(_ context.Context) ([]*models.Monitor, error) {
+	return nil, nil
+}
+
 // GetIPSourceRanges implements provider.Interface.
-func (p *Provider) GetIPSourceRanges(model *models.Monitor) ([]string, error) {
+func (p *Provider) GetIPSourceRanges(_ context.Context, _ *models.Monitor) ([]string, error) {
 	// We just whitelist localhost for testing here.
 	return []string{"127.0.0.1/32"}, nil
 }
@@ -0,0 +1,76 @@
+package pingdom
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/pkg/errors"
+)
+
+type builder struct {
+	defaults config.PingdomMonitorDefaults
+}
+
+func newBuilder(defaults config.PingdomMonitorDefaults) *builder {
+	return &builder{
+		defaults: defaults,
+	}
+}
+
+func (b *builder) FromModel(model *models.Monitor) (*Check, error) {
+	anno := model.Annotations
+	defaults := b.defaults
+
+	u, err := url.Parse(model.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &Check{
+		Name:       model.Name,
+		Host:       u.Hostname(),
+		URL:        u.RequestURI(),
+		Encryption: u.Scheme == "https",
+	}
+
+	if u.Port() != "" {
+		check.Port, err = strconv.Atoi(u.Port())
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid port in url %q", model.URL)
+		}
+	}
+
+	if model.ID != "" {
+		check.ID, err = strconv.ParseInt(model.ID, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid pingdom check ID %q", model.ID)
+		}
+	} else {
+		// The check type cannot be changed once the check is created.
+		check.Type = checkTypeHTTP
+	}
+
+	check.Resolution = anno.IntValue(config.AnnotationPingdomResolution, defaults.Resolution)
+	check.IntegrationIDs = anno.IntSliceValue(config.AnnotationPingdomIntegrationIDs, defaults.IntegrationIDs)
+	check.ProbeFilters = anno.StringSliceValue(config.AnnotationPingdomProbeFilters, defaults.ProbeFilters)
+	check.Tags = anno.StringSliceValue(config.AnnotationPingdomTags, defaults.Tags)
+	check.TeamIDs = anno.IntSliceValue(config.AnnotationPingdomTeamIDs, defaults.TeamIDs)
+	check.UserIDs = anno.IntSliceValue(config.AnnotationPingdomUserIDs, defaults.UserIDs)
+
+	err = anno.ParseJSON(config.AnnotationPingdomRequestHeaders, &check.RequestHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	if check.RequestHeaders == nil {
+		check.RequestHeaders = defaults.RequestHeaders
+	}
+
+	if model.Owner != nil {
+		check.Tags = withOwnerTags(check.Tags, model.Owner)
+	}
+
+	return check, nil
+}
@@ -0,0 +1,287 @@
+package pingdom
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultAPIBaseURL is the base url of the Pingdom 3.1 API.
+	defaultAPIBaseURL = "https://api.pingdom.com/api/3.1"
+
+	// checkTypeHTTP is the Pingdom check type for HTTP(s) checks.
+	checkTypeHTTP = "http"
+)
+
+// errNotFound is returned by the client if the API responds with status code
+// 404.
+var errNotFound = errors.New("not found")
+
+// Check is the configuration of a Pingdom HTTP check as it is sent to the
+// API when creating or updating checks.
+type Check struct {
+	ID             int64             `json:"-"`
+	Name           string            `json:"name"`
+	Host           string            `json:"host"`
+	Type           string            `json:"type,omitempty"`
+	URL            string            `json:"url"`
+	Encryption     bool              `json:"encryption"`
+	Port           int               `json:"port,omitempty"`
+	Resolution     int               `json:"resolution"`
+	IntegrationIDs []int             `json:"integrationids"`
+	ProbeFilters   []string          `json:"probe_filters"`
+	RequestHeaders map[string]string `json:"requestheaders,omitempty"`
+	Tags           []string          `json:"tags"`
+	TeamIDs        []int             `json:"teamids"`
+	UserIDs        []int             `json:"userids"`
+}
+
+// tag is a tag of a check as returned by the API.
+type tag struct {
+	Name string `json:"name"`
+}
+
+// checkSummary is the condensed representation of a check returned when
+// listing checks.
+type checkSummary struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
+	Type     string `json:"type"`
+	Tags     []tag  `json:"tags"`
+}
+
+// checkDetails is the detailed representation of a check.
+type checkDetails struct {
+	ID       int64  `json:"id"`
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
+	Tags     []tag  `json:"tags"`
+	Type     struct {
+		HTTP *struct {
+			URL            string            `json:"url"`
+			Encryption     bool              `json:"encryption"`
+			Port           int               `json:"port"`
+			RequestHeaders map[string]string `json:"requestheaders"`
+		} `json:"http"`
+	} `json:"type"`
+}
+
+// Probe is a Pingdom probe server.
+type Probe struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Active bool   `json:"active"`
+	IP     string `json:"ip"`
+	IPv6   string `json:"ipv6"`
+	Region string `json:"region"`
+}
+
+type apiError struct {
+	Error struct {
+		StatusCode   int    `json:"statuscode"`
+		StatusDesc   string `json:"statusdesc"`
+		ErrorMessage string `json:"errormessage"`
+	} `json:"error"`
+}
+
+// client is a minimal client for the Pingdom 3.1 API.
+type client struct {
+	httpClient *http.Client
+	baseURL    string
+	apiToken   string
+}
+
+func newClient(apiToken string) *client {
+	return &client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    defaultAPIBaseURL,
+		apiToken:   apiToken,
+	}
+}
+
+func (c *client) listChecks(ctx context.Context) ([]*checkSummary, error) {
+	var resp struct {
+		Checks []*checkSummary `json:"checks"`
+	}
+
+	err := c.do(ctx, http.MethodGet, "/checks?include_tags=true", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Checks, nil
+}
+
+func (c *client) getCheck(ctx context.Context, id int64) (*checkDetails, error) {
+	var resp struct {
+		Check *checkDetails `json:"check"`
+	}
+
+	err := c.do(ctx, http.MethodGet, "/checks/"+strconv.FormatInt(id, 10), nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Check, nil
+}
+
+func (c *client) createCheck(ctx context.Context, check *Check) (int64, error) {
+	var resp struct {
+		Check struct {
+			ID int64 `json:"id"`
+		} `json:"check"`
+	}
+
+	err := c.do(ctx, http.MethodPost, "/checks", check, &resp)
+	if err != nil {
+		return 0, err
+	}
+
+	return resp.Check.ID, nil
+}
+
+func (c *client) updateCheck(ctx context.Context, check *Check) error {
+	return c.do(ctx, http.MethodPut, "/checks/"+strconv.FormatInt(check.ID, 10), check, nil)
+}
+
+func (c *client) deleteCheck(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, "/checks/"+strconv.FormatInt(id, 10), nil, nil)
+}
+
+func (c *client) listProbes(ctx context.Context) ([]*Probe, error) {
+	var resp struct {
+		Probes []*Probe `json:"probes"`
+	}
+
+	err := c.do(ctx, http.MethodGet, "/probes?onlyactive=true", nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Probes, nil
+}
+
+// do performs an API request. If body is non-nil it is sent as json. If v is
+// non-nil, the response body is decoded into it.
+func (c *client) do(ctx context.Context, method, path string, body, v interface{}) error {
+	var r io.Reader
+
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+
+		r = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, r)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.apiToken)
+	req.Header.Set("Accept", "application/json")
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr apiError
+
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil || apiErr.Error.ErrorMessage == "" {
+			return errors.Errorf("%s %s: unexpected status code %d", method, path, resp.StatusCode)
+		}
+
+		return errors.Errorf("%s %s: %d %s: %s", method, path, resp.StatusCode, apiErr.Error.StatusDesc, apiErr.Error.ErrorMessage)
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode response of %s %s", method, path)
+	}
+
+	return nil
+}
+
+// summaryOf returns the summary of the created or updated check.
+func summaryOf(check *Check) *checkSummary {
+	summary := &checkSummary{
+		ID:       check.ID,
+		Name:     check.Name,
+		Hostname: check.Host,
+		Type:     checkTypeHTTP,
+		Tags:     make([]tag, len(check.Tags)),
+	}
+
+	for i, name := range check.Tags {
+		summary.Tags[i] = tag{Name: name}
+	}
+
+	return summary
+}
+
+// owner extracts the owner from the owner tags. Returns nil if the tags are
+// absent or incomplete.
+func (s *checkSummary) owner() *models.Owner {
+	return ownerFromTags(tagNames(s.Tags))
+}
+
+// owner extracts the owner from the owner tags. Returns nil if the tags are
+// absent or incomplete.
+func (d *checkDetails) owner() *models.Owner {
+	return ownerFromTags(tagNames(d.Tags))
+}
+
+func tagNames(tags []tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+
+	return names
+}
+
+// url reconstructs the monitored url from the check details.
+func (d *checkDetails) url() string {
+	if d.Type.HTTP == nil {
+		return ""
+	}
+
+	scheme, defaultPort := "http", 80
+	if d.Type.HTTP.Encryption {
+		scheme, defaultPort = "https", 443
+	}
+
+	host := d.Hostname
+	if d.Type.HTTP.Port != 0 && d.Type.HTTP.Port != defaultPort {
+		host = fmt.Sprintf("%s:%d", host, d.Type.HTTP.Port)
+	}
+
+	return fmt.Sprintf("%s://%s%s", scheme, host, d.Type.HTTP.URL)
+}
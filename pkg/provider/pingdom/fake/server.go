@@ -0,0 +1,287 @@
+// Package fake provides an in-memory stand-in for the Pingdom 3.1 API that can
+// be used in unit tests.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server is an httptest server that implements the subset of the Pingdom 3.1
+// API used by the pingdom provider. Checks are kept in memory.
+type Server struct {
+	*httptest.Server
+
+	// APIToken is the bearer token that is expected in every request.
+	APIToken string
+
+	// Probes is the list of probes served by the probes endpoint.
+	Probes []map[string]interface{}
+
+	mu       sync.Mutex
+	nextID   int64
+	checks   map[int64]map[string]interface{}
+	requests []string
+}
+
+// NewServer creates and starts a new *Server which expects apiToken to be
+// present in every API request. The server must be closed by the caller.
+func NewServer(apiToken string) *Server {
+	s := &Server{
+		APIToken: apiToken,
+		nextID:   1,
+		checks:   make(map[int64]map[string]interface{}),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+// APIBaseURL returns the base URL of the fake API.
+func (s *Server) APIBaseURL() string {
+	return s.URL + "/api/3.1"
+}
+
+// AddCheck adds a check with given fields to the server and returns its ID.
+// Fields are expected in the format of a create check request.
+func (s *Server) AddCheck(fields map[string]interface{}) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+
+	check := map[string]interface{}{"type": "http"}
+	for k, v := range fields {
+		check[k] = v
+	}
+
+	s.checks[id] = check
+
+	return id
+}
+
+// Checks returns a snapshot of all checks sorted by ID. The checks are
+// returned in the format of a create check request with the id added.
+func (s *Server) Checks() []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	checks := make([]map[string]interface{}, 0, len(s.checks))
+	for _, id := range s.sortedIDs() {
+		check := copyCheck(s.checks[id])
+		check["id"] = id
+		checks = append(checks, check)
+	}
+
+	return checks
+}
+
+// Requests returns the method and path of all API requests in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/3.1")
+
+	s.requests = append(s.requests, r.Method+" "+path)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Header.Get("Authorization") != "Bearer "+s.APIToken {
+		writeError(w, http.StatusUnauthorized, "Invalid token")
+		return
+	}
+
+	switch {
+	case path == "/probes" && r.Method == http.MethodGet:
+		writeJSON(w, map[string]interface{}{"probes": s.Probes})
+	case path == "/checks" && r.Method == http.MethodGet:
+		s.listChecks(w, r)
+	case path == "/checks" && r.Method == http.MethodPost:
+		s.createCheck(w, r)
+	case strings.HasPrefix(path, "/checks/"):
+		id, err := strconv.ParseInt(strings.TrimPrefix(path, "/checks/"), 10, 64)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid check ID")
+			return
+		}
+
+		check, ok := s.checks[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, "Check not found")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, map[string]interface{}{"check": checkDetails(id, check)})
+		case http.MethodPut:
+			s.updateCheck(w, r, id, check)
+		case http.MethodDelete:
+			delete(s.checks, id)
+			writeJSON(w, map[string]interface{}{"message": "Deletion of check was successful!"})
+		default:
+			writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+		}
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+func (s *Server) listChecks(w http.ResponseWriter, r *http.Request) {
+	checks := []map[string]interface{}{}
+	includeTags := r.URL.Query().Get("include_tags") == "true"
+
+	for _, id := range s.sortedIDs() {
+		check := s.checks[id]
+		summary := map[string]interface{}{
+			"id":       id,
+			"name":     check["name"],
+			"hostname": check["host"],
+			"type":     check["type"],
+		}
+
+		if tags, ok := check["tags"].([]interface{}); ok && includeTags {
+			summary["tags"] = tagObjects(tags)
+		}
+
+		checks = append(checks, summary)
+	}
+
+	writeJSON(w, map[string]interface{}{"checks": checks})
+}
+
+func (s *Server) createCheck(w http.ResponseWriter, r *http.Request) {
+	check := make(map[string]interface{})
+
+	if err := json.NewDecoder(r.Body).Decode(&check); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	for _, field := range []string{"name", "host", "type"} {
+		if _, ok := check[field]; !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("Missing required field %s", field))
+			return
+		}
+	}
+
+	id := s.nextID
+	s.nextID++
+
+	s.checks[id] = check
+
+	writeJSON(w, map[string]interface{}{
+		"check": map[string]interface{}{"id": id, "name": check["name"]},
+	})
+}
+
+func (s *Server) updateCheck(w http.ResponseWriter, r *http.Request, id int64, check map[string]interface{}) {
+	fields := make(map[string]interface{})
+
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if _, ok := fields["type"]; ok {
+		writeError(w, http.StatusBadRequest, "Check type cannot be changed")
+		return
+	}
+
+	for k, v := range fields {
+		check[k] = v
+	}
+
+	writeJSON(w, map[string]interface{}{"message": fmt.Sprintf("Modification of check %d was successful!", id)})
+}
+
+func (s *Server) sortedIDs() []int64 {
+	ids := make([]int64, 0, len(s.checks))
+	for id := range s.checks {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+// checkDetails converts a check into the format returned by the check
+// details endpoint.
+func checkDetails(id int64, check map[string]interface{}) map[string]interface{} {
+	details := map[string]interface{}{
+		"id":             id,
+		"name":           check["name"],
+		"hostname":       check["host"],
+		"resolution":     check["resolution"],
+		"integrationids": check["integrationids"],
+		"probe_filters":  check["probe_filters"],
+		"userids":        check["userids"],
+		"teamids":        check["teamids"],
+	}
+
+	if tags, ok := check["tags"].([]interface{}); ok {
+		details["tags"] = tagObjects(tags)
+	}
+
+	details["type"] = map[string]interface{}{
+		"http": map[string]interface{}{
+			"url":            check["url"],
+			"encryption":     check["encryption"],
+			"port":           check["port"],
+			"requestheaders": check["requestheaders"],
+		},
+	}
+
+	return details
+}
+
+// tagObjects converts tag names into the format returned by the API.
+func tagObjects(tags []interface{}) []map[string]interface{} {
+	objects := make([]map[string]interface{}, len(tags))
+	for i, tag := range tags {
+		objects[i] = map[string]interface{}{"name": tag, "type": "u"}
+	}
+
+	return objects
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, statusCode int, message string) {
+	w.WriteHeader(statusCode)
+	writeJSON(w, map[string]interface{}{
+		"error": map[string]interface{}{
+			"statuscode":   statusCode,
+			"statusdesc":   http.StatusText(statusCode),
+			"errormessage": message,
+		},
+	})
+}
+
+func copyCheck(check map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(check))
+	for k, v := range check {
+		c[k] = v
+	}
+
+	return c
+}
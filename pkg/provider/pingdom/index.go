@@ -0,0 +1,139 @@
+package pingdom
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/metrics"
+)
+
+// checkIndex indexes the summaries of Pingdom HTTP checks by name. It is
+// refreshed from the API once it is older than the refresh interval and kept
+// coherent with the checks created, updated and deleted by the provider in
+// between. This avoids listing all checks on every lookup.
+type checkIndex struct {
+	sync.Mutex
+
+	refreshInterval time.Duration
+	lastRefresh     time.Time
+	checks          map[string]*checkSummary
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+func newCheckIndex(refreshInterval time.Duration) *checkIndex {
+	return &checkIndex{
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+	}
+}
+
+// get looks up the HTTP check with name. If the index is stale, list is
+// called to refresh it first. The second return value is false if the check
+// does not exist.
+func (i *checkIndex) get(ctx context.Context, name string, list func(context.Context) ([]*checkSummary, error)) (*checkSummary, bool, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.isStale() {
+		metrics.MonitorCacheMissesTotal.WithLabelValues(config.ProviderPingdom).Inc()
+
+		checks, err := list(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+
+		i.replace(checks)
+	} else {
+		metrics.MonitorCacheHitsTotal.WithLabelValues(config.ProviderPingdom).Inc()
+	}
+
+	check, found := i.checks[name]
+
+	return check, found, nil
+}
+
+// refresh unconditionally refreshes the index using list and returns all
+// checks.
+func (i *checkIndex) refresh(ctx context.Context, list func(context.Context) ([]*checkSummary, error)) ([]*checkSummary, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	checks, err := list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	i.replace(checks)
+
+	return checks, nil
+}
+
+// set adds or replaces check in the index.
+func (i *checkIndex) set(check *checkSummary) {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.checks == nil {
+		return
+	}
+
+	// The name may have changed, so we have to drop the old entry.
+	for name, c := range i.checks {
+		if c.ID == check.ID {
+			delete(i.checks, name)
+		}
+	}
+
+	i.checks[check.Name] = check
+	i.updateSize()
+}
+
+// remove removes the check with name from the index.
+func (i *checkIndex) remove(name string) {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.checks == nil {
+		return
+	}
+
+	delete(i.checks, name)
+	i.updateSize()
+}
+
+// invalidate forces a refresh on the next lookup. This is used if the state
+// of a check is unknown after a failed API call.
+func (i *checkIndex) invalidate() {
+	i.Lock()
+	defer i.Unlock()
+
+	i.checks = nil
+}
+
+func (i *checkIndex) isStale() bool {
+	return i.checks == nil || i.refreshInterval <= 0 || i.now().Sub(i.lastRefresh) >= i.refreshInterval
+}
+
+// replace replaces the indexed checks with the HTTP checks in checks.
+func (i *checkIndex) replace(checks []*checkSummary) {
+	i.checks = make(map[string]*checkSummary, len(checks))
+
+	for _, check := range checks {
+		if check.Type == checkTypeHTTP {
+			i.checks[check.Name] = check
+		}
+	}
+
+	i.lastRefresh = i.now()
+
+	metrics.MonitorCacheLastRefreshTimestampSeconds.WithLabelValues(config.ProviderPingdom).Set(float64(i.lastRefresh.Unix()))
+	i.updateSize()
+}
+
+func (i *checkIndex) updateSize() {
+	metrics.MonitorCacheSize.WithLabelValues(config.ProviderPingdom).Set(float64(len(i.checks)))
+}
@@ -0,0 +1,74 @@
+package pingdom
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvider_IndexCoherence(t *testing.T) {
+	p, s := newTestProvider(t, config.PingdomConfig{
+		MonitorCacheRefreshInterval: config.NewDefaultProviderConfig().Pingdom.MonitorCacheRefreshInterval,
+	})
+
+	_, err := p.Get(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{Name: "foo", URL: "http://foo.bar"}))
+
+	monitor, err := p.Get(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "1", monitor.ID)
+
+	require.NoError(t, p.Update(context.Background(), &models.Monitor{ID: "1", Name: "bar", URL: "http://foo.bar"}))
+
+	_, err = p.Get(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	require.NoError(t, p.Delete(context.Background(), "bar"))
+
+	_, err = p.Get(context.Background(), "bar")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	// All lookups were served from the index after the initial refresh, only
+	// the details of the looked up checks were retrieved.
+	assert.Equal(t, []string{
+		"GET /checks",
+		"POST /checks",
+		"GET /checks/1",
+		"PUT /checks/1",
+		"GET /checks/1",
+		"DELETE /checks/1",
+	}, s.Requests())
+}
+
+func TestProvider_IndexRemovesDeletedChecks(t *testing.T) {
+	p, s := newTestProvider(t, config.PingdomConfig{
+		MonitorCacheRefreshInterval: config.NewDefaultProviderConfig().Pingdom.MonitorCacheRefreshInterval,
+	})
+
+	s.AddCheck(map[string]interface{}{"name": "foo", "host": "foo.bar", "url": "/"})
+
+	_, err := p.Get(context.Background(), "foo")
+	require.NoError(t, err)
+
+	// The check is deleted behind the back of the provider.
+	require.NoError(t, p.client.deleteCheck(context.Background(), 1))
+
+	_, err = p.Get(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	_, err = p.Get(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	assert.Equal(t, []string{
+		"GET /checks",
+		"GET /checks/1",
+		"DELETE /checks/1",
+		"GET /checks/1",
+	}, s.Requests())
+}
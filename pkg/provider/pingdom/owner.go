@@ -0,0 +1,75 @@
+package pingdom
+
+import (
+	"strings"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+)
+
+// ownerTagPrefix is the prefix of the tags which mark checks as owned by the
+// controller. Pingdom limits tags to 64 characters, so every field of the
+// owner is stored in a separate tag, e.g. "imc-namespace-kube-system".
+const ownerTagPrefix = "imc-"
+
+// ownerTagFields are the fields of the owner in the order their tags are
+// added to checks.
+var ownerTagFields = []string{"cluster", "kind", "namespace", "name", "uid"}
+
+// withOwnerTags returns a copy of tags with the owner tags replaced by the
+// tags of owner. Tags set by the user are preserved.
+func withOwnerTags(tags []string, owner *models.Owner) []string {
+	result := make([]string, 0, len(tags)+len(ownerTagFields))
+
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, ownerTagPrefix) {
+			result = append(result, tag)
+		}
+	}
+
+	values := ownerTagValues(owner)
+
+	for _, field := range ownerTagFields {
+		if values[field] != "" {
+			result = append(result, ownerTagPrefix+field+"-"+values[field])
+		}
+	}
+
+	return result
+}
+
+// ownerFromTags extracts the owner from the owner tags. Returns nil if tags
+// do not contain the namespace and name of an owner.
+func ownerFromTags(tags []string) *models.Owner {
+	values := make(map[string]string, len(ownerTagFields))
+
+	for _, tag := range tags {
+		for _, field := range ownerTagFields {
+			prefix := ownerTagPrefix + field + "-"
+			if strings.HasPrefix(tag, prefix) {
+				values[field] = strings.TrimPrefix(tag, prefix)
+			}
+		}
+	}
+
+	if values["namespace"] == "" || values["name"] == "" {
+		return nil
+	}
+
+	return &models.Owner{
+		ClusterID: values["cluster"],
+		Kind:      values["kind"],
+		Namespace: values["namespace"],
+		Name:      values["name"],
+		UID:       values["uid"],
+	}
+}
+
+func ownerTagValues(owner *models.Owner) map[string]string {
+	return map[string]string{
+		"cluster":   owner.ClusterID,
+		"kind":      owner.Kind,
+		"namespace": owner.Namespace,
+		"name":      owner.Name,
+		"uid":       owner.UID,
+	}
+}
@@ -0,0 +1,41 @@
+package pingdom
+
+import (
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOwnerTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		owner models.Owner
+		tags  []string
+	}{
+		{
+			name:  "ingress",
+			owner: models.Owner{ClusterID: "prod", Namespace: "kube-system", Name: "foo", UID: "1234"},
+			tags:  []string{"imc-cluster-prod", "imc-namespace-kube-system", "imc-name-foo", "imc-uid-1234"},
+		},
+		{
+			name:  "HTTPRoute without cluster",
+			owner: models.Owner{Kind: "HTTPRoute", Namespace: "default", Name: "name-space"},
+			tags:  []string{"imc-kind-HTTPRoute", "imc-namespace-default", "imc-name-name-space"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tags := withOwnerTags([]string{"kubernetes"}, &test.owner)
+			assert.Equal(t, append([]string{"kubernetes"}, test.tags...), tags)
+			assert.Equal(t, &test.owner, ownerFromTags(tags))
+		})
+	}
+}
+
+func TestOwnerFromTags_Incomplete(t *testing.T) {
+	assert.Nil(t, ownerFromTags(nil))
+	assert.Nil(t, ownerFromTags([]string{"kubernetes"}))
+	assert.Nil(t, ownerFromTags([]string{"imc-cluster-prod", "imc-name-foo"}))
+}
@@ -0,0 +1,269 @@
+package pingdom
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/cache"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("pingdom-provider")
+
+// Provider manages Pingdom HTTP checks.
+type Provider struct {
+	client           *client
+	config           config.PingdomConfig
+	builder          *builder
+	index            *checkIndex
+	sourceRangeCache *cache.Expiring
+}
+
+// NewProvider creates a new Pingdom provider with given PingdomConfig.
+func NewProvider(config config.PingdomConfig) *Provider {
+	return &Provider{
+		client:           newClient(config.APIToken),
+		config:           config,
+		builder:          newBuilder(config.MonitorDefaults),
+		index:            newCheckIndex(config.MonitorCacheRefreshInterval.Duration),
+		sourceRangeCache: cache.NewExpiring(),
+	}
+}
+
+// Create implements provider.Interface.
+func (p *Provider) Create(ctx context.Context, model *models.Monitor) error {
+	check, err := p.builder.FromModel(model)
+	if err != nil {
+		return errors.Wrapf(err, "failed to build pingdom check %q from model", model.Name)
+	}
+
+	check.ID, err = p.client.createCheck(ctx, check)
+	if err != nil {
+		// The check may have been created anyways, e.g. if the call timed
+		// out.
+		p.index.invalidate()
+		return errors.Wrapf(err, "failed to create pingdom check %q", model.Name)
+	}
+
+	p.index.set(summaryOf(check))
+
+	return nil
+}
+
+// Get implements provider.Interface. Checks are looked up in an index which
+// is refreshed periodically, only the details of the check with name are
+// retrieved.
+func (p *Provider) Get(ctx context.Context, name string) (*models.Monitor, error) {
+	summary, found, err := p.index.get(ctx, name, p.client.listChecks)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list pingdom checks")
+	}
+
+	if !found {
+		return nil, models.ErrMonitorNotFound
+	}
+
+	check, err := p.client.getCheck(ctx, summary.ID)
+	if err == errNotFound {
+		// The check was deleted in the meantime.
+		p.index.remove(name)
+		return nil, models.ErrMonitorNotFound
+	} else if err != nil {
+		return nil, errors.Wrapf(err, "failed to get pingdom check with ID %d", summary.ID)
+	}
+
+	m := &models.Monitor{
+		ID:    strconv.FormatInt(check.ID, 10),
+		Name:  check.Name,
+		URL:   check.url(),
+		Owner: check.owner(),
+	}
+
+	return m, nil
+}
+
+// Update implements provider.Interface.
+func (p *Provider) Update(ctx context.Context, model *models.Monitor) error {
+	check, err := p.builder.FromModel(model)
+	if err != nil {
+		return errors.Wrapf(err, "failed to build pingdom check %q from model", model.Name)
+	}
+
+	err = p.client.updateCheck(ctx, check)
+	if err != nil {
+		p.index.invalidate()
+		return errors.Wrapf(err, "failed to update pingdom check %q with ID %s", model.Name, model.ID)
+	}
+
+	p.index.set(summaryOf(check))
+
+	return nil
+}
+
+// Rename implements provider.Renamer. The check is updated in place and
+// keeps its ID.
+func (p *Provider) Rename(ctx context.Context, oldName string, model *models.Monitor) error {
+	existing, err := p.Get(ctx, oldName)
+	if err != nil {
+		return err
+	}
+
+	monitor := *model
+	monitor.ID = existing.ID
+
+	return p.Update(ctx, &monitor)
+}
+
+// Delete implements provider.Interface.
+func (p *Provider) Delete(ctx context.Context, name string) error {
+	monitor, err := p.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(monitor.ID, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid pingdom check ID %q", monitor.ID)
+	}
+
+	err = p.client.deleteCheck(ctx, id)
+	if err != nil && err != errNotFound {
+		p.index.invalidate()
+		return errors.Wrapf(err, "failed to delete pingdom check with ID %s", monitor.ID)
+	}
+
+	p.index.remove(name)
+
+	return nil
+}
+
+// List implements provider.Interface. Owned checks are identified by the
+// owner tags, which are part of the check summaries. Only the details of
+// owned checks are retrieved. The check index is refreshed as a side effect.
+func (p *Provider) List(ctx context.Context) ([]*models.Monitor, error) {
+	checks, err := p.index.refresh(ctx, p.client.listChecks)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list pingdom checks")
+	}
+
+	monitors := make([]*models.Monitor, 0, len(checks))
+
+	for _, summary := range checks {
+		if summary.Type != checkTypeHTTP || summary.owner() == nil {
+			continue
+		}
+
+		check, err := p.client.getCheck(ctx, summary.ID)
+		if err == errNotFound {
+			// The check was deleted in the meantime.
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to get pingdom check with ID %d", summary.ID)
+		}
+
+		owner := check.owner()
+		if owner == nil {
+			continue
+		}
+
+		monitors = append(monitors, &models.Monitor{
+			ID:    strconv.FormatInt(check.ID, 10),
+			Name:  check.Name,
+			URL:   check.url(),
+			Owner: owner,
+		})
+	}
+
+	return monitors, nil
+}
+
+// Check implements provider.Checker. The API token is verified by listing
+// the probe servers.
+func (p *Provider) Check(ctx context.Context) error {
+	_, err := p.client.listProbes(ctx)
+
+	return errors.Wrapf(err, "failed to list pingdom probes")
+}
+
+// GetIPSourceRanges implements provider.Interface. The source ranges are the
+// IPv4 addresses of all active Pingdom probes matching the check's probe
+// filters.
+func (p *Provider) GetIPSourceRanges(ctx context.Context, model *models.Monitor) ([]string, error) {
+	check, err := p.builder.FromModel(model)
+	if err != nil {
+		return nil, err
+	}
+
+	regions, err := parseRegionFilters(check.ProbeFilters)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey := strings.Join(regions, ",")
+
+	cachedSourceRanges, ok := p.sourceRangeCache.Get(cacheKey)
+	if ok {
+		return cachedSourceRanges.([]string), nil
+	}
+
+	probes, err := p.client.listProbes(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list pingdom probes")
+	}
+
+	sourceRanges := make([]string, 0, len(probes))
+
+	for _, probe := range probes {
+		if !probe.Active || probe.IP == "" || !matchesRegion(probe, regions) {
+			continue
+		}
+
+		sourceRanges = append(sourceRanges, probe.IP+"/32")
+	}
+
+	log.V(1).Info("found ip addresses for probes", "count", len(sourceRanges), "regions", regions, "ips", sourceRanges)
+
+	// The probe list rarely changes so we can just cache it for a day.
+	p.sourceRangeCache.Set(cacheKey, sourceRanges, 24*time.Hour)
+
+	return sourceRanges, nil
+}
+
+// parseRegionFilters extracts the regions from probe filters in the format
+// "region: <region>". The result is sorted.
+func parseRegionFilters(filters []string) ([]string, error) {
+	regions := make([]string, 0, len(filters))
+
+	for _, filter := range filters {
+		parts := strings.SplitN(filter, ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) != "region" {
+			return nil, errors.Errorf("unsupported probe filter %q", filter)
+		}
+
+		regions = append(regions, strings.TrimSpace(parts[1]))
+	}
+
+	sort.Strings(regions)
+
+	return regions, nil
+}
+
+func matchesRegion(probe *Probe, regions []string) bool {
+	if len(regions) == 0 {
+		return true
+	}
+
+	for _, region := range regions {
+		if strings.EqualFold(probe.Region, region) {
+			return true
+		}
+	}
+
+	return false
+}
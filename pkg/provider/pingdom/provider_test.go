@@ -0,0 +1,436 @@
+package pingdom
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/pingdom/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/cache"
+)
+
+func TestProvider_Create(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    *models.Monitor
+		config   config.PingdomConfig
+		validate func(*testing.T, *fake.Server)
+		expected string
+	}{
+		{
+			name: "creates check",
+			model: &models.Monitor{
+				Name: "my-monitor",
+				URL:  "https://my-monitor/health?foo=bar",
+			},
+			config: config.PingdomConfig{
+				MonitorDefaults: config.PingdomMonitorDefaults{
+					Resolution:     5,
+					IntegrationIDs: []int{1, 2},
+					Tags:           []string{"kubernetes"},
+				},
+			},
+			validate: func(t *testing.T, s *fake.Server) {
+				assert.Equal(t, []map[string]interface{}{
+					{
+						"id":             int64(1),
+						"name":           "my-monitor",
+						"host":           "my-monitor",
+						"type":           "http",
+						"url":            "/health?foo=bar",
+						"encryption":     true,
+						"resolution":     float64(5),
+						"integrationids": []interface{}{float64(1), float64(2)},
+						"probe_filters":  nil,
+						"tags":           []interface{}{"kubernetes"},
+						"teamids":        nil,
+						"userids":        nil,
+					},
+				}, s.Checks())
+			},
+		},
+		{
+			name: "annotations override check defaults",
+			model: &models.Monitor{
+				Name: "my-monitor",
+				URL:  "http://my-monitor:8080",
+				Annotations: config.Annotations{
+					config.AnnotationPingdomIntegrationIDs: "3",
+					config.AnnotationPingdomProbeFilters:   "region: EU",
+					config.AnnotationPingdomRequestHeaders: `{"X-Foo":"bar"}`,
+					config.AnnotationPingdomResolution:     "15",
+					config.AnnotationPingdomTags:           "foo,bar",
+					config.AnnotationPingdomTeamIDs:        "4,5",
+					config.AnnotationPingdomUserIDs:        "6",
+				},
+			},
+			config: config.PingdomConfig{
+				MonitorDefaults: config.PingdomMonitorDefaults{
+					Resolution:     5,
+					IntegrationIDs: []int{1, 2},
+					Tags:           []string{"kubernetes"},
+				},
+			},
+			validate: func(t *testing.T, s *fake.Server) {
+				assert.Equal(t, []map[string]interface{}{
+					{
+						"id":             int64(1),
+						"name":           "my-monitor",
+						"host":           "my-monitor",
+						"type":           "http",
+						"url":            "/",
+						"encryption":     false,
+						"port":           float64(8080),
+						"resolution":     float64(15),
+						"integrationids": []interface{}{float64(3)},
+						"probe_filters":  []interface{}{"region: EU"},
+						"requestheaders": map[string]interface{}{"X-Foo": "bar"},
+						"tags":           []interface{}{"foo", "bar"},
+						"teamids":        []interface{}{float64(4), float64(5)},
+						"userids":        []interface{}{float64(6)},
+					},
+				}, s.Checks())
+			},
+		},
+		{
+			name: "marks check with owner tags",
+			model: &models.Monitor{
+				Name:  "my-monitor",
+				URL:   "https://my-monitor",
+				Owner: &models.Owner{ClusterID: "prod", Namespace: "kube-system", Name: "my-ingress", UID: "1234"},
+				Annotations: config.Annotations{
+					config.AnnotationPingdomTags: "foo,imc-name-stale",
+				},
+			},
+			validate: func(t *testing.T, s *fake.Server) {
+				checks := s.Checks()
+				require.Len(t, checks, 1)
+				assert.Equal(t, []interface{}{"foo", "imc-cluster-prod", "imc-namespace-kube-system", "imc-name-my-ingress", "imc-uid-1234"}, checks[0]["tags"])
+				assert.NotContains(t, checks[0], "requestheaders")
+			},
+		},
+		{
+			name: "do not create check if the ingress annotations are invalid",
+			model: &models.Monitor{
+				Name: "my-monitor",
+				URL:  "http://my-monitor",
+				Annotations: config.Annotations{
+					config.AnnotationPingdomRequestHeaders: "{invalidjson",
+				},
+			},
+			validate: func(t *testing.T, s *fake.Server) {
+				assert.Len(t, s.Requests(), 0)
+			},
+			expected: `failed to build pingdom check "my-monitor" from model: invalid json in annotation "pingdom.ingress-monitor.bonial.com/request-headers": {invalidjson: invalid character 'i' looking for beginning of object key string`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, s := newTestProvider(t, test.config)
+
+			err := p.Create(context.Background(), test.model)
+			if test.expected != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.expected, err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+
+			if test.validate != nil {
+				test.validate(t, s)
+			}
+		})
+	}
+}
+
+func TestProvider_Update(t *testing.T) {
+	p, s := newTestProvider(t, config.PingdomConfig{
+		MonitorDefaults: config.PingdomMonitorDefaults{
+			Resolution: 1,
+		},
+	})
+
+	s.AddCheck(map[string]interface{}{
+		"name": "my-monitor",
+		"host": "my-monitor",
+		"url":  "/",
+	})
+
+	err := p.Update(context.Background(), &models.Monitor{
+		ID:   "1",
+		Name: "my-monitor",
+		URL:  "https://my-monitor.example.com/health",
+	})
+	require.NoError(t, err)
+
+	checks := s.Checks()
+	require.Len(t, checks, 1)
+	assert.Equal(t, "my-monitor.example.com", checks[0]["host"])
+	assert.Equal(t, "/health", checks[0]["url"])
+	assert.Equal(t, true, checks[0]["encryption"])
+	assert.Equal(t, "http", checks[0]["type"])
+
+	err = p.Update(context.Background(), &models.Monitor{
+		ID:   "42",
+		Name: "my-monitor",
+		URL:  "https://my-monitor.example.com/health",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to update pingdom check")
+}
+
+func TestProvider_Get(t *testing.T) {
+	tests := []struct {
+		name        string
+		monitorName string
+		setup       func(*fake.Server)
+		expected    *models.Monitor
+		expectedErr error
+	}{
+		{
+			name:        "returns models.ErrMonitorNotFound if check is not found",
+			monitorName: "my-monitor",
+			setup: func(s *fake.Server) {
+				s.AddCheck(map[string]interface{}{"name": "some-other-monitor"})
+			},
+			expectedErr: models.ErrMonitorNotFound,
+		},
+		{
+			name:        "ignores checks that are not http checks",
+			monitorName: "my-monitor",
+			setup: func(s *fake.Server) {
+				s.AddCheck(map[string]interface{}{"name": "my-monitor", "type": "ping"})
+			},
+			expectedErr: models.ErrMonitorNotFound,
+		},
+		{
+			name:        "returns check with name",
+			monitorName: "my-monitor",
+			setup: func(s *fake.Server) {
+				s.AddCheck(map[string]interface{}{"name": "some-other-monitor"})
+				s.AddCheck(map[string]interface{}{
+					"name":       "my-monitor",
+					"host":       "my-monitor",
+					"url":        "/health",
+					"encryption": true,
+					"port":       8443,
+				})
+			},
+			expected: &models.Monitor{
+				ID:   "2",
+				Name: "my-monitor",
+				URL:  "https://my-monitor:8443/health",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, s := newTestProvider(t, config.PingdomConfig{})
+
+			if test.setup != nil {
+				test.setup(s)
+			}
+
+			monitor, err := p.Get(context.Background(), test.monitorName)
+			if test.expectedErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, test.expectedErr.Error(), err.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, monitor)
+			}
+		})
+	}
+}
+
+func TestProvider_List(t *testing.T) {
+	p, s := newTestProvider(t, config.PingdomConfig{})
+
+	s.AddCheck(map[string]interface{}{"name": "unowned", "host": "unowned", "tags": []interface{}{"kubernetes"}})
+	s.AddCheck(map[string]interface{}{
+		"name": "ping",
+		"type": "ping",
+		"tags": []interface{}{"imc-namespace-kube-system", "imc-name-ping"},
+	})
+
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{
+		Name:  "owned",
+		URL:   "https://owned/health",
+		Owner: &models.Owner{Namespace: "kube-system", Name: "foo"},
+	}))
+
+	monitors, err := p.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Monitor{
+		{
+			ID:    "3",
+			Name:  "owned",
+			URL:   "https://owned/health",
+			Owner: &models.Owner{Namespace: "kube-system", Name: "foo"},
+		},
+	}, monitors)
+}
+
+func TestProvider_Delete(t *testing.T) {
+	tests := []struct {
+		name        string
+		monitorName string
+		setup       func(*fake.Server)
+		validate    func(*testing.T, *fake.Server)
+		expected    error
+	}{
+		{
+			name:        "returns if check is not found",
+			monitorName: "my-monitor",
+			expected:    models.ErrMonitorNotFound,
+		},
+		{
+			name:        "deletes check",
+			monitorName: "my-monitor",
+			setup: func(s *fake.Server) {
+				s.AddCheck(map[string]interface{}{"name": "some-other-monitor"})
+				s.AddCheck(map[string]interface{}{"name": "my-monitor"})
+			},
+			validate: func(t *testing.T, s *fake.Server) {
+				checks := s.Checks()
+				require.Len(t, checks, 1)
+				assert.Equal(t, "some-other-monitor", checks[0]["name"])
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, s := newTestProvider(t, config.PingdomConfig{})
+
+			if test.setup != nil {
+				test.setup(s)
+			}
+
+			err := p.Delete(context.Background(), test.monitorName)
+			if test.expected != nil {
+				require.Error(t, err)
+				assert.Equal(t, test.expected.Error(), err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+
+			if test.validate != nil {
+				test.validate(t, s)
+			}
+		})
+	}
+}
+
+func TestProvider_GetIPSourceRanges(t *testing.T) {
+	probes := []map[string]interface{}{
+		{"id": 1, "active": true, "ip": "1.1.1.1", "region": "EU"},
+		{"id": 2, "active": true, "ip": "2.2.2.2", "region": "NA"},
+		{"id": 3, "active": false, "ip": "3.3.3.3", "region": "EU"},
+		{"id": 4, "active": true, "ip": "4.4.4.4", "region": "APAC"},
+	}
+
+	tests := []struct {
+		name        string
+		model       *models.Monitor
+		expected    []string
+		expectedErr string
+	}{
+		{
+			name:     "returns ips of all active probes",
+			model:    &models.Monitor{Name: "foo", URL: "https://foo"},
+			expected: []string{"1.1.1.1/32", "2.2.2.2/32", "4.4.4.4/32"},
+		},
+		{
+			name: "only returns ips of probes matching the probe filters",
+			model: &models.Monitor{
+				Name: "foo",
+				URL:  "https://foo",
+				Annotations: config.Annotations{
+					config.AnnotationPingdomProbeFilters: "region: EU,region:APAC",
+				},
+			},
+			expected: []string{"1.1.1.1/32", "4.4.4.4/32"},
+		},
+		{
+			name: "unsupported probe filters cause an error",
+			model: &models.Monitor{
+				Name: "foo",
+				URL:  "https://foo",
+				Annotations: config.Annotations{
+					config.AnnotationPingdomProbeFilters: "country: DE",
+				},
+			},
+			expectedErr: `unsupported probe filter "country: DE"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, s := newTestProvider(t, config.PingdomConfig{})
+			s.Probes = probes
+
+			ips, err := p.GetIPSourceRanges(context.Background(), test.model)
+			if test.expectedErr != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.expectedErr, err.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, ips)
+			}
+		})
+	}
+}
+
+func TestProvider_GetIPSourceRanges_Cache(t *testing.T) {
+	p, s := newTestProvider(t, config.PingdomConfig{})
+	s.Probes = []map[string]interface{}{
+		{"id": 1, "active": true, "ip": "1.1.1.1", "region": "EU"},
+	}
+
+	model := &models.Monitor{Name: "foo", URL: "https://foo"}
+
+	ips, err := p.GetIPSourceRanges(context.Background(), model)
+	require.NoError(t, err)
+	require.Equal(t, []string{"1.1.1.1/32"}, ips)
+
+	ips2, err := p.GetIPSourceRanges(context.Background(), model)
+	require.NoError(t, err)
+	require.Equal(t, ips, ips2)
+
+	// Only expect one API call to fetch the probes.
+	assert.Equal(t, []string{"GET /probes"}, s.Requests())
+}
+
+func newTestProvider(t *testing.T, config config.PingdomConfig) (*Provider, *fake.Server) {
+	server := fake.NewServer("the-api-token")
+	t.Cleanup(server.Close)
+
+	client := newClient("the-api-token")
+	client.baseURL = server.APIBaseURL()
+
+	provider := &Provider{
+		client:           client,
+		config:           config,
+		builder:          newBuilder(config.MonitorDefaults),
+		index:            newCheckIndex(config.MonitorCacheRefreshInterval.Duration),
+		sourceRangeCache: cache.NewExpiring(),
+	}
+
+	return provider, server
+}
+
+func TestProvider_Check(t *testing.T) {
+	p, _ := newTestProvider(t, config.PingdomConfig{})
+
+	require.NoError(t, p.Check(context.Background()))
+
+	p.client.apiToken = "invalid"
+
+	require.Error(t, p.Check(context.Background()))
+}
@@ -0,0 +1,91 @@
+package plugin
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/pkg/errors"
+)
+
+// ServiceName is the name of the RPC service that plugins have to serve. The
+// protocol is JSON-RPC 1.0 as implemented by net/rpc/jsonrpc. The service
+// exposes the methods Create, Get, Update, Delete, List and
+// GetIPSourceRanges which mirror provider.Interface.
+const ServiceName = "Provider"
+
+// Interface mirrors provider.Interface. It is duplicated here to avoid an
+// import cycle between the provider and the plugin package. Any
+// provider.Interface can be served as a plugin.
+type Interface interface {
+	Create(ctx context.Context, model *models.Monitor) error
+	Get(ctx context.Context, name string) (*models.Monitor, error)
+	Update(ctx context.Context, model *models.Monitor) error
+	Delete(ctx context.Context, name string) error
+	List(ctx context.Context) ([]*models.Monitor, error)
+	GetIPSourceRanges(ctx context.Context, model *models.Monitor) ([]string, error)
+}
+
+// MonitorArgs are the arguments for the Create, Update and
+// GetIPSourceRanges methods.
+type MonitorArgs struct {
+	Monitor *models.Monitor
+}
+
+// NameArgs are the arguments for the Get and Delete methods.
+type NameArgs struct {
+	Name string
+}
+
+// GetReply is the reply of the Get method. NotFound is set instead of
+// returning an error if the monitor does not exist.
+type GetReply struct {
+	Monitor  *models.Monitor
+	NotFound bool
+}
+
+// DeleteReply is the reply of the Delete method. NotFound is set instead of
+// returning an error if the monitor does not exist.
+type DeleteReply struct {
+	NotFound bool
+}
+
+// ListReply is the reply of the List method.
+type ListReply struct {
+	Monitors []*models.Monitor
+}
+
+// SourceRangesReply is the reply of the GetIPSourceRanges method.
+type SourceRangesReply struct {
+	SourceRanges []string
+}
+
+// Empty is the reply of methods that do not return anything besides an
+// error.
+type Empty struct{}
+
+// Listen creates a listener for address, which has the form
+// unix:///path/to/socket or tcp://host:port.
+func Listen(address string) (net.Listener, error) {
+	network, addr, err := parseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	return net.Listen(network, addr)
+}
+
+func parseAddress(address string) (network, addr string, err error) {
+	parts := strings.SplitN(address, "://", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return "", "", errors.Errorf("invalid plugin address %q, expected unix:///path or tcp://host:port", address)
+	}
+
+	switch parts[0] {
+	case "unix", "tcp":
+		return parts[0], parts[1], nil
+	default:
+		return "", "", errors.Errorf("unsupported network %q in plugin address %q", parts[0], address)
+	}
+}
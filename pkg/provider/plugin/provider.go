@@ -0,0 +1,269 @@
+package plugin
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/pkg/errors"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("plugin-provider")
+
+const defaultDialTimeout = 10 * time.Second
+
+// Provider forwards all monitor operations to an out-of-process plugin. The
+// plugin is either launched as a child process or dialed at a configured
+// address. The connection is established lazily and re-established after it
+// broke.
+type Provider struct {
+	config config.PluginConfig
+
+	mu     sync.Mutex
+	client *rpc.Client
+}
+
+// NewProvider creates a new plugin provider with given PluginConfig.
+func NewProvider(config config.PluginConfig) *Provider {
+	return &Provider{
+		config: config,
+	}
+}
+
+// Create implements provider.Interface.
+func (p *Provider) Create(ctx context.Context, model *models.Monitor) error {
+	return p.call(ctx, "Create", &MonitorArgs{Monitor: model}, &Empty{})
+}
+
+// Get implements provider.Interface.
+func (p *Provider) Get(ctx context.Context, name string) (*models.Monitor, error) {
+	var reply GetReply
+
+	err := p.call(ctx, "Get", &NameArgs{Name: name}, &reply)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply.NotFound || reply.Monitor == nil {
+		return nil, models.ErrMonitorNotFound
+	}
+
+	return reply.Monitor, nil
+}
+
+// Update implements provider.Interface.
+func (p *Provider) Update(ctx context.Context, model *models.Monitor) error {
+	return p.call(ctx, "Update", &MonitorArgs{Monitor: model}, &Empty{})
+}
+
+// Delete implements provider.Interface.
+func (p *Provider) Delete(ctx context.Context, name string) error {
+	var reply DeleteReply
+
+	err := p.call(ctx, "Delete", &NameArgs{Name: name}, &reply)
+	if err != nil {
+		return err
+	}
+
+	if reply.NotFound {
+		return models.ErrMonitorNotFound
+	}
+
+	return nil
+}
+
+// List implements provider.Interface.
+func (p *Provider) List(ctx context.Context) ([]*models.Monitor, error) {
+	var reply ListReply
+
+	err := p.call(ctx, "List", &Empty{}, &reply)
+	if err != nil {
+		return nil, err
+	}
+
+	return reply.Monitors, nil
+}
+
+// GetIPSourceRanges implements provider.Interface.
+func (p *Provider) GetIPSourceRanges(ctx context.Context, model *models.Monitor) ([]string, error) {
+	var reply SourceRangesReply
+
+	err := p.call(ctx, "GetIPSourceRanges", &MonitorArgs{Monitor: model}, &reply)
+	if err != nil {
+		return nil, err
+	}
+
+	return reply.SourceRanges, nil
+}
+
+// Close closes the connection to the plugin. If the plugin was launched by
+// the provider, the plugin process is terminated.
+func (p *Provider) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client == nil {
+		return nil
+	}
+
+	err := p.client.Close()
+	p.client = nil
+
+	return err
+}
+
+func (p *Provider) call(ctx context.Context, method string, args, reply interface{}) error {
+	client, err := p.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	// net/rpc does not support contexts. If ctx is done before the call
+	// completes, we return early and the reply is discarded once it arrives.
+	select {
+	case call := <-client.Go(ServiceName+"."+method, args, reply, make(chan *rpc.Call, 1)).Done:
+		err = call.Error
+	case <-ctx.Done():
+		return errors.Wrapf(ctx.Err(), "plugin call %s aborted", method)
+	}
+
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(rpc.ServerError); !ok {
+		// The connection broke. Drop the client so that the next call
+		// reconnects.
+		p.reset(client)
+	}
+
+	return errors.Wrapf(err, "plugin call %s failed", method)
+}
+
+func (p *Provider) connect(ctx context.Context) (*rpc.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client != nil {
+		return p.client, nil
+	}
+
+	var (
+		conn io.ReadWriteCloser
+		err  error
+	)
+
+	if p.config.Command != "" {
+		conn, err = p.launch()
+	} else {
+		conn, err = p.dial(ctx)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	p.client = jsonrpc.NewClient(conn)
+
+	return p.client, nil
+}
+
+func (p *Provider) reset(client *rpc.Client) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client != client {
+		return
+	}
+
+	client.Close()
+	p.client = nil
+}
+
+func (p *Provider) dial(ctx context.Context) (io.ReadWriteCloser, error) {
+	network, addr, err := parseAddress(p.config.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := p.config.DialTimeout.Duration
+	if timeout <= 0 {
+		timeout = defaultDialTimeout
+	}
+
+	dialer := &net.Dialer{Timeout: timeout}
+
+	conn, err := dialer.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to plugin at %s", p.config.Address)
+	}
+
+	return conn, nil
+}
+
+func (p *Provider) launch() (io.ReadWriteCloser, error) {
+	cmd := exec.Command(p.config.Command, p.config.Args...)
+	cmd.Env = os.Environ()
+	cmd.Stderr = os.Stderr
+
+	for name, value := range p.config.Env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to launch plugin %s", p.config.Command)
+	}
+
+	log.Info("plugin launched", "command", p.config.Command, "pid", cmd.Process.Pid)
+
+	return &processConn{Reader: stdout, stdin: stdin, cmd: cmd}, nil
+}
+
+// processConn speaks to a plugin process via its stdin and stdout.
+type processConn struct {
+	io.Reader
+	stdin io.WriteCloser
+	cmd   *exec.Cmd
+}
+
+func (c *processConn) Write(p []byte) (int, error) {
+	return c.stdin.Write(p)
+}
+
+// Close closes stdin of the plugin process, which signals the plugin to
+// exit, and waits for the process to terminate. The process is killed if it
+// does not exit in time.
+func (c *processConn) Close() error {
+	c.stdin.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- c.cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(5 * time.Second):
+		c.cmd.Process.Kill()
+		return <-done
+	}
+}
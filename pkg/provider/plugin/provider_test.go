@@ -0,0 +1,254 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/null"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// servePluginEnv makes the test binary act as a plugin serving the null
+// provider on stdin/stdout. This allows testing plugins that are launched by
+// the provider without building a separate binary.
+const servePluginEnv = "INGRESS_MONITOR_CONTROLLER_SERVE_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(servePluginEnv) == "1" {
+		if err := ServeStdio(&null.Provider{}); err != nil {
+			os.Exit(1)
+		}
+
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// memoryProvider is a minimal in-memory implementation of Interface which is
+// used to verify that the plugin protocol preserves the provider semantics.
+type memoryProvider struct {
+	mu       sync.Mutex
+	nextID   int
+	monitors map[string]*models.Monitor
+}
+
+func newMemoryProvider() *memoryProvider {
+	return &memoryProvider{monitors: make(map[string]*models.Monitor)}
+}
+
+func (p *memoryProvider) Create(_ context.Context, model *models.Monitor) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, found := p.monitors[model.Name]; found {
+		return errors.New("monitor already exists")
+	}
+
+	p.nextID++
+
+	monitor := *model
+	monitor.ID = strconv.Itoa(p.nextID)
+	p.monitors[model.Name] = &monitor
+
+	return nil
+}
+
+func (p *memoryProvider) Get(_ context.Context, name string) (*models.Monitor, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	monitor, found := p.monitors[name]
+	if !found {
+		return nil, models.ErrMonitorNotFound
+	}
+
+	return monitor, nil
+}
+
+func (p *memoryProvider) Update(_ context.Context, model *models.Monitor) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	monitor := *model
+	p.monitors[model.Name] = &monitor
+
+	return nil
+}
+
+func (p *memoryProvider) Delete(_ context.Context, name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, found := p.monitors[name]; !found {
+		return models.ErrMonitorNotFound
+	}
+
+	delete(p.monitors, name)
+
+	return nil
+}
+
+func (p *memoryProvider) List(_ context.Context) ([]*models.Monitor, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	monitors := make([]*models.Monitor, 0, len(p.monitors))
+
+	for _, monitor := range p.monitors {
+		if monitor.Owner != nil {
+			monitors = append(monitors, monitor)
+		}
+	}
+
+	return monitors, nil
+}
+
+func (p *memoryProvider) GetIPSourceRanges(_ context.Context, _ *models.Monitor) ([]string, error) {
+	return []string{"10.0.0.0/8"}, nil
+}
+
+func startServer(t *testing.T, impl Interface) string {
+	address := "unix://" + filepath.Join(t.TempDir(), "plugin.sock")
+
+	l, err := Listen(address)
+	require.NoError(t, err)
+
+	go Serve(l, impl)
+
+	t.Cleanup(func() { l.Close() })
+
+	return address
+}
+
+func TestProvider_Conformance(t *testing.T) {
+	address := startServer(t, newMemoryProvider())
+
+	p := NewProvider(config.PluginConfig{Address: address})
+	defer p.Close()
+
+	_, err := p.Get(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	err = p.Delete(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	monitor := &models.Monitor{
+		Name: "foo",
+		URL:  "https://foo.example.com",
+		Annotations: config.Annotations{
+			config.AnnotationEnabled: "true",
+		},
+		Owner: &models.Owner{Namespace: "kube-system", Name: "foo"},
+	}
+
+	require.NoError(t, p.Create(context.Background(), monitor))
+
+	err = p.Create(context.Background(), monitor)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "monitor already exists")
+
+	created, err := p.Get(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, &models.Monitor{
+		ID:          "1",
+		Name:        "foo",
+		URL:         "https://foo.example.com",
+		Annotations: monitor.Annotations,
+		Owner:       monitor.Owner,
+	}, created)
+
+	monitors, err := p.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Monitor{created}, monitors)
+
+	created.URL = "https://bar.example.com"
+	require.NoError(t, p.Update(context.Background(), created))
+
+	updated, err := p.Get(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, created, updated)
+
+	sourceRanges, err := p.GetIPSourceRanges(context.Background(), updated)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.0/8"}, sourceRanges)
+
+	require.NoError(t, p.Delete(context.Background(), "foo"))
+
+	_, err = p.Get(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+}
+
+func TestProvider_NullPlugin(t *testing.T) {
+	address := startServer(t, &null.Provider{})
+
+	p := NewProvider(config.PluginConfig{Address: address})
+	defer p.Close()
+
+	monitor := &models.Monitor{Name: "foo", URL: "http://foo"}
+
+	require.NoError(t, p.Create(context.Background(), monitor))
+	require.NoError(t, p.Update(context.Background(), monitor))
+	require.NoError(t, p.Delete(context.Background(), "foo"))
+
+	_, err := p.Get(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	sourceRanges, err := p.GetIPSourceRanges(context.Background(), monitor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1/32"}, sourceRanges)
+}
+
+func TestProvider_LaunchCommand(t *testing.T) {
+	p := NewProvider(config.PluginConfig{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^$"},
+		Env: map[string]string{
+			servePluginEnv: "1",
+		},
+	})
+
+	_, err := p.Get(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	sourceRanges, err := p.GetIPSourceRanges(context.Background(), &models.Monitor{Name: "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1/32"}, sourceRanges)
+
+	require.NoError(t, p.Close())
+}
+
+func TestProvider_Reconnect(t *testing.T) {
+	address := startServer(t, newMemoryProvider())
+
+	p := NewProvider(config.PluginConfig{Address: address})
+	defer p.Close()
+
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{Name: "foo"}))
+
+	// Simulate a broken connection.
+	p.client.Close()
+
+	_, err := p.Get(context.Background(), "foo")
+	require.Error(t, err)
+
+	monitor, err := p.Get(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", monitor.Name)
+}
+
+func TestProvider_DialError(t *testing.T) {
+	p := NewProvider(config.PluginConfig{Address: "unix://" + filepath.Join(t.TempDir(), "missing.sock")})
+
+	err := p.Create(context.Background(), &models.Monitor{Name: "foo"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect to plugin")
+}
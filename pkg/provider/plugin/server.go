@@ -0,0 +1,129 @@
+package plugin
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+)
+
+// Serve accepts connections on l and serves impl on each of them. It blocks
+// until l is closed.
+func Serve(l net.Listener, impl Interface) error {
+	server, err := newRPCServer(impl)
+	if err != nil {
+		return err
+	}
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+}
+
+// ServeConn serves impl on conn. It blocks until the client hangs up.
+func ServeConn(conn io.ReadWriteCloser, impl Interface) error {
+	server, err := newRPCServer(impl)
+	if err != nil {
+		return err
+	}
+
+	server.ServeCodec(jsonrpc.NewServerCodec(conn))
+
+	return nil
+}
+
+// ServeStdio serves impl on stdin and stdout. This is used by plugins which
+// are launched by the controller.
+func ServeStdio(impl Interface) error {
+	return ServeConn(&stdioConn{Reader: os.Stdin, Writer: os.Stdout}, impl)
+}
+
+func newRPCServer(impl Interface) (*rpc.Server, error) {
+	server := rpc.NewServer()
+
+	err := server.RegisterName(ServiceName, &service{impl: impl})
+	if err != nil {
+		return nil, err
+	}
+
+	return server, nil
+}
+
+// service adapts an Interface to the method signatures required by net/rpc.
+// The protocol does not propagate cancellation, so the calls are performed
+// with a background context. Clients abort calls on their side.
+type service struct {
+	impl Interface
+}
+
+func (s *service) Create(args *MonitorArgs, _ *Empty) error {
+	return s.impl.Create(context.Background(), args.Monitor)
+}
+
+func (s *service) Get(args *NameArgs, reply *GetReply) error {
+	monitor, err := s.impl.Get(context.Background(), args.Name)
+	if err == models.ErrMonitorNotFound {
+		reply.NotFound = true
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	reply.Monitor = monitor
+
+	return nil
+}
+
+func (s *service) Update(args *MonitorArgs, _ *Empty) error {
+	return s.impl.Update(context.Background(), args.Monitor)
+}
+
+func (s *service) Delete(args *NameArgs, reply *DeleteReply) error {
+	err := s.impl.Delete(context.Background(), args.Name)
+	if err == models.ErrMonitorNotFound {
+		reply.NotFound = true
+		return nil
+	}
+
+	return err
+}
+
+func (s *service) List(_ *Empty, reply *ListReply) error {
+	monitors, err := s.impl.List(context.Background())
+	if err != nil {
+		return err
+	}
+
+	reply.Monitors = monitors
+
+	return nil
+}
+
+func (s *service) GetIPSourceRanges(args *MonitorArgs, reply *SourceRangesReply) error {
+	sourceRanges, err := s.impl.GetIPSourceRanges(context.Background(), args.Monitor)
+	if err != nil {
+		return err
+	}
+
+	reply.SourceRanges = sourceRanges
+
+	return nil
+}
+
+type stdioConn struct {
+	io.Reader
+	io.Writer
+}
+
+func (c *stdioConn) Close() error {
+	return nil
+}
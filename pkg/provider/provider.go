@@ -1,47 +1,193 @@
 package provider
 
 import (
+	"context"
+	"strings"
+
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/blackbox"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/local"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/null"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/pingdom"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/plugin"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/site24x7"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/uptimerobot"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/webhook"
 	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// Interface is the interface for a monitor provider.
+// Interface is the interface for a monitor provider. Implementations must
+// abort API calls once the passed context is done.
 type Interface interface {
 	// Create creates a monitor based on the given model. Must return an error
 	// if the monitor creation fails.
-	Create(model *models.Monitor) error
+	Create(ctx context.Context, model *models.Monitor) error
 
 	// Get retrieves a monitor by its name. Must return
 	// models.ErrMonitorNotFound if the monitor does not exist.
-	Get(name string) (*models.Monitor, error)
+	Get(ctx context.Context, name string) (*models.Monitor, error)
 
 	// Update updates a monitor based on the given model. Must return an error
 	// if the monitor update fails.
-	Update(model *models.Monitor) error
+	Update(ctx context.Context, model *models.Monitor) error
 
 	// Delete delete a monitor by its name. Must return an error if the monitor
 	// deletion fails.
-	Delete(name string) error
+	Delete(ctx context.Context, name string) error
+
+	// List returns all monitors that are owned by the controller. Monitors
+	// not created by the controller must be omitted and the Owner of all
+	// returned monitors must be set.
+	List(ctx context.Context) ([]*models.Monitor, error)
 
 	// GetIPSourceRanges returns a list of CIDR blocks that the provider is
 	// performing the monitoring checks from. The source ranges are
 	// automatically added to the source range whitelist of the
 	// nginx-ingress-controller if an ingress uses whitelisting.
-	GetIPSourceRanges(model *models.Monitor) ([]string, error)
+	GetIPSourceRanges(ctx context.Context, model *models.Monitor) ([]string, error)
+}
+
+// Renamer is an optional interface for providers that are able to rename
+// monitors in place, preserving the monitor ID and its history.
+type Renamer interface {
+	// Rename renames the monitor oldName to the name of model and updates it
+	// based on model. Must return models.ErrMonitorNotFound if the monitor
+	// oldName does not exist.
+	Rename(ctx context.Context, oldName string, model *models.Monitor) error
+}
+
+// Rename renames the monitor oldName of p to the name of model. If p does not
+// implement Renamer, the monitor is recreated under the new name and the old
+// monitor is deleted afterwards. Returns models.ErrMonitorNotFound if the
+// monitor oldName does not exist.
+func Rename(ctx context.Context, p Interface, oldName string, model *models.Monitor) error {
+	if r, ok := p.(Renamer); ok {
+		return r.Rename(ctx, oldName, model)
+	}
+
+	_, err := p.Get(ctx, oldName)
+	if err != nil {
+		return err
+	}
+
+	monitor := *model
+	monitor.ID = ""
+
+	err = p.Create(ctx, &monitor)
+	if err != nil {
+		return err
+	}
+
+	err = p.Delete(ctx, oldName)
+	if err != nil && err != models.ErrMonitorNotFound {
+		return err
+	}
+
+	return nil
+}
+
+// Differ is an optional interface for providers that are able to detect
+// configuration drift. Monitors of providers which do not implement Differ
+// are updated on every sync.
+type Differ interface {
+	// Diff compares the existing monitor, as returned by Get, with the
+	// monitor that would be built from model. Returns the fields that differ
+	// or an empty result if the monitor is up to date. Must return
+	// models.ErrDiffNotSupported if existing lacks the information needed to
+	// compare it.
+	Diff(ctx context.Context, existing, model *models.Monitor) ([]models.FieldDiff, error)
+}
+
+// Diff compares the existing monitor of p with model. Returns
+// models.ErrDiffNotSupported if p does not implement Differ.
+func Diff(ctx context.Context, p Interface, existing, model *models.Monitor) ([]models.FieldDiff, error) {
+	if d, ok := p.(Differ); ok {
+		return d.Diff(ctx, existing, model)
+	}
+
+	return nil, models.ErrDiffNotSupported
 }
 
-// New creates a new monitor provider by name. Returns an error if the named
+// OwnerAwareDeleter is an optional interface for providers that are able to
+// verify the owner of a monitor before deleting it.
+type OwnerAwareDeleter interface {
+	// DeleteOwned deletes the monitor name on behalf of owner. Must return
+	// models.ErrMonitorNotOwned if the monitor is owned by another resource.
+	DeleteOwned(ctx context.Context, name string, owner *models.Owner) error
+}
+
+// DeleteOwned deletes the monitor name of p on behalf of owner. If p does not
+// implement OwnerAwareDeleter, the monitor is deleted regardless of its
+// owner.
+func DeleteOwned(ctx context.Context, p Interface, name string, owner *models.Owner) error {
+	if d, ok := p.(OwnerAwareDeleter); ok {
+		return d.DeleteOwned(ctx, name, owner)
+	}
+
+	return p.Delete(ctx, name)
+}
+
+// Checker is an optional interface for providers that are able to verify
+// that they are usable, e.g. that the configured credentials are valid.
+type Checker interface {
+	// Check returns an error if the provider is not usable.
+	Check(ctx context.Context) error
+}
+
+// Check verifies that p is usable. Providers which do not implement Checker
+// are always considered usable.
+func Check(ctx context.Context, p Interface) error {
+	if c, ok := p.(Checker); ok {
+		return c.Check(ctx)
+	}
+
+	return nil
+}
+
+// New creates a new monitor provider by name. The client is used by providers
+// that manage resources inside of the cluster. Returns an error if the named
 // provider is not supported.
-func New(name string, c config.ProviderConfig) (Interface, error) {
+func New(name string, c config.ProviderConfig, client client.Client) (Interface, error) {
 	switch name {
 	case config.ProviderSite24x7:
 		return site24x7.NewProvider(c.Site24x7), nil
+	case config.ProviderUptimeRobot:
+		return uptimerobot.NewProvider(c.UptimeRobot), nil
+	case config.ProviderPingdom:
+		return pingdom.NewProvider(c.Pingdom), nil
+	case config.ProviderBlackbox:
+		return blackbox.NewProvider(client, c.Blackbox), nil
+	case config.ProviderLocal:
+		return local.NewProvider(c.Local), nil
+	case config.ProviderWebhook:
+		p, err := webhook.NewProvider(c.Webhook)
+		if err != nil {
+			return nil, err
+		}
+
+		return p, nil
 	case config.ProviderNull:
 		return &null.Provider{}, nil
 	default:
+		if strings.HasPrefix(name, config.ProviderPluginPrefix) {
+			return newPluginProvider(strings.TrimPrefix(name, config.ProviderPluginPrefix), c)
+		}
+
 		return nil, errors.Errorf("unsupported provider %q", name)
 	}
 }
+
+func newPluginProvider(name string, c config.ProviderConfig) (Interface, error) {
+	pluginConfig, ok := c.Plugins[name]
+	if !ok {
+		return nil, errors.Errorf("plugin %q is not configured", name)
+	}
+
+	if pluginConfig.Command == "" && pluginConfig.Address == "" {
+		return nil, errors.Errorf("plugin %q needs either a command or an address", name)
+	}
+
+	return plugin.NewProvider(pluginConfig), nil
+}
@@ -0,0 +1,111 @@
+package site24x7
+
+import (
+	site24x7api "github.com/Bonial-International-GmbH/site24x7-go/api"
+	"github.com/Bonial-International-GmbH/site24x7-go/rest"
+)
+
+const contentTypeJSON = "application/json;charset=UTF-8"
+
+// taggedMonitor is a Site24x7 website monitor together with the IDs of its
+// tags.
+type taggedMonitor struct {
+	*site24x7api.Monitor
+
+	TagIDs []string `json:"tag_ids,omitempty"`
+}
+
+// tag is a Site24x7 tag.
+type tag struct {
+	TagID    string `json:"tag_id,omitempty"`
+	TagName  string `json:"tag_name"`
+	TagValue string `json:"tag_value"`
+	TagColor string `json:"tag_color,omitempty"`
+}
+
+// monitorClient manages website monitors and tags via the Site24x7 API. The
+// site24x7-go client does not support tags, which are needed to mark the
+// monitors owned by the controller, so monitors are not managed through it.
+type monitorClient struct {
+	client rest.Client
+}
+
+func newMonitorClient(httpClient rest.HTTPClient, baseURL string) *monitorClient {
+	return &monitorClient{client: rest.NewClient(httpClient, baseURL)}
+}
+
+func (c *monitorClient) List() ([]*taggedMonitor, error) {
+	monitors := []*taggedMonitor{}
+
+	err := c.client.
+		Get().
+		Resource("monitors").
+		Do().
+		Into(&monitors)
+
+	return monitors, err
+}
+
+func (c *monitorClient) Create(m *taggedMonitor) (*taggedMonitor, error) {
+	created := &taggedMonitor{}
+
+	err := c.client.
+		Post().
+		Resource("monitors").
+		AddHeader("Content-Type", contentTypeJSON).
+		Body(m).
+		Do().
+		Into(created)
+
+	return created, err
+}
+
+func (c *monitorClient) Update(m *taggedMonitor) (*taggedMonitor, error) {
+	updated := &taggedMonitor{}
+
+	err := c.client.
+		Put().
+		Resource("monitors").
+		ResourceID(m.MonitorID).
+		AddHeader("Content-Type", contentTypeJSON).
+		Body(m).
+		Do().
+		Into(updated)
+
+	return updated, err
+}
+
+func (c *monitorClient) Delete(monitorID string) error {
+	return c.client.
+		Delete().
+		Resource("monitors").
+		ResourceID(monitorID).
+		Do().
+		Err()
+}
+
+func (c *monitorClient) ListTags() ([]*tag, error) {
+	tags := []*tag{}
+
+	err := c.client.
+		Get().
+		Resource("tags").
+		Do().
+		Into(&tags)
+
+	return tags, err
+}
+
+func (c *monitorClient) CreateTag(t *tag) (*tag, error) {
+	created := &tag{}
+
+	err := c.client.
+		Post().
+		Resource("tags").
+		AddHeader("Content-Type", contentTypeJSON).
+		Body(t).
+		Do().
+		Into(created)
+
+	return created, err
+}
@@ -0,0 +1,315 @@
+// Package fake provides an in-memory stand-in for the monitor and tag
+// endpoints of the Site24x7 API that can be used in unit tests.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	site24x7api "github.com/Bonial-International-GmbH/site24x7-go/api"
+)
+
+// Monitor is a website monitor as stored by the server.
+type Monitor struct {
+	site24x7api.Monitor
+
+	TagIDs []string `json:"tag_ids,omitempty"`
+}
+
+// Tag is a tag as stored by the server.
+type Tag struct {
+	TagID    string `json:"tag_id,omitempty"`
+	TagName  string `json:"tag_name"`
+	TagValue string `json:"tag_value"`
+	TagColor string `json:"tag_color,omitempty"`
+}
+
+// Server is an httptest server that implements the subset of the Site24x7
+// API used by the site24x7 provider for monitors and tags. Monitors and tags
+// are kept in memory.
+type Server struct {
+	*httptest.Server
+
+	// Block, if not nil, blocks every request until it is closed.
+	Block chan struct{}
+
+	mu       sync.Mutex
+	nextID   int64
+	monitors map[string]Monitor
+	tags     map[string]Tag
+	requests []string
+}
+
+// NewServer creates and starts a new *Server. The server must be closed by
+// the caller.
+func NewServer() *Server {
+	s := &Server{
+		nextID:   1,
+		monitors: make(map[string]Monitor),
+		tags:     make(map[string]Tag),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+
+	return s
+}
+
+// APIBaseURL returns the base URL of the fake API.
+func (s *Server) APIBaseURL() string {
+	return s.URL + "/api"
+}
+
+// AddMonitor adds monitor to the server and returns its ID.
+func (s *Server) AddMonitor(monitor Monitor) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	monitor.MonitorID = s.newID()
+	s.monitors[monitor.MonitorID] = monitor
+
+	return monitor.MonitorID
+}
+
+// AddTag adds tag to the server and returns its ID.
+func (s *Server) AddTag(tag Tag) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tag.TagID = s.newID()
+	s.tags[tag.TagID] = tag
+
+	return tag.TagID
+}
+
+// Monitors returns a snapshot of all monitors sorted by ID.
+func (s *Server) Monitors() []Monitor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	monitors := make([]Monitor, 0, len(s.monitors))
+	for _, id := range s.sortedMonitorIDs() {
+		monitors = append(monitors, s.monitors[id])
+	}
+
+	return monitors
+}
+
+// Tags returns a snapshot of all tags sorted by ID.
+func (s *Server) Tags() []Tag {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tags := make([]Tag, 0, len(s.tags))
+	for _, id := range s.sortedTagIDs() {
+		tags = append(tags, s.tags[id])
+	}
+
+	return tags
+}
+
+// Requests returns the method and path of all API requests in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if s.Block != nil {
+		<-s.Block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := strings.TrimPrefix(r.URL.Path, "/api")
+
+	s.requests = append(s.requests, r.Method+" "+path)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	switch {
+	case path == "/monitors" && r.Method == http.MethodGet:
+		monitors := make([]Monitor, 0, len(s.monitors))
+		for _, id := range s.sortedMonitorIDs() {
+			monitors = append(monitors, s.monitors[id])
+		}
+
+		writeData(w, monitors)
+	case path == "/monitors" && r.Method == http.MethodPost:
+		s.createMonitor(w, r)
+	case strings.HasPrefix(path, "/monitors/"):
+		id := strings.TrimPrefix(path, "/monitors/")
+
+		monitor, ok := s.monitors[id]
+		if !ok {
+			writeError(w, http.StatusNotFound, 1001, "Monitor not found")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			writeData(w, monitor)
+		case http.MethodPut:
+			s.updateMonitor(w, r, monitor)
+		case http.MethodDelete:
+			delete(s.monitors, id)
+			writeData(w, nil)
+		default:
+			writeError(w, http.StatusMethodNotAllowed, 1000, "Method not allowed")
+		}
+	case path == "/tags" && r.Method == http.MethodGet:
+		tags := make([]Tag, 0, len(s.tags))
+		for _, id := range s.sortedTagIDs() {
+			tags = append(tags, s.tags[id])
+		}
+
+		writeData(w, tags)
+	case path == "/tags" && r.Method == http.MethodPost:
+		s.createTag(w, r)
+	default:
+		writeError(w, http.StatusNotFound, 1000, "Not found")
+	}
+}
+
+func (s *Server) createMonitor(w http.ResponseWriter, r *http.Request) {
+	var monitor Monitor
+
+	if err := json.NewDecoder(r.Body).Decode(&monitor); err != nil {
+		writeError(w, http.StatusBadRequest, 1002, "Invalid request body")
+		return
+	}
+
+	if monitor.DisplayName == "" || monitor.Type == "" {
+		writeError(w, http.StatusBadRequest, 1002, "Missing required field")
+		return
+	}
+
+	if !s.validTagIDs(w, monitor.TagIDs) {
+		return
+	}
+
+	monitor.MonitorID = s.newID()
+	s.monitors[monitor.MonitorID] = monitor
+
+	writeData(w, monitor)
+}
+
+func (s *Server) updateMonitor(w http.ResponseWriter, r *http.Request, current Monitor) {
+	var monitor Monitor
+
+	if err := json.NewDecoder(r.Body).Decode(&monitor); err != nil {
+		writeError(w, http.StatusBadRequest, 1002, "Invalid request body")
+		return
+	}
+
+	if !s.validTagIDs(w, monitor.TagIDs) {
+		return
+	}
+
+	// Tags are left unchanged if they are omitted.
+	if monitor.TagIDs == nil {
+		monitor.TagIDs = current.TagIDs
+	}
+
+	monitor.MonitorID = current.MonitorID
+	s.monitors[monitor.MonitorID] = monitor
+
+	writeData(w, monitor)
+}
+
+func (s *Server) createTag(w http.ResponseWriter, r *http.Request) {
+	var tag Tag
+
+	if err := json.NewDecoder(r.Body).Decode(&tag); err != nil {
+		writeError(w, http.StatusBadRequest, 1002, "Invalid request body")
+		return
+	}
+
+	if tag.TagName == "" {
+		writeError(w, http.StatusBadRequest, 1002, "Missing required field tag_name")
+		return
+	}
+
+	for _, t := range s.tags {
+		if t.TagName == tag.TagName && t.TagValue == tag.TagValue {
+			writeError(w, http.StatusBadRequest, 1003, "Tag already exists")
+			return
+		}
+	}
+
+	tag.TagID = s.newID()
+	s.tags[tag.TagID] = tag
+
+	writeData(w, tag)
+}
+
+func (s *Server) validTagIDs(w http.ResponseWriter, tagIDs []string) bool {
+	for _, id := range tagIDs {
+		if _, ok := s.tags[id]; !ok {
+			writeError(w, http.StatusBadRequest, 1002, fmt.Sprintf("Invalid tag ID %s", id))
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *Server) newID() string {
+	id := strconv.FormatInt(s.nextID, 10)
+	s.nextID++
+
+	return id
+}
+
+func (s *Server) sortedMonitorIDs() []string {
+	ids := make([]string, 0, len(s.monitors))
+	for id := range s.monitors {
+		ids = append(ids, id)
+	}
+
+	return sortIDs(ids)
+}
+
+func (s *Server) sortedTagIDs() []string {
+	ids := make([]string, 0, len(s.tags))
+	for id := range s.tags {
+		ids = append(ids, id)
+	}
+
+	return sortIDs(ids)
+}
+
+// sortIDs sorts the numeric IDs in ids in ascending order.
+func sortIDs(ids []string) []string {
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.ParseInt(ids[i], 10, 64)
+		b, _ := strconv.ParseInt(ids[j], 10, 64)
+		return a < b
+	})
+
+	return ids
+}
+
+func writeData(w http.ResponseWriter, data interface{}) {
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"code":    0,
+		"message": "success",
+		"data":    data,
+	})
+}
+
+func writeError(w http.ResponseWriter, statusCode, errorCode int, message string) {
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(site24x7api.ErrorResponse{
+		ErrorCode: errorCode,
+		Message:   message,
+	})
+}
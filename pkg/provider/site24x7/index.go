@@ -0,0 +1,151 @@
+package site24x7
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/metrics"
+)
+
+// monitorIndex indexes Site24x7 monitors by display name. It is refreshed
+// from the API once it is older than the refresh interval and kept coherent
+// with the monitors created, updated and deleted by the provider in between.
+// This avoids listing all monitors on every lookup.
+type monitorIndex struct {
+	sync.Mutex
+
+	refreshInterval time.Duration
+	lastRefresh     time.Time
+	monitors        map[string]*taggedMonitor
+
+	// now is replaced in tests.
+	now func() time.Time
+}
+
+func newMonitorIndex(refreshInterval time.Duration) *monitorIndex {
+	return &monitorIndex{
+		refreshInterval: refreshInterval,
+		now:             time.Now,
+	}
+}
+
+// get looks up the monitor with name. If the index is stale, list is called
+// to refresh it first. The second return value is false if the monitor does
+// not exist.
+func (i *monitorIndex) get(ctx context.Context, name string, list func(context.Context) ([]*taggedMonitor, error)) (*taggedMonitor, bool, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.isStale() {
+		metrics.MonitorCacheMissesTotal.WithLabelValues(config.ProviderSite24x7).Inc()
+
+		monitors, err := list(ctx)
+		if err != nil {
+			return nil, false, err
+		}
+
+		i.replace(monitors)
+	} else {
+		metrics.MonitorCacheHitsTotal.WithLabelValues(config.ProviderSite24x7).Inc()
+	}
+
+	monitor, found := i.monitors[name]
+
+	return monitor, found, nil
+}
+
+// refresh unconditionally refreshes the index using list and returns all
+// monitors.
+func (i *monitorIndex) refresh(ctx context.Context, list func(context.Context) ([]*taggedMonitor, error)) ([]*taggedMonitor, error) {
+	i.Lock()
+	defer i.Unlock()
+
+	monitors, err := list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	i.replace(monitors)
+
+	return monitors, nil
+}
+
+// getByID returns the monitor with monitorID from the index without
+// refreshing it. Returns nil if the monitor is not indexed.
+func (i *monitorIndex) getByID(monitorID string) *taggedMonitor {
+	i.Lock()
+	defer i.Unlock()
+
+	for _, m := range i.monitors {
+		if m.MonitorID == monitorID {
+			return m
+		}
+	}
+
+	return nil
+}
+
+// set adds or replaces monitor in the index.
+func (i *monitorIndex) set(monitor *taggedMonitor) {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.monitors == nil {
+		return
+	}
+
+	// The display name may have changed, so we have to drop the old entry.
+	for name, m := range i.monitors {
+		if m.MonitorID == monitor.MonitorID {
+			delete(i.monitors, name)
+		}
+	}
+
+	i.monitors[monitor.DisplayName] = monitor
+	i.updateSize()
+}
+
+// remove removes the monitor with name from the index.
+func (i *monitorIndex) remove(name string) {
+	i.Lock()
+	defer i.Unlock()
+
+	if i.monitors == nil {
+		return
+	}
+
+	delete(i.monitors, name)
+	i.updateSize()
+}
+
+// invalidate forces a refresh on the next lookup. This is used if the state
+// of a monitor is unknown after a failed API call.
+func (i *monitorIndex) invalidate() {
+	i.Lock()
+	defer i.Unlock()
+
+	i.monitors = nil
+}
+
+func (i *monitorIndex) isStale() bool {
+	return i.monitors == nil || i.refreshInterval <= 0 || i.now().Sub(i.lastRefresh) >= i.refreshInterval
+}
+
+func (i *monitorIndex) replace(monitors []*taggedMonitor) {
+	i.monitors = make(map[string]*taggedMonitor, len(monitors))
+
+	for _, monitor := range monitors {
+		i.monitors[monitor.DisplayName] = monitor
+	}
+
+	i.lastRefresh = i.now()
+
+	metrics.MonitorCacheLastRefreshTimestampSeconds.WithLabelValues(config.ProviderSite24x7).Set(float64(i.lastRefresh.Unix()))
+	i.updateSize()
+}
+
+func (i *monitorIndex) updateSize() {
+	metrics.MonitorCacheSize.WithLabelValues(config.ProviderSite24x7).Set(float64(len(i.monitors)))
+}
@@ -0,0 +1,123 @@
+package site24x7
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/monitor/metrics"
+	site24x7api "github.com/Bonial-International-GmbH/site24x7-go/api"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitorIndex_Refresh(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+
+	index := newMonitorIndex(time.Minute)
+	index.now = func() time.Time { return now }
+
+	calls := 0
+	list := func(context.Context) ([]*taggedMonitor, error) {
+		calls++
+		return []*taggedMonitor{{Monitor: &site24x7api.Monitor{MonitorID: "1", DisplayName: "foo"}}}, nil
+	}
+
+	hits := testutil.ToFloat64(metrics.MonitorCacheHitsTotal.WithLabelValues(config.ProviderSite24x7))
+	misses := testutil.ToFloat64(metrics.MonitorCacheMissesTotal.WithLabelValues(config.ProviderSite24x7))
+
+	monitor, found, err := index.get(context.Background(), "foo", list)
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "1", monitor.MonitorID)
+
+	_, found, err = index.get(context.Background(), "bar", list)
+	require.NoError(t, err)
+	require.False(t, found)
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, hits+1, testutil.ToFloat64(metrics.MonitorCacheHitsTotal.WithLabelValues(config.ProviderSite24x7)))
+	assert.Equal(t, misses+1, testutil.ToFloat64(metrics.MonitorCacheMissesTotal.WithLabelValues(config.ProviderSite24x7)))
+	assert.Equal(t, float64(now.Unix()), testutil.ToFloat64(metrics.MonitorCacheLastRefreshTimestampSeconds.WithLabelValues(config.ProviderSite24x7)))
+
+	now = now.Add(time.Minute)
+
+	_, _, err = index.get(context.Background(), "foo", list)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestMonitorIndex_RefreshError(t *testing.T) {
+	index := newMonitorIndex(time.Minute)
+
+	_, _, err := index.get(context.Background(), "foo", func(context.Context) ([]*taggedMonitor, error) {
+		return nil, errors.New("whoops")
+	})
+	require.Error(t, err)
+
+	// A failed refresh must not result in an empty index being used.
+	assert.True(t, index.isStale())
+}
+
+func TestMonitorIndex_Disabled(t *testing.T) {
+	index := newMonitorIndex(0)
+
+	calls := 0
+	list := func(context.Context) ([]*taggedMonitor, error) {
+		calls++
+		return nil, nil
+	}
+
+	for i := 0; i < 3; i++ {
+		_, _, err := index.get(context.Background(), "foo", list)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 3, calls)
+}
+
+func TestProvider_IndexCoherence(t *testing.T) {
+	p, _, s := newTestProvider(t, config.Site24x7Config{
+		MonitorCacheRefreshInterval: config.NewDefaultProviderConfig().Site24x7.MonitorCacheRefreshInterval,
+		MonitorDefaults: config.Site24x7MonitorDefaults{
+			LocationProfileID:     "123",
+			NotificationProfileID: "456",
+			ThresholdProfileID:    "789",
+			MonitorGroupIDs:       []string{"012"},
+			UserGroupIDs:          []string{"345"},
+		},
+	})
+
+	_, err := p.Get(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{Name: "foo", URL: "http://foo.bar"}))
+
+	monitor, err := p.Get(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "1", monitor.ID)
+	assert.Equal(t, "http://foo.bar", monitor.URL)
+
+	require.NoError(t, p.Update(context.Background(), &models.Monitor{ID: "1", Name: "bar", URL: "http://foo.bar"}))
+
+	_, err = p.Get(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	require.NoError(t, p.Delete(context.Background(), "bar"))
+
+	_, err = p.Get(context.Background(), "bar")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	// All lookups were served from the index after the initial refresh.
+	assert.Equal(t, []string{
+		"GET /tags",
+		"GET /monitors",
+		"POST /monitors",
+		"PUT /monitors/1",
+		"DELETE /monitors/1",
+	}, s.Requests())
+}
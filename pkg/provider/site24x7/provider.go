@@ -1,11 +1,14 @@
 package site24x7
 
 import (
+	"context"
 	"time"
 
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
 	site24x7 "github.com/Bonial-International-GmbH/site24x7-go"
+	site24x7api "github.com/Bonial-International-GmbH/site24x7-go/api"
+	"github.com/Bonial-International-GmbH/site24x7-go/backoff"
 	"github.com/Bonial-International-GmbH/site24x7-go/location"
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/cache"
@@ -17,97 +20,298 @@ var log = logf.Log.WithName("site24x7-provider")
 // Provider manages Site24x7 website monitors.
 type Provider struct {
 	client           site24x7.Client
+	monitors         *monitorClient
 	config           config.Site24x7Config
 	ipProvider       *location.ProfileIPProvider
 	builder          *builder
+	index            *monitorIndex
+	ownerTags        *ownerTags
 	sourceRangeCache *cache.Expiring
 }
 
 // NewProvider creates a new Site24x7 provider with given Site24x7Config.
 func NewProvider(config config.Site24x7Config) *Provider {
-	client := site24x7.New(site24x7.Config{
+	clientConfig := site24x7.Config{
 		ClientID:     config.ClientID,
 		ClientSecret: config.ClientSecret,
 		RefreshToken: config.RefreshToken,
-	})
+	}
+
+	httpClient := backoff.WithRetries(clientConfig.OAuthClient(context.Background()), clientConfig.RetryConfig)
+	client := site24x7.NewClient(httpClient)
 
 	return &Provider{
 		client:           client,
+		monitors:         newMonitorClient(httpClient, site24x7.APIBaseURL),
 		config:           config,
 		builder:          newBuilder(client, config.MonitorDefaults),
+		index:            newMonitorIndex(config.MonitorCacheRefreshInterval.Duration),
+		ownerTags:        newOwnerTags(),
 		sourceRangeCache: cache.NewExpiring(),
 	}
 }
 
-// Create implements provider.Interface.
-func (p *Provider) Create(model *models.Monitor) error {
-	monitor, err := p.builder.FromModel(model)
+// Create implements provider.Interface. Monitors created for an owner are
+// marked with the owner tag.
+func (p *Provider) Create(ctx context.Context, model *models.Monitor) error {
+	monitor, err := p.buildMonitor(ctx, model)
 	if err != nil {
-		return errors.Wrapf(err, "failed to build site24x7 monitor from model: %#v", model)
+		return errors.Wrapf(err, "failed to build site24x7 monitor %q from model", model.Name)
 	}
 
-	_, err = p.client.Monitors().Create(monitor)
+	tagIDs, err := p.tagIDs(ctx, nil, model.Owner)
 	if err != nil {
-		return errors.Wrapf(err, "failed to create site24x7 monitor: %#v", monitor)
+		return err
+	}
+
+	var created *taggedMonitor
+
+	err = withContext(ctx, func() (err error) {
+		created, err = p.monitors.Create(&taggedMonitor{Monitor: monitor, TagIDs: tagIDs})
+		return err
+	})
+	if err != nil {
+		// The monitor may have been created anyways, e.g. if the call
+		// timed out.
+		p.index.invalidate()
+		return errors.Wrapf(err, "failed to create site24x7 monitor %q", model.Name)
+	}
+
+	if created != nil {
+		p.index.set(created)
 	}
 
 	return nil
 }
 
-// Create implements provider.Interface.
-func (p *Provider) Get(name string) (*models.Monitor, error) {
-	monitors, err := p.client.Monitors().List()
+// Get implements provider.Interface. Monitors are looked up in an index which
+// is refreshed periodically.
+func (p *Provider) Get(ctx context.Context, name string) (*models.Monitor, error) {
+	monitor, found, err := p.index.get(ctx, name, p.listMonitors)
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to list site24x7 monitors")
 	}
 
-	for _, monitor := range monitors {
-		if monitor.DisplayName != name {
-			continue
-		}
+	if !found {
+		return nil, models.ErrMonitorNotFound
+	}
+
+	m := &models.Monitor{
+		ID:     monitor.MonitorID,
+		Name:   monitor.DisplayName,
+		URL:    monitor.Website,
+		Owner:  p.ownerTags.owner(monitor.TagIDs),
+		Config: monitor.Monitor,
+	}
+
+	return m, nil
+}
+
+// Diff implements provider.Differ. The existing monitor is compared with the
+// monitor built from model. The auth password is write-only and never
+// returned by the API, so it is excluded from the comparison. A missing or
+// different owner tag is reported as well.
+func (p *Provider) Diff(ctx context.Context, existing, model *models.Monitor) ([]models.FieldDiff, error) {
+	current, ok := existing.Config.(*site24x7api.Monitor)
+	if !ok {
+		return nil, models.ErrDiffNotSupported
+	}
 
-		m := &models.Monitor{
-			ID:   monitor.MonitorID,
-			Name: monitor.DisplayName,
-			URL:  monitor.Website,
+	m := *model
+	m.ID = existing.ID
+
+	desired, err := p.buildMonitor(ctx, &m)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to build site24x7 monitor %q from model", model.Name)
+	}
+
+	withoutAuthPass := *current
+	withoutAuthPass.AuthPass = ""
+	desired.AuthPass = ""
+
+	diffs := models.DiffFields(&withoutAuthPass, desired)
+
+	if model.Owner != nil && (existing.Owner == nil || *existing.Owner != *model.Owner) {
+		var currentOwner string
+		if existing.Owner != nil {
+			currentOwner = existing.Owner.String()
 		}
 
-		return m, nil
+		diffs = append(diffs, models.FieldDiff{
+			Field:   "Owner",
+			Current: currentOwner,
+			Desired: model.Owner.String(),
+		})
 	}
 
-	return nil, models.ErrMonitorNotFound
+	return diffs, nil
 }
 
-// Create implements provider.Interface.
-func (p *Provider) Update(model *models.Monitor) error {
-	monitor, err := p.builder.FromModel(model)
+// Update implements provider.Interface. The owner tag is replaced if model
+// has an owner, other tags of the monitor are kept.
+func (p *Provider) Update(ctx context.Context, model *models.Monitor) error {
+	monitor, err := p.buildMonitor(ctx, model)
 	if err != nil {
-		return errors.Wrapf(err, "failed to build site24x7 monitor from model: %#v", model)
+		return errors.Wrapf(err, "failed to build site24x7 monitor %q from model", model.Name)
 	}
 
-	_, err = p.client.Monitors().Update(monitor)
+	var currentTagIDs []string
+	if current := p.index.getByID(model.ID); current != nil {
+		currentTagIDs = current.TagIDs
+	}
+
+	tagIDs, err := p.tagIDs(ctx, currentTagIDs, model.Owner)
+	if err != nil {
+		return err
+	}
+
+	var updated *taggedMonitor
+
+	err = withContext(ctx, func() (err error) {
+		updated, err = p.monitors.Update(&taggedMonitor{Monitor: monitor, TagIDs: tagIDs})
+		return err
+	})
 	if err != nil {
-		return errors.Wrapf(err, "failed to update site24x7 monitor: %#v", monitor)
+		p.index.invalidate()
+		return errors.Wrapf(err, "failed to update site24x7 monitor %q with ID %s", model.Name, model.ID)
+	}
+
+	if updated != nil {
+		p.index.set(updated)
 	}
 
 	return nil
 }
 
-// Create implements provider.Interface.
-func (p *Provider) Delete(name string) error {
-	monitor, err := p.Get(name)
+// Rename implements provider.Renamer. The monitor is updated in place and
+// keeps its ID.
+func (p *Provider) Rename(ctx context.Context, oldName string, model *models.Monitor) error {
+	existing, err := p.Get(ctx, oldName)
+	if err != nil {
+		return err
+	}
+
+	monitor := *model
+	monitor.ID = existing.ID
+
+	return p.Update(ctx, &monitor)
+}
+
+// Delete implements provider.Interface.
+func (p *Provider) Delete(ctx context.Context, name string) error {
+	monitor, err := p.Get(ctx, name)
 	if err != nil {
 		return err
 	}
 
-	err = p.client.Monitors().Delete(monitor.ID)
+	err = withContext(ctx, func() error {
+		return p.monitors.Delete(monitor.ID)
+	})
 	if err != nil {
+		p.index.invalidate()
 		return errors.Wrapf(err, "failed to delete site24x7 monitor with ID %s", monitor.ID)
 	}
 
+	p.index.remove(name)
+
 	return nil
 }
 
+// List implements provider.Interface. Owned monitors are identified by the
+// owner tag. The monitor index is refreshed as a side effect.
+func (p *Provider) List(ctx context.Context) ([]*models.Monitor, error) {
+	monitors, err := p.index.refresh(ctx, p.listMonitors)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list site24x7 monitors")
+	}
+
+	result := make([]*models.Monitor, 0, len(monitors))
+
+	for _, monitor := range monitors {
+		owner := p.ownerTags.owner(monitor.TagIDs)
+		if owner == nil {
+			continue
+		}
+
+		result = append(result, &models.Monitor{
+			ID:    monitor.MonitorID,
+			Name:  monitor.DisplayName,
+			URL:   monitor.Website,
+			Owner: owner,
+		})
+	}
+
+	return result, nil
+}
+
+// listMonitors lists all monitors. The owner tags are refreshed as well, so
+// that they are in sync with the tag IDs of the monitors.
+func (p *Provider) listMonitors(ctx context.Context) ([]*taggedMonitor, error) {
+	var (
+		monitors []*taggedMonitor
+		tags     []*tag
+	)
+
+	err := withContext(ctx, func() (err error) {
+		tags, err = p.monitors.ListTags()
+		if err != nil {
+			return err
+		}
+
+		monitors, err = p.monitors.List()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	p.ownerTags.replace(tags)
+
+	return monitors, nil
+}
+
+// tagIDs returns the tag IDs for a monitor of owner which currently has
+// currentTagIDs. The owner tag is created if it does not exist yet. Returns
+// currentTagIDs unchanged if owner is nil.
+func (p *Provider) tagIDs(ctx context.Context, currentTagIDs []string, owner *models.Owner) ([]string, error) {
+	if owner == nil {
+		return currentTagIDs, nil
+	}
+
+	ownerTagID, found := p.ownerTags.lookup(owner)
+	if !found {
+		var created *tag
+
+		err := withContext(ctx, func() (err error) {
+			created, err = p.monitors.CreateTag(&tag{
+				TagName:  ownerTagName,
+				TagValue: owner.String(),
+				TagColor: ownerTagColor,
+			})
+			return err
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create site24x7 owner tag")
+		}
+
+		p.ownerTags.add(created)
+		ownerTagID = created.TagID
+	}
+
+	return p.ownerTags.withOwnerTag(currentTagIDs, ownerTagID), nil
+}
+
+// Check implements provider.Checker. The credentials are verified by listing
+// the location profiles.
+func (p *Provider) Check(ctx context.Context) error {
+	err := withContext(ctx, func() error {
+		_, err := p.client.LocationProfiles().List()
+		return err
+	})
+
+	return errors.Wrapf(err, "failed to list site24x7 location profiles")
+}
+
 // getProfileIPProvider lazily creates a ProfileIPProvider. This is an
 // optimization to avoid API calls when not needed and also allows us to stub
 // out the ProfileIPProvider in tests.
@@ -121,8 +325,8 @@ func (p *Provider) getProfileIPProvider() (*location.ProfileIPProvider, error) {
 }
 
 // GetIPSourceRanges implements provider.Interface.
-func (p *Provider) GetIPSourceRanges(model *models.Monitor) ([]string, error) {
-	monitor, err := p.builder.FromModel(model)
+func (p *Provider) GetIPSourceRanges(ctx context.Context, model *models.Monitor) ([]string, error) {
+	monitor, err := p.buildMonitor(ctx, model)
 	if err != nil {
 		return nil, err
 	}
@@ -132,17 +336,26 @@ func (p *Provider) GetIPSourceRanges(model *models.Monitor) ([]string, error) {
 		return cachedSourceRanges.([]string), nil
 	}
 
-	ipProvider, err := p.getProfileIPProvider()
-	if err != nil {
-		return nil, err
-	}
+	var (
+		locationProfile *site24x7api.LocationProfile
+		locationIPs     []string
+	)
 
-	locationProfile, err := p.client.LocationProfiles().Get(monitor.LocationProfileID)
-	if err != nil {
-		return nil, err
-	}
+	err = withContext(ctx, func() error {
+		ipProvider, err := p.getProfileIPProvider()
+		if err != nil {
+			return err
+		}
+
+		locationProfile, err = p.client.LocationProfiles().Get(monitor.LocationProfileID)
+		if err != nil {
+			return err
+		}
+
+		locationIPs, err = ipProvider.GetLocationIPs(locationProfile)
 
-	locationIPs, err := ipProvider.GetLocationIPs(locationProfile)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -159,3 +372,39 @@ func (p *Provider) GetIPSourceRanges(model *models.Monitor) ([]string, error) {
 
 	return sourceRanges, nil
 }
+
+// buildMonitor builds a site24x7 monitor from model. The builder may perform
+// API calls to look up profiles and monitor groups.
+func (p *Provider) buildMonitor(ctx context.Context, model *models.Monitor) (*site24x7api.Monitor, error) {
+	var monitor *site24x7api.Monitor
+
+	err := withContext(ctx, func() (err error) {
+		monitor, err = p.builder.FromModel(model)
+		return err
+	})
+
+	return monitor, err
+}
+
+// withContext runs fn and returns early with the context's error if ctx is
+// done before fn returns. The site24x7 client does not support contexts, so
+// an aborted fn keeps running in the background until the API call returns,
+// but it does not block the caller anymore.
+func withContext(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- fn()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,11 +1,15 @@
 package site24x7
 
 import (
+	"context"
 	"errors"
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
 	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	fakeapi "github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/site24x7/fake"
 	site24x7api "github.com/Bonial-International-GmbH/site24x7-go/api"
 	"github.com/Bonial-International-GmbH/site24x7-go/fake"
 	"github.com/Bonial-International-GmbH/site24x7-go/location"
@@ -19,8 +23,7 @@ func TestProvider_Create(t *testing.T) {
 		name     string
 		model    *models.Monitor
 		config   config.Site24x7Config
-		setup    func(*fake.Client)
-		validate func(*testing.T, *fake.Client)
+		validate func(*testing.T, *fakeapi.Server)
 		expected error
 	}{
 		{
@@ -29,13 +32,53 @@ func TestProvider_Create(t *testing.T) {
 				Name: "my-monitor",
 				URL:  "http://my-monitor",
 			},
-			setup: func(c *fake.Client) {
-				monitor := &site24x7api.Monitor{
-					DisplayName: "my-monitor",
-					Website:     "http://my-monitor",
-					Type:        "URL",
-				}
-				c.FakeMonitors.On("Create", monitor).Return(monitor, nil)
+			validate: func(t *testing.T, s *fakeapi.Server) {
+				assert.Equal(t, []fakeapi.Monitor{
+					{
+						Monitor: site24x7api.Monitor{
+							MonitorID:   "1",
+							DisplayName: "my-monitor",
+							Website:     "http://my-monitor",
+							Type:        "URL",
+						},
+					},
+				}, s.Monitors())
+				assert.Empty(t, s.Tags())
+			},
+		},
+		{
+			name: "marks monitor with owner tag",
+			model: &models.Monitor{
+				Name:  "my-monitor",
+				URL:   "http://my-monitor",
+				Owner: &models.Owner{ClusterID: "prod", Namespace: "kube-system", Name: "my-ingress", UID: "1234"},
+			},
+			config: config.Site24x7Config{
+				MonitorDefaults: config.Site24x7MonitorDefaults{
+					CustomHeaders: []site24x7api.Header{{Name: "Accept", Value: "text/html"}},
+				},
+			},
+			validate: func(t *testing.T, s *fakeapi.Server) {
+				assert.Equal(t, []fakeapi.Tag{
+					{
+						TagID:    "1",
+						TagName:  "ingress-monitor-owner",
+						TagValue: "cluster=prod&name=my-ingress&namespace=kube-system&uid=1234",
+						TagColor: ownerTagColor,
+					},
+				}, s.Tags())
+				assert.Equal(t, []fakeapi.Monitor{
+					{
+						Monitor: site24x7api.Monitor{
+							MonitorID:     "2",
+							DisplayName:   "my-monitor",
+							Website:       "http://my-monitor",
+							Type:          "URL",
+							CustomHeaders: []site24x7api.Header{{Name: "Accept", Value: "text/html"}},
+						},
+						TagIDs: []string{"1"},
+					},
+				}, s.Monitors())
 			},
 		},
 		{
@@ -47,22 +90,18 @@ func TestProvider_Create(t *testing.T) {
 					config.AnnotationSite24x7Actions: "{invalidjson",
 				},
 			},
-			validate: func(t *testing.T, c *fake.Client) {
-				assert.Len(t, c.FakeMonitors.Calls, 0)
+			validate: func(t *testing.T, s *fakeapi.Server) {
+				assert.Empty(t, s.Requests())
 			},
-			expected: errors.New(`failed to build site24x7 monitor from model: &models.Monitor{ID:"", Name:"my-monitor", URL:"http://my-monitor", Annotations:config.Annotations{"site24x7.ingress-monitor.bonial.com/actions":"{invalidjson"}}: invalid json in annotation "site24x7.ingress-monitor.bonial.com/actions": {invalidjson: invalid character 'i' looking for beginning of object key string`),
+			expected: errors.New(`failed to build site24x7 monitor "my-monitor" from model: invalid json in annotation "site24x7.ingress-monitor.bonial.com/actions": {invalidjson: invalid character 'i' looking for beginning of object key string`),
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			p, c := newTestProvider(test.config)
+			p, _, s := newTestProvider(t, test.config)
 
-			if test.setup != nil {
-				test.setup(c)
-			}
-
-			err := p.Create(test.model)
+			err := p.Create(context.Background(), test.model)
 			if test.expected != nil {
 				require.Error(t, err)
 				assert.Equal(t, test.expected.Error(), err.Error())
@@ -71,7 +110,7 @@ func TestProvider_Create(t *testing.T) {
 			}
 
 			if test.validate != nil {
-				test.validate(t, c)
+				test.validate(t, s)
 			}
 		})
 	}
@@ -79,12 +118,12 @@ func TestProvider_Create(t *testing.T) {
 
 func TestProvider_Update(t *testing.T) {
 	tests := []struct {
-		name     string
-		model    *models.Monitor
-		config   config.Site24x7Config
-		setup    func(*fake.Client)
-		validate func(*testing.T, *fake.Client)
-		expected error
+		name            string
+		model           *models.Monitor
+		config          config.Site24x7Config
+		setup           func(*fake.Client)
+		expectedMonitor site24x7api.Monitor
+		expected        error
 	}{
 		{
 			name: "auto discovers profile and group IDs from API if enabled",
@@ -102,18 +141,6 @@ func TestProvider_Update(t *testing.T) {
 				},
 			},
 			setup: func(c *fake.Client) {
-				monitor := &site24x7api.Monitor{
-					DisplayName:           "my-monitor",
-					Website:               "http://my-monitor",
-					Type:                  "URL",
-					LocationProfileID:     "123",
-					NotificationProfileID: "456",
-					ThresholdProfileID:    "789",
-					UserGroupIDs:          []string{"012"},
-					MonitorGroups:         []string{"345"},
-				}
-				c.FakeMonitors.On("Update", monitor).Return(monitor, nil)
-
 				c.FakeLocationProfiles.On("List").Return([]*site24x7api.LocationProfile{
 					{ProfileID: "123"},
 				}, nil)
@@ -122,10 +149,6 @@ func TestProvider_Update(t *testing.T) {
 					{ProfileID: "456"},
 				}, nil)
 
-				c.FakeLocationProfiles.On("List").Return([]*site24x7api.LocationProfile{
-					{ProfileID: "123"},
-				}, nil)
-
 				c.FakeThresholdProfiles.On("List").Return([]*site24x7api.ThresholdProfile{
 					{ProfileID: "789"},
 				}, nil)
@@ -138,6 +161,16 @@ func TestProvider_Update(t *testing.T) {
 					{GroupID: "345"},
 				}, nil)
 			},
+			expectedMonitor: site24x7api.Monitor{
+				DisplayName:           "my-monitor",
+				Website:               "http://my-monitor",
+				Type:                  "URL",
+				LocationProfileID:     "123",
+				NotificationProfileID: "456",
+				ThresholdProfileID:    "789",
+				UserGroupIDs:          []string{"012"},
+				MonitorGroups:         []string{"345"},
+			},
 		},
 		{
 			name: "it will not override explicitly set profile IDs with auto discovered IDs",
@@ -153,14 +186,11 @@ func TestProvider_Update(t *testing.T) {
 					AutoLocationProfile: true,
 				},
 			},
-			setup: func(c *fake.Client) {
-				monitor := &site24x7api.Monitor{
-					DisplayName:       "my-monitor",
-					Website:           "http://my-monitor",
-					Type:              "URL",
-					LocationProfileID: "456",
-				}
-				c.FakeMonitors.On("Update", monitor).Return(monitor, nil)
+			expectedMonitor: site24x7api.Monitor{
+				DisplayName:       "my-monitor",
+				Website:           "http://my-monitor",
+				Type:              "URL",
+				LocationProfileID: "456",
 			},
 		},
 		{
@@ -177,19 +207,30 @@ func TestProvider_Update(t *testing.T) {
 			setup: func(c *fake.Client) {
 				c.FakeLocationProfiles.On("List").Return(nil, nil)
 			},
-			expected: errors.New(`failed to build site24x7 monitor from model: &models.Monitor{ID:"", Name:"my-monitor", URL:"http://my-monitor", Annotations:config.Annotations(nil)}: no location profiles configured`),
+			expectedMonitor: site24x7api.Monitor{
+				DisplayName: "my-monitor",
+				Type:        "URL",
+			},
+			expected: errors.New(`failed to build site24x7 monitor "my-monitor" from model: no location profiles configured`),
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			p, c := newTestProvider(test.config)
+			p, c, s := newTestProvider(t, test.config)
 
 			if test.setup != nil {
 				test.setup(c)
 			}
 
-			err := p.Update(test.model)
+			id := s.AddMonitor(fakeapi.Monitor{
+				Monitor: site24x7api.Monitor{DisplayName: "my-monitor", Type: "URL"},
+			})
+
+			model := *test.model
+			model.ID = id
+
+			err := p.Update(context.Background(), &model)
 			if test.expected != nil {
 				require.Error(t, err)
 				assert.Equal(t, test.expected.Error(), err.Error())
@@ -197,64 +238,116 @@ func TestProvider_Update(t *testing.T) {
 				require.NoError(t, err)
 			}
 
-			if test.validate != nil {
-				test.validate(t, c)
-			}
+			expected := test.expectedMonitor
+			expected.MonitorID = id
+
+			assert.Equal(t, []fakeapi.Monitor{{Monitor: expected}}, s.Monitors())
 		})
 	}
 }
 
+func TestProvider_Update_Tags(t *testing.T) {
+	p, _, s := newTestProvider(t, config.Site24x7Config{})
+
+	userTagID := s.AddTag(fakeapi.Tag{TagName: "team", TagValue: "platform"})
+	oldOwnerTagID := s.AddTag(fakeapi.Tag{TagName: ownerTagName, TagValue: "cluster=&name=foo&namespace=default&uid="})
+	id := s.AddMonitor(fakeapi.Monitor{
+		Monitor: site24x7api.Monitor{DisplayName: "my-monitor", Website: "http://my-monitor", Type: "URL"},
+		TagIDs:  []string{userTagID, oldOwnerTagID},
+	})
+
+	existing, err := p.Get(context.Background(), "my-monitor")
+	require.NoError(t, err)
+	assert.Equal(t, &models.Owner{Namespace: "default", Name: "foo"}, existing.Owner)
+
+	// Updating a monitor without owner leaves its tags alone.
+	require.NoError(t, p.Update(context.Background(), &models.Monitor{ID: id, Name: "my-monitor", URL: "http://my-monitor"}))
+	assert.Equal(t, []string{userTagID, oldOwnerTagID}, s.Monitors()[0].TagIDs)
+
+	owner := &models.Owner{ClusterID: "prod", Namespace: "kube-system", Name: "my-ingress"}
+
+	require.NoError(t, p.Update(context.Background(), &models.Monitor{ID: id, Name: "my-monitor", URL: "http://my-monitor", Owner: owner}))
+
+	tags := s.Tags()
+	require.Len(t, tags, 3)
+	assert.Equal(t, owner.String(), tags[2].TagValue)
+
+	// The owner tag is replaced, other tags are kept.
+	assert.Equal(t, []string{userTagID, tags[2].TagID}, s.Monitors()[0].TagIDs)
+
+	updated, err := p.Get(context.Background(), "my-monitor")
+	require.NoError(t, err)
+	assert.Equal(t, owner, updated.Owner)
+}
+
 func TestProvider_Get(t *testing.T) {
 	tests := []struct {
 		name        string
 		monitorName string
-		config      config.Site24x7Config
-		setup       func(*fake.Client)
-		validate    func(*testing.T, *fake.Client)
+		setup       func(*fakeapi.Server)
 		expected    *models.Monitor
 		expectedErr error
 	}{
 		{
 			name:        "returns models.ErrMonitorNotFound if monitor is not found",
 			monitorName: "my-monitor",
-			setup: func(c *fake.Client) {
-				monitors := []*site24x7api.Monitor{
-					{DisplayName: "some-other-monitor"},
-				}
-				c.FakeMonitors.On("List").Return(monitors, nil)
+			setup: func(s *fakeapi.Server) {
+				s.AddMonitor(fakeapi.Monitor{Monitor: site24x7api.Monitor{DisplayName: "some-other-monitor"}})
 			},
 			expectedErr: models.ErrMonitorNotFound,
 		},
 		{
 			name:        "returns monitor with name",
 			monitorName: "my-monitor",
-			setup: func(c *fake.Client) {
-				monitors := []*site24x7api.Monitor{
-					{
-						MonitorID:   "123",
+			setup: func(s *fakeapi.Server) {
+				s.AddMonitor(fakeapi.Monitor{
+					Monitor: site24x7api.Monitor{
 						DisplayName: "my-monitor",
 						Website:     "http://my-monitor",
 					},
-				}
-				c.FakeMonitors.On("List").Return(monitors, nil)
+				})
 			},
 			expected: &models.Monitor{
-				ID:   "123",
+				ID:   "1",
 				Name: "my-monitor",
 				URL:  "http://my-monitor",
+				Config: &site24x7api.Monitor{
+					MonitorID:   "1",
+					DisplayName: "my-monitor",
+					Website:     "http://my-monitor",
+				},
+			},
+		},
+		{
+			name:        "returns owner from owner tag",
+			monitorName: "my-monitor",
+			setup: func(s *fakeapi.Server) {
+				userTagID := s.AddTag(fakeapi.Tag{TagName: "team", TagValue: "platform"})
+				ownerTagID := s.AddTag(fakeapi.Tag{TagName: ownerTagName, TagValue: "cluster=prod&name=foo&namespace=kube-system&uid="})
+
+				s.AddMonitor(fakeapi.Monitor{
+					Monitor: site24x7api.Monitor{DisplayName: "my-monitor"},
+					TagIDs:  []string{userTagID, ownerTagID},
+				})
+			},
+			expected: &models.Monitor{
+				ID:     "3",
+				Name:   "my-monitor",
+				Owner:  &models.Owner{ClusterID: "prod", Namespace: "kube-system", Name: "foo"},
+				Config: &site24x7api.Monitor{MonitorID: "3", DisplayName: "my-monitor"},
 			},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			p, c := newTestProvider(test.config)
+			p, _, s := newTestProvider(t, config.Site24x7Config{})
 
 			if test.setup != nil {
-				test.setup(c)
+				test.setup(s)
 			}
 
-			monitor, err := p.Get(test.monitorName)
+			monitor, err := p.Get(context.Background(), test.monitorName)
 			if test.expectedErr != nil {
 				require.Error(t, err)
 				assert.Equal(t, test.expectedErr.Error(), err.Error())
@@ -262,10 +355,6 @@ func TestProvider_Get(t *testing.T) {
 				require.NoError(t, err)
 				assert.Equal(t, test.expected, monitor)
 			}
-
-			if test.validate != nil {
-				test.validate(t, c)
-			}
 		})
 	}
 }
@@ -274,42 +363,39 @@ func TestProvider_Delete(t *testing.T) {
 	tests := []struct {
 		name        string
 		monitorName string
-		config      config.Site24x7Config
-		setup       func(*fake.Client)
-		validate    func(*testing.T, *fake.Client)
+		setup       func(*fakeapi.Server)
+		validate    func(*testing.T, *fakeapi.Server)
 		expected    error
 	}{
 		{
 			name:        "returns if monitor is not found",
 			monitorName: "my-monitor",
-			setup: func(c *fake.Client) {
-				c.FakeMonitors.On("List").Return(nil, nil)
-			},
-			expected: models.ErrMonitorNotFound,
+			expected:    models.ErrMonitorNotFound,
 		},
 		{
 			name:        "deletes monitor",
 			monitorName: "my-monitor",
-			setup: func(c *fake.Client) {
-				c.FakeMonitors.On("List").Return([]*site24x7api.Monitor{
-					{MonitorID: "123", DisplayName: "some-other-monitor"},
-					{MonitorID: "456", DisplayName: "my-monitor"},
-				}, nil)
-
-				c.FakeMonitors.On("Delete", "456").Return(nil)
+			setup: func(s *fakeapi.Server) {
+				s.AddMonitor(fakeapi.Monitor{Monitor: site24x7api.Monitor{DisplayName: "some-other-monitor"}})
+				s.AddMonitor(fakeapi.Monitor{Monitor: site24x7api.Monitor{DisplayName: "my-monitor"}})
+			},
+			validate: func(t *testing.T, s *fakeapi.Server) {
+				assert.Equal(t, []fakeapi.Monitor{
+					{Monitor: site24x7api.Monitor{MonitorID: "1", DisplayName: "some-other-monitor"}},
+				}, s.Monitors())
 			},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			p, c := newTestProvider(test.config)
+			p, _, s := newTestProvider(t, config.Site24x7Config{})
 
 			if test.setup != nil {
-				test.setup(c)
+				test.setup(s)
 			}
 
-			err := p.Delete(test.monitorName)
+			err := p.Delete(context.Background(), test.monitorName)
 			if test.expected != nil {
 				require.Error(t, err)
 				assert.Equal(t, test.expected.Error(), err.Error())
@@ -318,7 +404,155 @@ func TestProvider_Delete(t *testing.T) {
 			}
 
 			if test.validate != nil {
-				test.validate(t, c)
+				test.validate(t, s)
+			}
+		})
+	}
+}
+
+func TestProvider_Rename(t *testing.T) {
+	p, _, s := newTestProvider(t, config.Site24x7Config{
+		MonitorCacheRefreshInterval: config.NewDefaultProviderConfig().Site24x7.MonitorCacheRefreshInterval,
+	})
+
+	id := s.AddMonitor(fakeapi.Monitor{Monitor: site24x7api.Monitor{DisplayName: "old-monitor", Type: "URL"}})
+
+	err := p.Rename(context.Background(), "old-monitor", &models.Monitor{
+		Name: "new-monitor",
+		URL:  "http://new-monitor",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []fakeapi.Monitor{
+		{
+			Monitor: site24x7api.Monitor{
+				MonitorID:   id,
+				DisplayName: "new-monitor",
+				Website:     "http://new-monitor",
+				Type:        "URL",
+			},
+		},
+	}, s.Monitors())
+
+	// The index must reflect the new name without another list call.
+	renamed, err := p.Get(context.Background(), "new-monitor")
+	require.NoError(t, err)
+	assert.Equal(t, id, renamed.ID)
+
+	_, err = p.Get(context.Background(), "old-monitor")
+	assert.Equal(t, models.ErrMonitorNotFound, err)
+
+	assert.Equal(t, []string{"GET /tags", "GET /monitors", "PUT /monitors/" + id}, s.Requests())
+}
+
+func TestProvider_Diff(t *testing.T) {
+	defaults := config.Site24x7MonitorDefaults{
+		CheckFrequency:        "1",
+		HTTPMethod:            "G",
+		Timeout:               10,
+		LocationProfileID:     "123",
+		NotificationProfileID: "456",
+		ThresholdProfileID:    "789",
+		MonitorGroupIDs:       []string{"012"},
+		UserGroupIDs:          []string{"345"},
+	}
+
+	existing := &site24x7api.Monitor{
+		MonitorID:             "42",
+		DisplayName:           "my-monitor",
+		Type:                  "URL",
+		Website:               "http://my-monitor",
+		CheckFrequency:        "1",
+		HTTPMethod:            "G",
+		Timeout:               10,
+		LocationProfileID:     "123",
+		NotificationProfileID: "456",
+		ThresholdProfileID:    "789",
+		MonitorGroups:         []string{"012"},
+		UserGroupIDs:          []string{"345"},
+	}
+
+	tests := []struct {
+		name        string
+		existing    *models.Monitor
+		model       *models.Monitor
+		expected    []models.FieldDiff
+		expectedErr error
+	}{
+		{
+			name:     "monitor is up to date",
+			existing: &models.Monitor{ID: "42", Name: "my-monitor", Config: existing},
+			model:    &models.Monitor{Name: "my-monitor", URL: "http://my-monitor"},
+		},
+		{
+			name:     "reports drifted fields",
+			existing: &models.Monitor{ID: "42", Name: "my-monitor", Config: existing},
+			model: &models.Monitor{
+				Name: "my-monitor",
+				URL:  "http://my-monitor",
+				Annotations: map[string]string{
+					config.AnnotationSite24x7Timeout: "30",
+				},
+			},
+			expected: []models.FieldDiff{
+				{Field: "Timeout", Current: 10, Desired: 30},
+			},
+		},
+		{
+			name:     "ignores auth password which is not returned by the API",
+			existing: &models.Monitor{ID: "42", Name: "my-monitor", Config: existing},
+			model: &models.Monitor{
+				Name: "my-monitor",
+				URL:  "http://my-monitor",
+				Annotations: map[string]string{
+					config.AnnotationSite24x7AuthPass: "secret",
+				},
+			},
+		},
+		{
+			name:     "reports missing owner tag",
+			existing: &models.Monitor{ID: "42", Name: "my-monitor", Config: existing},
+			model: &models.Monitor{
+				Name:  "my-monitor",
+				URL:   "http://my-monitor",
+				Owner: &models.Owner{Namespace: "kube-system", Name: "foo"},
+			},
+			expected: []models.FieldDiff{
+				{Field: "Owner", Current: "", Desired: "cluster=&name=foo&namespace=kube-system&uid="},
+			},
+		},
+		{
+			name: "owner tag is up to date",
+			existing: &models.Monitor{
+				ID:     "42",
+				Name:   "my-monitor",
+				Owner:  &models.Owner{Namespace: "kube-system", Name: "foo"},
+				Config: existing,
+			},
+			model: &models.Monitor{
+				Name:  "my-monitor",
+				URL:   "http://my-monitor",
+				Owner: &models.Owner{Namespace: "kube-system", Name: "foo"},
+			},
+		},
+		{
+			name:        "existing monitor without config",
+			existing:    &models.Monitor{ID: "42", Name: "my-monitor"},
+			model:       &models.Monitor{Name: "my-monitor", URL: "http://my-monitor"},
+			expectedErr: models.ErrDiffNotSupported,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, _, _ := newTestProvider(t, config.Site24x7Config{MonitorDefaults: defaults})
+
+			diffs, err := p.Diff(context.Background(), test.existing, test.model)
+			if test.expectedErr != nil {
+				require.Equal(t, test.expectedErr, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, diffs)
 			}
 		})
 	}
@@ -442,14 +676,14 @@ func TestProvider_GetIPSourceRanges(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			p, c := newTestProvider(config.Site24x7Config{})
+			p, c, _ := newTestProvider(t, config.Site24x7Config{})
 			p.ipProvider = test.ipProvider
 
 			if test.setup != nil {
 				test.setup(c)
 			}
 
-			ips, err := p.GetIPSourceRanges(test.model)
+			ips, err := p.GetIPSourceRanges(context.Background(), test.model)
 			if test.expectedErr != nil {
 				require.Error(t, err)
 				assert.Equal(t, test.expectedErr.Error(), err.Error())
@@ -466,7 +700,7 @@ func TestProvider_GetIPSourceRanges(t *testing.T) {
 }
 
 func TestProvider_GetIPSourceRanges_Cache(t *testing.T) {
-	p, c := newTestProvider(config.Site24x7Config{})
+	p, c, _ := newTestProvider(t, config.Site24x7Config{})
 	p.ipProvider = &location.ProfileIPProvider{
 		IPSource: &location.StaticIPSource{
 			LocationIPs: map[string][]string{
@@ -505,24 +739,84 @@ func TestProvider_GetIPSourceRanges_Cache(t *testing.T) {
 
 	expected := []string{"1.1.1.1/32", "2.2.2.2/32", "1.2.3.4/32", "5.6.7.8/32"}
 
-	ips, err := p.GetIPSourceRanges(model)
+	ips, err := p.GetIPSourceRanges(context.Background(), model)
 	require.NoError(t, err)
 	require.Equal(t, expected, ips)
 
-	ips2, err := p.GetIPSourceRanges(model)
+	ips2, err := p.GetIPSourceRanges(context.Background(), model)
 	require.NoError(t, err)
 	require.Equal(t, ips, ips2)
 }
 
-func newTestProvider(config config.Site24x7Config) (*Provider, *fake.Client) {
+func TestProvider_List(t *testing.T) {
+	p, _, s := newTestProvider(t, config.Site24x7Config{})
+
+	ownerTagID := s.AddTag(fakeapi.Tag{TagName: ownerTagName, TagValue: "cluster=&name=foo&namespace=kube-system&uid="})
+	userTagID := s.AddTag(fakeapi.Tag{TagName: "team", TagValue: "platform"})
+
+	s.AddMonitor(fakeapi.Monitor{
+		Monitor: site24x7api.Monitor{DisplayName: "owned", Website: "http://owned"},
+		TagIDs:  []string{ownerTagID},
+	})
+	s.AddMonitor(fakeapi.Monitor{
+		Monitor: site24x7api.Monitor{DisplayName: "unowned", Website: "http://unowned"},
+		TagIDs:  []string{userTagID},
+	})
+
+	monitors, err := p.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Monitor{
+		{
+			ID:    "3",
+			Name:  "owned",
+			URL:   "http://owned",
+			Owner: &models.Owner{Namespace: "kube-system", Name: "foo"},
+		},
+	}, monitors)
+}
+
+func TestProvider_Get_ContextCanceled(t *testing.T) {
+	p, _, s := newTestProvider(t, config.Site24x7Config{})
+
+	s.Block = make(chan struct{})
+	defer close(s.Block)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := p.Get(ctx, "foo")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func newTestProvider(t *testing.T, config config.Site24x7Config) (*Provider, *fake.Client, *fakeapi.Server) {
 	client := fake.NewClient()
 
+	server := fakeapi.NewServer()
+	t.Cleanup(server.Close)
+
 	provider := &Provider{
 		client:           client,
+		monitors:         newMonitorClient(http.DefaultClient, server.APIBaseURL()),
 		config:           config,
 		builder:          newBuilder(client, config.MonitorDefaults),
+		index:            newMonitorIndex(config.MonitorCacheRefreshInterval.Duration),
+		ownerTags:        newOwnerTags(),
 		sourceRangeCache: cache.NewExpiring(),
 	}
 
-	return provider, client
+	return provider, client, server
+}
+
+func TestProvider_Check(t *testing.T) {
+	p, c, _ := newTestProvider(t, config.Site24x7Config{})
+
+	c.FakeLocationProfiles.On("List").Return([]*site24x7api.LocationProfile{}, nil).Once()
+	c.FakeLocationProfiles.On("List").Return(nil, errors.New("unauthorized")).Once()
+
+	require.NoError(t, p.Check(context.Background()))
+
+	err := p.Check(context.Background())
+	require.Error(t, err)
+	assert.Equal(t, "failed to list site24x7 location profiles: unauthorized", err.Error())
 }
@@ -0,0 +1,99 @@
+package site24x7
+
+import (
+	"sync"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+)
+
+const (
+	// ownerTagName is the name of the tags that mark monitors owned by the
+	// controller. The tag value holds the encoded owner.
+	ownerTagName = "ingress-monitor-owner"
+
+	ownerTagColor = "#4A90E2"
+)
+
+// ownerTags caches the owner tags of the Site24x7 account by ID. It is
+// refreshed together with the monitor index.
+type ownerTags struct {
+	sync.Mutex
+
+	tags map[string]*tag
+}
+
+func newOwnerTags() *ownerTags {
+	return &ownerTags{tags: make(map[string]*tag)}
+}
+
+// replace replaces the cached owner tags with the owner tags in tags. Other
+// tags are ignored.
+func (o *ownerTags) replace(tags []*tag) {
+	o.Lock()
+	defer o.Unlock()
+
+	o.tags = make(map[string]*tag, len(tags))
+
+	for _, t := range tags {
+		if t.TagName == ownerTagName {
+			o.tags[t.TagID] = t
+		}
+	}
+}
+
+// add adds tag to the cache.
+func (o *ownerTags) add(t *tag) {
+	o.Lock()
+	defer o.Unlock()
+
+	o.tags[t.TagID] = t
+}
+
+// lookup returns the ID of the owner tag for owner. The second return value
+// is false if there is no such tag.
+func (o *ownerTags) lookup(owner *models.Owner) (string, bool) {
+	o.Lock()
+	defer o.Unlock()
+
+	value := owner.String()
+
+	for id, t := range o.tags {
+		if t.TagValue == value {
+			return id, true
+		}
+	}
+
+	return "", false
+}
+
+// owner returns the owner encoded in the first owner tag in tagIDs. Returns
+// nil if there is no valid owner tag.
+func (o *ownerTags) owner(tagIDs []string) *models.Owner {
+	o.Lock()
+	defer o.Unlock()
+
+	for _, id := range tagIDs {
+		if t, ok := o.tags[id]; ok {
+			return models.ParseOwner(t.TagValue)
+		}
+	}
+
+	return nil
+}
+
+// withOwnerTag returns a copy of tagIDs with the owner tag replaced by
+// ownerTagID. Tags that are not owner tags are kept.
+func (o *ownerTags) withOwnerTag(tagIDs []string, ownerTagID string) []string {
+	o.Lock()
+	defer o.Unlock()
+
+	result := make([]string, 0, len(tagIDs)+1)
+
+	for _, id := range tagIDs {
+		if _, ok := o.tags[id]; !ok && id != ownerTagID {
+			result = append(result, id)
+		}
+	}
+
+	return append(result, ownerTagID)
+}
@@ -0,0 +1,89 @@
+package uptimerobot
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/pkg/errors"
+)
+
+// ownerHeader is the custom HTTP header which marks monitors owned by the
+// controller. The UptimeRobot API has no tags or other metadata fields for
+// monitors, so the owner has to be stored in a header which is sent to the
+// monitored endpoint.
+const ownerHeader = "X-Ingress-Monitor-Owner"
+
+type builder struct {
+	defaults config.UptimeRobotMonitorDefaults
+}
+
+func newBuilder(defaults config.UptimeRobotMonitorDefaults) *builder {
+	return &builder{
+		defaults: defaults,
+	}
+}
+
+func (b *builder) FromModel(model *models.Monitor) (*Monitor, error) {
+	anno := model.Annotations
+	defaults := b.defaults
+
+	monitor := &Monitor{
+		Type:         monitorTypeHTTP,
+		FriendlyName: model.Name,
+		URL:          model.URL,
+	}
+
+	if model.ID != "" {
+		id, err := strconv.ParseInt(model.ID, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid uptimerobot monitor ID %q", model.ID)
+		}
+
+		monitor.ID = id
+	}
+
+	methodName := strings.ToUpper(anno.StringValue(config.AnnotationUptimeRobotHTTPMethod, defaults.HTTPMethod))
+
+	method, ok := httpMethods[methodName]
+	if !ok {
+		return nil, errors.Errorf("unsupported http method %q", methodName)
+	}
+
+	monitor.HTTPMethod = method
+	monitor.HTTPUsername = anno.StringValue(config.AnnotationUptimeRobotHTTPUsername, defaults.HTTPUsername)
+	monitor.HTTPPassword = anno.StringValue(config.AnnotationUptimeRobotHTTPPassword, defaults.HTTPPassword)
+	monitor.Interval = anno.IntValue(config.AnnotationUptimeRobotInterval, defaults.Interval)
+	monitor.Timeout = anno.IntValue(config.AnnotationUptimeRobotTimeout, defaults.Timeout)
+	monitor.AlertContacts = anno.StringSliceValue(config.AnnotationUptimeRobotAlertContacts, defaults.AlertContacts)
+
+	err := anno.ParseJSON(config.AnnotationUptimeRobotCustomHTTPHeaders, &monitor.CustomHTTPHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	if monitor.CustomHTTPHeaders == nil {
+		monitor.CustomHTTPHeaders = defaults.CustomHTTPHeaders
+	}
+
+	if model.Owner != nil {
+		monitor.CustomHTTPHeaders = withOwnerHeader(monitor.CustomHTTPHeaders, model.Owner)
+	}
+
+	return monitor, nil
+}
+
+// withOwnerHeader returns a copy of headers with the owner header set to
+// owner.
+func withOwnerHeader(headers map[string]string, owner *models.Owner) map[string]string {
+	result := make(map[string]string, len(headers)+1)
+
+	for name, value := range headers {
+		result[name] = value
+	}
+
+	result[ownerHeader] = owner.String()
+
+	return result
+}
@@ -0,0 +1,274 @@
+package uptimerobot
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// defaultAPIBaseURL is the base url of the UptimeRobot v2 API.
+	defaultAPIBaseURL = "https://api.uptimerobot.com/v2"
+
+	// defaultIPListURL is the location of the list of IPv4 addresses that
+	// UptimeRobot performs checks from.
+	defaultIPListURL = "https://uptimerobot.com/inc/files/ips/IPv4.txt"
+
+	// monitorTypeHTTP is the UptimeRobot monitor type for HTTP(s) checks.
+	monitorTypeHTTP = 1
+
+	// pageSize is the maximum number of monitors returned by the
+	// getMonitors API call.
+	pageSize = 50
+)
+
+// httpMethods maps HTTP method names to their UptimeRobot API constants.
+var httpMethods = map[string]int{
+	http.MethodHead:    1,
+	http.MethodGet:     2,
+	http.MethodPost:    3,
+	http.MethodPut:     4,
+	http.MethodPatch:   5,
+	http.MethodDelete:  6,
+	http.MethodOptions: 7,
+}
+
+// Monitor is an UptimeRobot monitor.
+type Monitor struct {
+	ID                int64             `json:"id,omitempty"`
+	FriendlyName      string            `json:"friendly_name"`
+	URL               string            `json:"url"`
+	Type              int               `json:"type"`
+	Interval          int               `json:"interval"`
+	Timeout           int               `json:"timeout"`
+	HTTPMethod        int               `json:"http_method"`
+	HTTPUsername      string            `json:"http_username"`
+	HTTPPassword      string            `json:"http_password"`
+	CustomHTTPHeaders map[string]string `json:"custom_http_headers,omitempty"`
+	AlertContacts     []string          `json:"-"`
+}
+
+// values converts m into the form values expected by the newMonitor and
+// editMonitor API calls.
+func (m *Monitor) values() (url.Values, error) {
+	v := url.Values{}
+
+	if m.ID != 0 {
+		v.Set("id", strconv.FormatInt(m.ID, 10))
+	} else {
+		// The monitor type cannot be changed once the monitor is created.
+		v.Set("type", strconv.Itoa(m.Type))
+	}
+
+	v.Set("friendly_name", m.FriendlyName)
+	v.Set("url", m.URL)
+	v.Set("interval", strconv.Itoa(m.Interval))
+	v.Set("timeout", strconv.Itoa(m.Timeout))
+	v.Set("http_method", strconv.Itoa(m.HTTPMethod))
+	v.Set("http_username", m.HTTPUsername)
+	v.Set("http_password", m.HTTPPassword)
+	v.Set("alert_contacts", strings.Join(m.AlertContacts, "-"))
+
+	headers := m.CustomHTTPHeaders
+	if headers == nil {
+		headers = map[string]string{}
+	}
+
+	buf, err := json.Marshal(headers)
+	if err != nil {
+		return nil, err
+	}
+
+	v.Set("custom_http_headers", string(buf))
+
+	return v, nil
+}
+
+type apiError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type pagination struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+	Total  int `json:"total"`
+}
+
+type response struct {
+	Stat       string      `json:"stat"`
+	Error      *apiError   `json:"error,omitempty"`
+	Monitor    *Monitor    `json:"monitor,omitempty"`
+	Monitors   []*Monitor  `json:"monitors,omitempty"`
+	Pagination *pagination `json:"pagination,omitempty"`
+}
+
+// client is a minimal client for the UptimeRobot v2 API.
+type client struct {
+	httpClient *http.Client
+	baseURL    string
+	ipListURL  string
+	apiKey     string
+}
+
+func newClient(apiKey string) *client {
+	return &client{
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		baseURL:    defaultAPIBaseURL,
+		ipListURL:  defaultIPListURL,
+		apiKey:     apiKey,
+	}
+}
+
+// getMonitors returns all monitors matching the search string. The
+// UptimeRobot API matches the search string against the friendly name and
+// the url of the monitors, callers have to filter the result if they need an
+// exact match.
+func (c *client) getMonitors(ctx context.Context, search string) ([]*Monitor, error) {
+	var monitors []*Monitor
+
+	for offset := 0; ; offset += pageSize {
+		params := url.Values{}
+		params.Set("search", search)
+		params.Set("offset", strconv.Itoa(offset))
+		params.Set("limit", strconv.Itoa(pageSize))
+		params.Set("custom_http_headers", "1")
+
+		resp, err := c.call(ctx, "getMonitors", params)
+		if err != nil {
+			return nil, err
+		}
+
+		monitors = append(monitors, resp.Monitors...)
+
+		if resp.Pagination == nil || offset+pageSize >= resp.Pagination.Total {
+			return monitors, nil
+		}
+	}
+}
+
+// getAccountDetails retrieves the account details. It is used to verify the
+// API key, so the details themselves are discarded.
+func (c *client) getAccountDetails(ctx context.Context) error {
+	_, err := c.call(ctx, "getAccountDetails", url.Values{})
+
+	return err
+}
+
+func (c *client) newMonitor(ctx context.Context, monitor *Monitor) (*Monitor, error) {
+	params, err := monitor.values()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.call(ctx, "newMonitor", params)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Monitor == nil {
+		return nil, errors.New("response does not contain the created monitor")
+	}
+
+	return resp.Monitor, nil
+}
+
+func (c *client) editMonitor(ctx context.Context, monitor *Monitor) error {
+	params, err := monitor.values()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.call(ctx, "editMonitor", params)
+
+	return err
+}
+
+func (c *client) deleteMonitor(ctx context.Context, id int64) error {
+	params := url.Values{}
+	params.Set("id", strconv.FormatInt(id, 10))
+
+	_, err := c.call(ctx, "deleteMonitor", params)
+
+	return err
+}
+
+// getIPs retrieves the list of IP addresses that UptimeRobot performs checks
+// from.
+func (c *client) getIPs(ctx context.Context) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.ipListURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d while fetching ip list", resp.StatusCode)
+	}
+
+	var ips []string
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		ip := strings.TrimSpace(scanner.Text())
+		if ip != "" {
+			ips = append(ips, ip)
+		}
+	}
+
+	return ips, scanner.Err()
+}
+
+// call performs the API call method with params and decodes the response.
+// Returns an error if the API responds with a failure.
+func (c *client) call(ctx context.Context, method string, params url.Values) (*response, error) {
+	params.Set("api_key", c.apiKey)
+	params.Set("format", "json")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/%s", c.baseURL, method), strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected status code %d from %s", resp.StatusCode, method)
+	}
+
+	var r response
+
+	err = json.NewDecoder(resp.Body).Decode(&r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode %s response", method)
+	}
+
+	if r.Stat != "ok" {
+		if r.Error != nil {
+			return nil, errors.Errorf("%s failed: %s: %s", method, r.Error.Type, r.Error.Message)
+		}
+
+		return nil, errors.Errorf("%s failed", method)
+	}
+
+	return &r, nil
+}
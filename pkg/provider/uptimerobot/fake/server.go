@@ -0,0 +1,330 @@
+// Package fake provides an in-memory stand-in for the UptimeRobot v2 API that
+// can be used in unit tests.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Server is an httptest server that implements the subset of the UptimeRobot
+// v2 API used by the uptimerobot provider. Monitors are kept in memory.
+type Server struct {
+	*httptest.Server
+
+	// APIKey is the API key that is expected in every request.
+	APIKey string
+
+	// IPs is the list of IPs served at the IP list endpoint.
+	IPs []string
+
+	mu       sync.Mutex
+	nextID   int64
+	monitors map[int64]map[string]interface{}
+	requests []string
+}
+
+// NewServer creates and starts a new *Server which expects apiKey to be
+// present in every API request. The server must be closed by the caller.
+func NewServer(apiKey string) *Server {
+	s := &Server{
+		APIKey:   apiKey,
+		nextID:   1,
+		monitors: make(map[int64]map[string]interface{}),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/getMonitors", s.handle(s.getMonitors))
+	mux.HandleFunc("/v2/newMonitor", s.handle(s.newMonitor))
+	mux.HandleFunc("/v2/editMonitor", s.handle(s.editMonitor))
+	mux.HandleFunc("/v2/deleteMonitor", s.handle(s.deleteMonitor))
+	mux.HandleFunc("/v2/getAccountDetails", s.handle(s.getAccountDetails))
+	mux.HandleFunc("/ips.txt", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, strings.Join(s.IPs, "\r\n"))
+	})
+
+	s.Server = httptest.NewServer(mux)
+
+	return s
+}
+
+// APIBaseURL returns the base URL of the fake API.
+func (s *Server) APIBaseURL() string {
+	return s.URL + "/v2"
+}
+
+// IPListURL returns the URL of the fake IP list.
+func (s *Server) IPListURL() string {
+	return s.URL + "/ips.txt"
+}
+
+// AddMonitor adds a monitor with given fields to the server and returns its
+// ID.
+func (s *Server) AddMonitor(fields map[string]interface{}) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID
+	s.nextID++
+
+	monitor := map[string]interface{}{"id": id}
+	for k, v := range fields {
+		monitor[k] = v
+	}
+
+	s.monitors[id] = monitor
+
+	return id
+}
+
+// Monitors returns a snapshot of all monitors sorted by ID.
+func (s *Server) Monitors() []map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int64, 0, len(s.monitors))
+	for id := range s.monitors {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	monitors := make([]map[string]interface{}, len(ids))
+	for i, id := range ids {
+		monitors[i] = copyMonitor(s.monitors[id])
+	}
+
+	return monitors
+}
+
+// Requests returns the names of all API methods that were called in order.
+func (s *Server) Requests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.requests...)
+}
+
+type handlerFunc func(r *http.Request) (map[string]interface{}, error)
+
+func (s *Server) handle(h handlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.requests = append(s.requests, strings.TrimPrefix(r.URL.Path, "/v2/"))
+
+		var resp map[string]interface{}
+		var err error
+
+		if r.PostForm.Get("api_key") != s.APIKey {
+			err = fmt.Errorf("api_key not valid")
+		} else {
+			resp, err = h(r)
+		}
+
+		if err != nil {
+			resp = map[string]interface{}{
+				"stat": "fail",
+				"error": map[string]string{
+					"type":    "invalid_parameter",
+					"message": err.Error(),
+				},
+			}
+		} else {
+			resp["stat"] = "ok"
+		}
+
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+func (s *Server) getMonitors(r *http.Request) (map[string]interface{}, error) {
+	search := r.PostForm.Get("search")
+	offset, _ := strconv.Atoi(r.PostForm.Get("offset"))
+
+	limit, err := strconv.Atoi(r.PostForm.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 50
+	}
+
+	ids := make([]int64, 0, len(s.monitors))
+	for id, monitor := range s.monitors {
+		if search == "" ||
+			strings.Contains(fmt.Sprint(monitor["friendly_name"]), search) ||
+			strings.Contains(fmt.Sprint(monitor["url"]), search) {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	monitors := []map[string]interface{}{}
+	for i := offset; i < len(ids) && i < offset+limit; i++ {
+		monitors = append(monitors, copyMonitor(s.monitors[ids[i]]))
+	}
+
+	resp := map[string]interface{}{
+		"pagination": map[string]int{
+			"offset": offset,
+			"limit":  limit,
+			"total":  len(ids),
+		},
+		"monitors": monitors,
+	}
+
+	return resp, nil
+}
+
+func (s *Server) getAccountDetails(_ *http.Request) (map[string]interface{}, error) {
+	resp := map[string]interface{}{
+		"account": map[string]interface{}{
+			"email":         "user@example.com",
+			"monitor_limit": 50,
+			"up_monitors":   len(s.monitors),
+		},
+	}
+
+	return resp, nil
+}
+
+func (s *Server) newMonitor(r *http.Request) (map[string]interface{}, error) {
+	for _, param := range []string{"friendly_name", "url", "type"} {
+		if r.PostForm.Get(param) == "" {
+			return nil, fmt.Errorf("%s parameter is required", param)
+		}
+	}
+
+	monitor, err := monitorFromForm(r)
+	if err != nil {
+		return nil, err
+	}
+
+	id := s.nextID
+	s.nextID++
+
+	monitor["id"] = id
+	s.monitors[id] = monitor
+
+	resp := map[string]interface{}{
+		"monitor": map[string]interface{}{"id": id, "status": 1},
+	}
+
+	return resp, nil
+}
+
+func (s *Server) editMonitor(r *http.Request) (map[string]interface{}, error) {
+	id, monitor, err := s.lookupMonitor(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.PostForm.Get("type") != "" {
+		return nil, fmt.Errorf("monitor type cannot be edited")
+	}
+
+	fields, err := monitorFromForm(r)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range fields {
+		monitor[k] = v
+	}
+
+	resp := map[string]interface{}{
+		"monitor": map[string]interface{}{"id": id},
+	}
+
+	return resp, nil
+}
+
+func (s *Server) deleteMonitor(r *http.Request) (map[string]interface{}, error) {
+	id, _, err := s.lookupMonitor(r)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(s.monitors, id)
+
+	resp := map[string]interface{}{
+		"monitor": map[string]interface{}{"id": id},
+	}
+
+	return resp, nil
+}
+
+func (s *Server) lookupMonitor(r *http.Request) (int64, map[string]interface{}, error) {
+	id, err := strconv.ParseInt(r.PostForm.Get("id"), 10, 64)
+	if err != nil {
+		return 0, nil, fmt.Errorf("id parameter is invalid")
+	}
+
+	monitor, ok := s.monitors[id]
+	if !ok {
+		return 0, nil, fmt.Errorf("monitor not found")
+	}
+
+	return id, monitor, nil
+}
+
+// monitorFromForm converts the form values of r into a monitor. Numeric values
+// are converted to numbers and custom http headers are decoded from json just
+// like the real API would return them.
+func monitorFromForm(r *http.Request) (map[string]interface{}, error) {
+	monitor := make(map[string]interface{})
+
+	for key := range r.PostForm {
+		value := r.PostForm.Get(key)
+
+		switch key {
+		case "api_key", "format", "id":
+			continue
+		case "type", "interval", "timeout", "http_method":
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s parameter is invalid", key)
+			}
+
+			monitor[key] = n
+		case "custom_http_headers":
+			headers := map[string]string{}
+			if err := json.Unmarshal([]byte(value), &headers); err != nil {
+				return nil, fmt.Errorf("%s parameter is invalid", key)
+			}
+
+			monitor[key] = headers
+		default:
+			monitor[key] = value
+		}
+	}
+
+	return monitor, nil
+}
+
+func copyMonitor(monitor map[string]interface{}) map[string]interface{} {
+	c := make(map[string]interface{}, len(monitor))
+	for k, v := range monitor {
+		c[k] = v
+	}
+
+	return c
+}
@@ -0,0 +1,193 @@
+package uptimerobot
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/cache"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("uptimerobot-provider")
+
+// sourceRangeCacheKey is the key under which the UptimeRobot source ranges are
+// cached. They are the same for all monitors.
+const sourceRangeCacheKey = "ipv4"
+
+// Provider manages UptimeRobot website monitors.
+type Provider struct {
+	client           *client
+	config           config.UptimeRobotConfig
+	builder          *builder
+	sourceRangeCache *cache.Expiring
+}
+
+// NewProvider creates a new UptimeRobot provider with given
+// UptimeRobotConfig.
+func NewProvider(config config.UptimeRobotConfig) *Provider {
+	return &Provider{
+		client:           newClient(config.APIKey),
+		config:           config,
+		builder:          newBuilder(config.MonitorDefaults),
+		sourceRangeCache: cache.NewExpiring(),
+	}
+}
+
+// Create implements provider.Interface.
+func (p *Provider) Create(ctx context.Context, model *models.Monitor) error {
+	monitor, err := p.builder.FromModel(model)
+	if err != nil {
+		return errors.Wrapf(err, "failed to build uptimerobot monitor %q from model", model.Name)
+	}
+
+	_, err = p.client.newMonitor(ctx, monitor)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create uptimerobot monitor %q", model.Name)
+	}
+
+	return nil
+}
+
+// Get implements provider.Interface.
+func (p *Provider) Get(ctx context.Context, name string) (*models.Monitor, error) {
+	monitors, err := p.client.getMonitors(ctx, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list uptimerobot monitors")
+	}
+
+	for _, monitor := range monitors {
+		if monitor.FriendlyName != name {
+			continue
+		}
+
+		m := &models.Monitor{
+			ID:    strconv.FormatInt(monitor.ID, 10),
+			Name:  monitor.FriendlyName,
+			URL:   monitor.URL,
+			Owner: models.ParseOwner(monitor.CustomHTTPHeaders[ownerHeader]),
+		}
+
+		return m, nil
+	}
+
+	return nil, models.ErrMonitorNotFound
+}
+
+// Update implements provider.Interface.
+func (p *Provider) Update(ctx context.Context, model *models.Monitor) error {
+	monitor, err := p.builder.FromModel(model)
+	if err != nil {
+		return errors.Wrapf(err, "failed to build uptimerobot monitor %q from model", model.Name)
+	}
+
+	err = p.client.editMonitor(ctx, monitor)
+	if err != nil {
+		return errors.Wrapf(err, "failed to update uptimerobot monitor %q with ID %s", model.Name, model.ID)
+	}
+
+	return nil
+}
+
+// Rename implements provider.Renamer. The monitor is updated in place and
+// keeps its ID.
+func (p *Provider) Rename(ctx context.Context, oldName string, model *models.Monitor) error {
+	existing, err := p.Get(ctx, oldName)
+	if err != nil {
+		return err
+	}
+
+	monitor := *model
+	monitor.ID = existing.ID
+
+	return p.Update(ctx, &monitor)
+}
+
+// Delete implements provider.Interface.
+func (p *Provider) Delete(ctx context.Context, name string) error {
+	monitor, err := p.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseInt(monitor.ID, 10, 64)
+	if err != nil {
+		return errors.Wrapf(err, "invalid uptimerobot monitor ID %q", monitor.ID)
+	}
+
+	err = p.client.deleteMonitor(ctx, id)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete uptimerobot monitor with ID %s", monitor.ID)
+	}
+
+	return nil
+}
+
+// List implements provider.Interface. Owned monitors are identified by the
+// owner header.
+func (p *Provider) List(ctx context.Context) ([]*models.Monitor, error) {
+	monitors, err := p.client.getMonitors(ctx, "")
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list uptimerobot monitors")
+	}
+
+	result := make([]*models.Monitor, 0, len(monitors))
+
+	for _, monitor := range monitors {
+		owner := models.ParseOwner(monitor.CustomHTTPHeaders[ownerHeader])
+		if owner == nil {
+			continue
+		}
+
+		result = append(result, &models.Monitor{
+			ID:    strconv.FormatInt(monitor.ID, 10),
+			Name:  monitor.FriendlyName,
+			URL:   monitor.URL,
+			Owner: owner,
+		})
+	}
+
+	return result, nil
+}
+
+// Check implements provider.Checker. The API key is verified by retrieving
+// the account details.
+func (p *Provider) Check(ctx context.Context) error {
+	err := p.client.getAccountDetails(ctx)
+
+	return errors.Wrapf(err, "failed to get uptimerobot account details")
+}
+
+// GetIPSourceRanges implements provider.Interface.
+func (p *Provider) GetIPSourceRanges(ctx context.Context, _ *models.Monitor) ([]string, error) {
+	cachedSourceRanges, ok := p.sourceRangeCache.Get(sourceRangeCacheKey)
+	if ok {
+		return cachedSourceRanges.([]string), nil
+	}
+
+	ips, err := p.client.getIPs(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch uptimerobot ip list")
+	}
+
+	log.V(1).Info("found uptimerobot ip addresses", "count", len(ips), "ips", ips)
+
+	sourceRanges := make([]string, len(ips))
+	for i, ip := range ips {
+		if strings.Contains(ip, "/") {
+			sourceRanges[i] = ip
+		} else {
+			sourceRanges[i] = ip + "/32"
+		}
+	}
+
+	// The UptimeRobot ip list rarely changes so we can just cache it for a
+	// day.
+	p.sourceRangeCache.Set(sourceRangeCacheKey, sourceRanges, 24*time.Hour)
+
+	return sourceRanges, nil
+}
@@ -0,0 +1,409 @@
+package uptimerobot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/provider/uptimerobot/fake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/cache"
+)
+
+func TestProvider_Create(t *testing.T) {
+	tests := []struct {
+		name     string
+		model    *models.Monitor
+		config   config.UptimeRobotConfig
+		validate func(*testing.T, *fake.Server)
+		expected string
+	}{
+		{
+			name: "creates monitor",
+			model: &models.Monitor{
+				Name: "my-monitor",
+				URL:  "http://my-monitor",
+			},
+			config: config.UptimeRobotConfig{
+				MonitorDefaults: config.UptimeRobotMonitorDefaults{
+					AlertContacts: []string{"123_0_0"},
+					HTTPMethod:    "GET",
+					Interval:      300,
+					Timeout:       30,
+				},
+			},
+			validate: func(t *testing.T, s *fake.Server) {
+				assert.Equal(t, []map[string]interface{}{
+					{
+						"id":                  int64(1),
+						"friendly_name":       "my-monitor",
+						"url":                 "http://my-monitor",
+						"type":                1,
+						"interval":            300,
+						"timeout":             30,
+						"http_method":         2,
+						"http_username":       "",
+						"http_password":       "",
+						"alert_contacts":      "123_0_0",
+						"custom_http_headers": map[string]string{},
+					},
+				}, s.Monitors())
+			},
+		},
+		{
+			name: "annotations override monitor defaults",
+			model: &models.Monitor{
+				Name: "my-monitor",
+				URL:  "http://my-monitor",
+				Annotations: config.Annotations{
+					config.AnnotationUptimeRobotAlertContacts:     "456_0_0,789_5_0",
+					config.AnnotationUptimeRobotCustomHTTPHeaders: `{"X-Foo":"bar"}`,
+					config.AnnotationUptimeRobotHTTPMethod:        "head",
+					config.AnnotationUptimeRobotHTTPUsername:      "user",
+					config.AnnotationUptimeRobotHTTPPassword:      "pass",
+					config.AnnotationUptimeRobotInterval:          "60",
+					config.AnnotationUptimeRobotTimeout:           "10",
+				},
+			},
+			config: config.UptimeRobotConfig{
+				MonitorDefaults: config.UptimeRobotMonitorDefaults{
+					AlertContacts: []string{"123_0_0"},
+					HTTPMethod:    "GET",
+					Interval:      300,
+					Timeout:       30,
+				},
+			},
+			validate: func(t *testing.T, s *fake.Server) {
+				assert.Equal(t, []map[string]interface{}{
+					{
+						"id":                  int64(1),
+						"friendly_name":       "my-monitor",
+						"url":                 "http://my-monitor",
+						"type":                1,
+						"interval":            60,
+						"timeout":             10,
+						"http_method":         1,
+						"http_username":       "user",
+						"http_password":       "pass",
+						"alert_contacts":      "456_0_0-789_5_0",
+						"custom_http_headers": map[string]string{"X-Foo": "bar"},
+					},
+				}, s.Monitors())
+			},
+		},
+		{
+			name: "do not create monitor if the ingress annotations are invalid",
+			model: &models.Monitor{
+				Name: "my-monitor",
+				URL:  "http://my-monitor",
+				Annotations: config.Annotations{
+					config.AnnotationUptimeRobotCustomHTTPHeaders: "{invalidjson",
+				},
+			},
+			config: config.UptimeRobotConfig{
+				MonitorDefaults: config.UptimeRobotMonitorDefaults{
+					HTTPMethod: "GET",
+				},
+			},
+			validate: func(t *testing.T, s *fake.Server) {
+				assert.Len(t, s.Requests(), 0)
+			},
+			expected: `failed to build uptimerobot monitor "my-monitor" from model: invalid json in annotation "uptimerobot.ingress-monitor.bonial.com/custom-http-headers": {invalidjson: invalid character 'i' looking for beginning of object key string`,
+		},
+		{
+			name: "do not create monitor if the http method is not supported",
+			model: &models.Monitor{
+				Name: "my-monitor",
+				URL:  "http://my-monitor",
+			},
+			config: config.UptimeRobotConfig{
+				MonitorDefaults: config.UptimeRobotMonitorDefaults{
+					HTTPMethod: "TRACE",
+				},
+			},
+			validate: func(t *testing.T, s *fake.Server) {
+				assert.Len(t, s.Requests(), 0)
+			},
+			expected: `failed to build uptimerobot monitor "my-monitor" from model: unsupported http method "TRACE"`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, s := newTestProvider(t, test.config)
+
+			err := p.Create(context.Background(), test.model)
+			if test.expected != "" {
+				require.Error(t, err)
+				assert.Equal(t, test.expected, err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+
+			if test.validate != nil {
+				test.validate(t, s)
+			}
+		})
+	}
+}
+
+func TestProvider_Update(t *testing.T) {
+	p, s := newTestProvider(t, config.UptimeRobotConfig{
+		MonitorDefaults: config.UptimeRobotMonitorDefaults{
+			HTTPMethod: "GET",
+			Interval:   300,
+			Timeout:    30,
+		},
+	})
+
+	id := s.AddMonitor(map[string]interface{}{
+		"friendly_name": "my-monitor",
+		"url":           "http://my-monitor",
+		"type":          1,
+	})
+
+	err := p.Update(context.Background(), &models.Monitor{
+		ID:   "1",
+		Name: "my-monitor",
+		URL:  "https://my-monitor/health",
+	})
+	require.NoError(t, err)
+
+	monitors := s.Monitors()
+	require.Len(t, monitors, 1)
+	assert.Equal(t, id, monitors[0]["id"])
+	assert.Equal(t, "https://my-monitor/health", monitors[0]["url"])
+	assert.Equal(t, 1, monitors[0]["type"])
+	assert.Equal(t, 300, monitors[0]["interval"])
+
+	err = p.Update(context.Background(), &models.Monitor{
+		ID:   "42",
+		Name: "my-monitor",
+		URL:  "https://my-monitor/health",
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "editMonitor failed: invalid_parameter: monitor not found")
+}
+
+func TestProvider_Get(t *testing.T) {
+	tests := []struct {
+		name        string
+		monitorName string
+		setup       func(*fake.Server)
+		expected    *models.Monitor
+		expectedErr error
+	}{
+		{
+			name:        "returns models.ErrMonitorNotFound if monitor is not found",
+			monitorName: "my-monitor",
+			setup: func(s *fake.Server) {
+				s.AddMonitor(map[string]interface{}{"friendly_name": "some-other-monitor"})
+			},
+			expectedErr: models.ErrMonitorNotFound,
+		},
+		{
+			name:        "does not return monitors which only partially match the name",
+			monitorName: "my-monitor",
+			setup: func(s *fake.Server) {
+				s.AddMonitor(map[string]interface{}{"friendly_name": "my-monitor-2"})
+			},
+			expectedErr: models.ErrMonitorNotFound,
+		},
+		{
+			name:        "returns monitor with name",
+			monitorName: "my-monitor",
+			setup: func(s *fake.Server) {
+				s.AddMonitor(map[string]interface{}{"friendly_name": "my-monitor-2"})
+				s.AddMonitor(map[string]interface{}{
+					"friendly_name": "my-monitor",
+					"url":           "http://my-monitor",
+				})
+			},
+			expected: &models.Monitor{
+				ID:   "2",
+				Name: "my-monitor",
+				URL:  "http://my-monitor",
+			},
+		},
+		{
+			name:        "paginates through search results",
+			monitorName: "my-monitor",
+			setup: func(s *fake.Server) {
+				for i := 0; i < 120; i++ {
+					s.AddMonitor(map[string]interface{}{"friendly_name": "my-monitor-x"})
+				}
+
+				s.AddMonitor(map[string]interface{}{
+					"friendly_name": "my-monitor",
+					"url":           "http://my-monitor",
+				})
+			},
+			expected: &models.Monitor{
+				ID:   "121",
+				Name: "my-monitor",
+				URL:  "http://my-monitor",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, s := newTestProvider(t, config.UptimeRobotConfig{})
+
+			if test.setup != nil {
+				test.setup(s)
+			}
+
+			monitor, err := p.Get(context.Background(), test.monitorName)
+			if test.expectedErr != nil {
+				require.Error(t, err)
+				assert.Equal(t, test.expectedErr.Error(), err.Error())
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, monitor)
+			}
+		})
+	}
+}
+
+func TestProvider_Delete(t *testing.T) {
+	tests := []struct {
+		name        string
+		monitorName string
+		setup       func(*fake.Server)
+		validate    func(*testing.T, *fake.Server)
+		expected    error
+	}{
+		{
+			name:        "returns if monitor is not found",
+			monitorName: "my-monitor",
+			expected:    models.ErrMonitorNotFound,
+		},
+		{
+			name:        "deletes monitor",
+			monitorName: "my-monitor",
+			setup: func(s *fake.Server) {
+				s.AddMonitor(map[string]interface{}{"friendly_name": "some-other-monitor"})
+				s.AddMonitor(map[string]interface{}{"friendly_name": "my-monitor"})
+			},
+			validate: func(t *testing.T, s *fake.Server) {
+				monitors := s.Monitors()
+				require.Len(t, monitors, 1)
+				assert.Equal(t, "some-other-monitor", monitors[0]["friendly_name"])
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p, s := newTestProvider(t, config.UptimeRobotConfig{})
+
+			if test.setup != nil {
+				test.setup(s)
+			}
+
+			err := p.Delete(context.Background(), test.monitorName)
+			if test.expected != nil {
+				require.Error(t, err)
+				assert.Equal(t, test.expected.Error(), err.Error())
+			} else {
+				require.NoError(t, err)
+			}
+
+			if test.validate != nil {
+				test.validate(t, s)
+			}
+		})
+	}
+}
+
+func TestProvider_List(t *testing.T) {
+	p, s := newTestProvider(t, config.UptimeRobotConfig{
+		MonitorDefaults: config.UptimeRobotMonitorDefaults{
+			HTTPMethod:        "GET",
+			CustomHTTPHeaders: map[string]string{"Accept": "text/html"},
+		},
+	})
+
+	s.AddMonitor(map[string]interface{}{"friendly_name": "unowned", "url": "http://unowned"})
+
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{
+		Name:  "owned",
+		URL:   "http://owned",
+		Owner: &models.Owner{Namespace: "kube-system", Name: "foo"},
+	}))
+
+	assert.Equal(t, map[string]string{
+		"Accept":    "text/html",
+		ownerHeader: "cluster=&name=foo&namespace=kube-system&uid=",
+	}, s.Monitors()[1]["custom_http_headers"])
+
+	monitors, err := p.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Monitor{
+		{
+			ID:    "2",
+			Name:  "owned",
+			URL:   "http://owned",
+			Owner: &models.Owner{Namespace: "kube-system", Name: "foo"},
+		},
+	}, monitors)
+}
+
+func TestProvider_GetIPSourceRanges(t *testing.T) {
+	p, s := newTestProvider(t, config.UptimeRobotConfig{})
+	s.IPs = []string{"1.2.3.4", "5.6.7.8", "10.0.0.0/24"}
+
+	expected := []string{"1.2.3.4/32", "5.6.7.8/32", "10.0.0.0/24"}
+
+	ips, err := p.GetIPSourceRanges(context.Background(), &models.Monitor{Name: "foo"})
+	require.NoError(t, err)
+	require.Equal(t, expected, ips)
+
+	// The ip list is cached, so changes on the server are not visible.
+	s.IPs = []string{"1.3.3.7"}
+
+	ips, err = p.GetIPSourceRanges(context.Background(), &models.Monitor{Name: "bar"})
+	require.NoError(t, err)
+	require.Equal(t, expected, ips)
+}
+
+func TestProvider_InvalidAPIKey(t *testing.T) {
+	p, _ := newTestProvider(t, config.UptimeRobotConfig{})
+	p.client.apiKey = "invalid"
+
+	_, err := p.Get(context.Background(), "my-monitor")
+	require.Error(t, err)
+	assert.Equal(t, "failed to list uptimerobot monitors: getMonitors failed: invalid_parameter: api_key not valid", err.Error())
+}
+
+func newTestProvider(t *testing.T, config config.UptimeRobotConfig) (*Provider, *fake.Server) {
+	server := fake.NewServer("the-api-key")
+	t.Cleanup(server.Close)
+
+	client := newClient("the-api-key")
+	client.baseURL = server.APIBaseURL()
+	client.ipListURL = server.IPListURL()
+
+	provider := &Provider{
+		client:           client,
+		config:           config,
+		builder:          newBuilder(config.MonitorDefaults),
+		sourceRangeCache: cache.NewExpiring(),
+	}
+
+	return provider, server
+}
+
+func TestProvider_Check(t *testing.T) {
+	p, s := newTestProvider(t, config.UptimeRobotConfig{})
+
+	require.NoError(t, p.Check(context.Background()))
+
+	p.client.apiKey = "invalid"
+
+	require.Error(t, p.Check(context.Background()))
+	assert.Equal(t, []string{"getAccountDetails", "getAccountDetails"}, s.Requests())
+}
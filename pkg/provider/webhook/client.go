@@ -0,0 +1,249 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/pkg/errors"
+)
+
+// Payload is the JSON rendering of a monitor that is sent to the create and
+// update endpoints.
+type Payload struct {
+	ID          string            `json:"id,omitempty"`
+	Name        string            `json:"name"`
+	URL         string            `json:"url"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+}
+
+// response is the result of a request that did not fail with a network
+// error or a retryable status.
+type response struct {
+	statusCode int
+	body       []byte
+}
+
+func (r *response) isSuccess() bool {
+	return r.statusCode >= 200 && r.statusCode < 300
+}
+
+// endpointData is passed to the endpoint templates.
+type endpointData struct {
+	ID   string
+	Name string
+}
+
+type client struct {
+	config     config.WebhookConfig
+	httpClient *http.Client
+	endpoints  map[string]*template.Template
+	now        func() time.Time
+}
+
+func newClient(config config.WebhookConfig) (*client, error) {
+	endpoints := map[string]string{
+		"create":       config.Endpoints.Create,
+		"get":          config.Endpoints.Get,
+		"update":       config.Endpoints.Update,
+		"delete":       config.Endpoints.Delete,
+		"sourceRanges": config.Endpoints.SourceRanges,
+		"list":         config.Endpoints.List,
+	}
+
+	c := &client{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout.Duration},
+		endpoints:  make(map[string]*template.Template, len(endpoints)),
+		now:        time.Now,
+	}
+
+	for name, text := range endpoints {
+		tpl, err := template.New(name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s endpoint template", name)
+		}
+
+		c.endpoints[name] = tpl
+	}
+
+	return c, nil
+}
+
+// endpointURL renders the named endpoint template for the monitor. Relative
+// endpoints are resolved against the configured base URL.
+func (c *client) endpointURL(name string, model *models.Monitor) (string, error) {
+	data := endpointData{
+		ID:   url.PathEscape(model.ID),
+		Name: url.PathEscape(model.Name),
+	}
+
+	var buf bytes.Buffer
+
+	err := c.endpoints[name].Execute(&buf, data)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to render %s endpoint", name)
+	}
+
+	endpoint := buf.String()
+	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		return endpoint, nil
+	}
+
+	return strings.TrimSuffix(c.config.BaseURL, "/") + "/" + strings.TrimPrefix(endpoint, "/"), nil
+}
+
+// do sends a request to url. The body is JSON encoded if non-nil. Requests
+// using idempotent methods are retried on network errors and retryable status
+// codes. POST requests are sent only once, as a retry could create duplicate
+// monitors. Returns the response for all other status codes, the caller has
+// to check them.
+func (c *client) do(ctx context.Context, method, url string, body interface{}) (*response, error) {
+	var payload []byte
+
+	if body != nil {
+		var err error
+
+		payload, err = json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	maxRetries := c.config.MaxRetries
+	if method == http.MethodPost {
+		maxRetries = 0
+	}
+
+	var lastErr error
+
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(time.Duration(attempt) * c.config.RetryBackoff.Duration):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		resp, err := c.send(ctx, method, url, payload)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		if resp.statusCode == http.StatusTooManyRequests || resp.statusCode >= 500 {
+			lastErr = statusError(method, url, resp)
+			continue
+		}
+
+		return resp, nil
+	}
+
+	if maxRetries == 0 {
+		return nil, lastErr
+	}
+
+	return nil, errors.Wrapf(lastErr, "giving up after %d attempts", maxRetries+1)
+}
+
+func (c *client) send(ctx context.Context, method, url string, payload []byte) (*response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	for name, value := range c.config.Headers {
+		req.Header.Set(name, value)
+	}
+
+	if c.config.Secret != "" {
+		timestamp := strconv.FormatInt(c.now().Unix(), 10)
+
+		req.Header.Set(c.config.TimestampHeader, timestamp)
+		req.Header.Set(c.config.SignatureHeader, sign(c.config.Secret, timestamp, method, req.URL.RequestURI(), payload))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &response{statusCode: resp.StatusCode, body: body}, nil
+}
+
+// sign computes the HMAC-SHA256 signature of a request. The signed message
+// consists of the timestamp, the method, the request URI and the payload,
+// separated by newlines. Requests without body are signed using an empty
+// payload.
+func sign(secret, timestamp, method, requestURI string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "\n" + method + "\n" + requestURI + "\n"))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func statusError(method, url string, resp *response) error {
+	return errors.Errorf("%s %s: unexpected status %d: %s", method, url, resp.statusCode, strings.TrimSpace(string(resp.body)))
+}
+
+// lookupField looks up the dot separated field in the JSON body and returns
+// its string representation. Returns false if the field does not exist or is
+// not a string or number.
+func lookupField(body []byte, field string) (string, bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	var value interface{}
+
+	if err := decoder.Decode(&value); err != nil {
+		return "", false
+	}
+
+	for _, key := range strings.Split(field, ".") {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+
+		value, ok = obj[key]
+		if !ok {
+			return "", false
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,270 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/cache"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("webhook-provider")
+
+// sourceRangeCacheKey is the key under which the source ranges are cached.
+// They are the same for all monitors.
+const sourceRangeCacheKey = "sourceRanges"
+
+// Provider manages monitors via a generic REST API.
+type Provider struct {
+	client           *client
+	config           config.WebhookConfig
+	sourceRangeCache *cache.Expiring
+}
+
+// NewProvider creates a new webhook provider with given WebhookConfig.
+// Returns an error if the endpoint templates are invalid.
+func NewProvider(config config.WebhookConfig) (*Provider, error) {
+	client, err := newClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Provider{
+		client:           client,
+		config:           config,
+		sourceRangeCache: cache.NewExpiring(),
+	}
+
+	return p, nil
+}
+
+// Create implements provider.Interface. The ID returned by the create
+// endpoint is stored in the ID field of model.
+func (p *Provider) Create(ctx context.Context, model *models.Monitor) error {
+	url, err := p.client.endpointURL("create", model)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.do(ctx, http.MethodPost, url, newPayload(model))
+	if err != nil {
+		return errors.Wrapf(err, "failed to create webhook monitor %q", model.Name)
+	}
+
+	if !resp.isSuccess() {
+		return errors.Wrapf(statusError(http.MethodPost, url, resp), "failed to create webhook monitor %q", model.Name)
+	}
+
+	if id, ok := lookupField(resp.body, p.config.IDField); ok {
+		log.V(1).Info("webhook monitor created", "monitor", model.Name, "id", id)
+		model.ID = id
+	}
+
+	return nil
+}
+
+// Get implements provider.Interface.
+func (p *Provider) Get(ctx context.Context, name string) (*models.Monitor, error) {
+	url, err := p.client.endpointURL("get", &models.Monitor{Name: name})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.do(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get webhook monitor %q", name)
+	}
+
+	if resp.statusCode == http.StatusNotFound {
+		return nil, models.ErrMonitorNotFound
+	}
+
+	if !resp.isSuccess() {
+		return nil, errors.Wrapf(statusError(http.MethodGet, url, resp), "failed to get webhook monitor %q", name)
+	}
+
+	id, ok := lookupField(resp.body, p.config.IDField)
+	if !ok {
+		return nil, errors.Errorf("response for webhook monitor %q does not contain the ID field %q", name, p.config.IDField)
+	}
+
+	monitor := &models.Monitor{
+		ID:   id,
+		Name: name,
+	}
+
+	if monitorURL, ok := lookupField(resp.body, "url"); ok {
+		monitor.URL = monitorURL
+	}
+
+	if owner, ok := lookupField(resp.body, "owner"); ok {
+		monitor.Owner = models.ParseOwner(owner)
+	}
+
+	return monitor, nil
+}
+
+// Update implements provider.Interface.
+func (p *Provider) Update(ctx context.Context, model *models.Monitor) error {
+	url, err := p.client.endpointURL("update", model)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.do(ctx, http.MethodPut, url, newPayload(model))
+	if err != nil {
+		return errors.Wrapf(err, "failed to update webhook monitor %q", model.Name)
+	}
+
+	if !resp.isSuccess() {
+		return errors.Wrapf(statusError(http.MethodPut, url, resp), "failed to update webhook monitor %q", model.Name)
+	}
+
+	return nil
+}
+
+// Delete implements provider.Interface.
+func (p *Provider) Delete(ctx context.Context, name string) error {
+	monitor, err := p.Get(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	url, err := p.client.endpointURL("delete", monitor)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.do(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to delete webhook monitor %q", name)
+	}
+
+	if resp.statusCode == http.StatusNotFound {
+		return models.ErrMonitorNotFound
+	}
+
+	if !resp.isSuccess() {
+		return errors.Wrapf(statusError(http.MethodDelete, url, resp), "failed to delete webhook monitor %q", name)
+	}
+
+	return nil
+}
+
+// List implements provider.Interface. Owned monitors are identified by the
+// owner field. Returns an error if no list endpoint is configured.
+func (p *Provider) List(ctx context.Context) ([]*models.Monitor, error) {
+	if p.config.Endpoints.List == "" {
+		return nil, errors.New("webhook list endpoint is not configured")
+	}
+
+	url, err := p.client.endpointURL("list", &models.Monitor{})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.do(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list webhook monitors")
+	}
+
+	if !resp.isSuccess() {
+		return nil, errors.Wrapf(statusError(http.MethodGet, url, resp), "failed to list webhook monitors")
+	}
+
+	var items []json.RawMessage
+
+	err = json.Unmarshal(resp.body, &items)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode webhook monitor list")
+	}
+
+	monitors := make([]*models.Monitor, 0, len(items))
+
+	for _, item := range items {
+		ownerField, _ := lookupField(item, "owner")
+
+		owner := models.ParseOwner(ownerField)
+		if owner == nil {
+			continue
+		}
+
+		id, ok := lookupField(item, p.config.IDField)
+		if !ok {
+			return nil, errors.Errorf("webhook monitor list contains an item without the ID field %q", p.config.IDField)
+		}
+
+		monitor := &models.Monitor{
+			ID:    id,
+			Owner: owner,
+		}
+
+		monitor.Name, _ = lookupField(item, "name")
+		monitor.URL, _ = lookupField(item, "url")
+
+		monitors = append(monitors, monitor)
+	}
+
+	return monitors, nil
+}
+
+// GetIPSourceRanges implements provider.Interface. If no source ranges
+// endpoint is configured, the static source ranges from the config are
+// returned.
+func (p *Provider) GetIPSourceRanges(ctx context.Context, model *models.Monitor) ([]string, error) {
+	if p.config.Endpoints.SourceRanges == "" {
+		return p.config.SourceRanges, nil
+	}
+
+	cachedSourceRanges, ok := p.sourceRangeCache.Get(sourceRangeCacheKey)
+	if ok {
+		return cachedSourceRanges.([]string), nil
+	}
+
+	url, err := p.client.endpointURL("sourceRanges", model)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.do(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to fetch webhook source ranges")
+	}
+
+	if !resp.isSuccess() {
+		return nil, errors.Wrapf(statusError(http.MethodGet, url, resp), "failed to fetch webhook source ranges")
+	}
+
+	var sourceRanges []string
+
+	err = json.Unmarshal(resp.body, &sourceRanges)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decode webhook source ranges")
+	}
+
+	log.V(1).Info("found webhook source ranges", "count", len(sourceRanges), "sourceRanges", sourceRanges)
+
+	p.sourceRangeCache.Set(sourceRangeCacheKey, sourceRanges, time.Hour)
+
+	return sourceRanges, nil
+}
+
+func newPayload(model *models.Monitor) *Payload {
+	payload := &Payload{
+		ID:          model.ID,
+		Name:        model.Name,
+		URL:         model.URL,
+		Annotations: model.Annotations,
+	}
+
+	if model.Owner != nil {
+		payload.Owner = model.Owner.String()
+	}
+
+	return payload
+}
@@ -0,0 +1,367 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/config"
+	"github.com/Bonial-International-GmbH/ingress-monitor-controller/pkg/models"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// server is an in-memory implementation of the default webhook endpoints.
+// Monitor IDs are returned in the nested field data.id.
+type server struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	nextID     int
+	monitors   map[string]Payload
+	failures   int
+	signatures []string
+	requests   []string
+	secret     string
+}
+
+func newServer(t *testing.T, secret string) *server {
+	s := &server{
+		monitors: make(map[string]Payload),
+		secret:   secret,
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	if s.failures > 0 {
+		s.failures--
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+
+	if s.secret != "" {
+		timestamp := r.Header.Get("X-Ingress-Monitor-Timestamp")
+		signature := r.Header.Get("X-Ingress-Monitor-Signature")
+		if !hmac.Equal([]byte(signature), []byte(sign(s.secret, timestamp, r.Method, r.URL.RequestURI(), body))) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		s.signatures = append(s.signatures, signature)
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/monitors")
+
+	switch {
+	case r.Method == http.MethodGet && path == "/source-ranges":
+		json.NewEncoder(w).Encode([]string{"10.0.0.0/8", "1.2.3.4/32"})
+	case r.Method == http.MethodGet && path == "":
+		items := []map[string]interface{}{}
+		for id := 1; id <= s.nextID; id++ {
+			if payload, found := s.monitors[strconv.Itoa(id)]; found {
+				items = append(items, map[string]interface{}{"data": map[string]interface{}{"id": id}, "name": payload.Name, "url": payload.URL, "owner": payload.Owner})
+			}
+		}
+		json.NewEncoder(w).Encode(items)
+	case r.Method == http.MethodPost && path == "":
+		var payload Payload
+		json.Unmarshal(body, &payload)
+		s.nextID++
+		payload.ID = strconv.Itoa(s.nextID)
+		s.monitors[payload.ID] = payload
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"id": s.nextID}})
+	case r.Method == http.MethodGet:
+		for _, payload := range s.monitors {
+			if "/"+payload.Name == path {
+				json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"id": json.Number(payload.ID)}, "url": payload.URL, "owner": payload.Owner})
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodPut:
+		id := strings.TrimPrefix(path, "/")
+		if _, found := s.monitors[id]; !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var payload Payload
+		json.Unmarshal(body, &payload)
+		s.monitors[id] = payload
+	case r.Method == http.MethodDelete:
+		id := strings.TrimPrefix(path, "/")
+		if _, found := s.monitors[id]; !found {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(s.monitors, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func newTestProvider(t *testing.T, s *server, fn func(*config.WebhookConfig)) *Provider {
+	c := config.NewDefaultProviderConfig().Webhook
+	c.BaseURL = s.URL
+	c.IDField = "data.id"
+	c.RetryBackoff = metav1.Duration{Duration: time.Millisecond}
+
+	if fn != nil {
+		fn(&c)
+	}
+
+	p, err := NewProvider(c)
+	require.NoError(t, err)
+
+	return p
+}
+
+func TestProvider(t *testing.T) {
+	s := newServer(t, "s3cr3t")
+
+	p := newTestProvider(t, s, func(c *config.WebhookConfig) {
+		c.Secret = "s3cr3t"
+	})
+
+	_, err := p.Get(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+
+	model := &models.Monitor{
+		Name: "foo",
+		URL:  "https://foo.example.com",
+		Annotations: config.Annotations{
+			config.AnnotationEnabled: "true",
+		},
+	}
+
+	require.NoError(t, p.Create(context.Background(), model))
+	assert.Equal(t, "1", model.ID)
+
+	assert.Equal(t, map[string]Payload{
+		"1": {
+			ID:   "1",
+			Name: "foo",
+			URL:  "https://foo.example.com",
+			Annotations: map[string]string{
+				config.AnnotationEnabled: "true",
+			},
+		},
+	}, s.monitors)
+
+	monitor, err := p.Get(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, &models.Monitor{ID: "1", Name: "foo", URL: "https://foo.example.com"}, monitor)
+
+	monitor.URL = "https://bar.example.com"
+	require.NoError(t, p.Update(context.Background(), monitor))
+	assert.Equal(t, "https://bar.example.com", s.monitors["1"].URL)
+
+	require.NoError(t, p.Delete(context.Background(), "foo"))
+	assert.Empty(t, s.monitors)
+
+	err = p.Delete(context.Background(), "foo")
+	require.Equal(t, models.ErrMonitorNotFound, err)
+}
+
+func TestProvider_List(t *testing.T) {
+	s := newServer(t, "")
+
+	p := newTestProvider(t, s, func(c *config.WebhookConfig) {
+		c.Endpoints.List = "/monitors"
+	})
+
+	owner := &models.Owner{Namespace: "kube-system", Name: "foo"}
+
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{Name: "unowned", URL: "https://unowned.example.com"}))
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{Name: "owned", URL: "https://owned.example.com", Owner: owner}))
+
+	assert.Equal(t, "cluster=&name=foo&namespace=kube-system&uid=", s.monitors["2"].Owner)
+
+	monitors, err := p.List(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*models.Monitor{{ID: "2", Name: "owned", URL: "https://owned.example.com", Owner: owner}}, monitors)
+}
+
+func TestProvider_List_NotConfigured(t *testing.T) {
+	p := newTestProvider(t, newServer(t, ""), nil)
+
+	_, err := p.List(context.Background())
+	require.EqualError(t, err, "webhook list endpoint is not configured")
+}
+
+func TestProvider_InvalidSignature(t *testing.T) {
+	s := newServer(t, "s3cr3t")
+
+	p := newTestProvider(t, s, func(c *config.WebhookConfig) {
+		c.Secret = "wrong"
+	})
+
+	err := p.Create(context.Background(), &models.Monitor{Name: "foo"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status 401")
+}
+
+func TestProvider_SignatureCoversRequest(t *testing.T) {
+	s := newServer(t, "s3cr3t")
+
+	p := newTestProvider(t, s, func(c *config.WebhookConfig) {
+		c.Secret = "s3cr3t"
+	})
+
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{Name: "foo"}))
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{Name: "bar"}))
+
+	s.signatures = nil
+
+	_, err := p.Get(context.Background(), "foo")
+	require.NoError(t, err)
+	_, err = p.Get(context.Background(), "bar")
+	require.NoError(t, err)
+
+	require.Len(t, s.signatures, 2)
+	assert.NotEqual(t, s.signatures[0], s.signatures[1])
+
+	// The signature of a request without body must not be valid for other
+	// methods or paths.
+	timestamp := "1600000000"
+	assert.NotEqual(t, sign("s3cr3t", timestamp, http.MethodGet, "/monitors/foo", nil), sign("s3cr3t", timestamp, http.MethodDelete, "/monitors/foo", nil))
+	assert.NotEqual(t, sign("s3cr3t", timestamp, http.MethodDelete, "/monitors/1", nil), sign("s3cr3t", timestamp, http.MethodDelete, "/monitors/2", nil))
+	assert.NotEqual(t, sign("s3cr3t", timestamp, http.MethodDelete, "/monitors/1", nil), sign("s3cr3t", "1600000001", http.MethodDelete, "/monitors/1", nil))
+}
+
+func TestProvider_Retries(t *testing.T) {
+	s := newServer(t, "")
+
+	p := newTestProvider(t, s, nil)
+
+	require.NoError(t, p.Create(context.Background(), &models.Monitor{Name: "foo"}))
+
+	s.requests = nil
+	s.failures = 2
+
+	_, err := p.Get(context.Background(), "foo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"GET /monitors/foo", "GET /monitors/foo", "GET /monitors/foo"}, s.requests)
+}
+
+func TestProvider_CreateNotRetried(t *testing.T) {
+	s := newServer(t, "")
+	s.failures = 1
+
+	p := newTestProvider(t, s, nil)
+
+	err := p.Create(context.Background(), &models.Monitor{Name: "foo"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unexpected status 503")
+	assert.Equal(t, []string{"POST /monitors"}, s.requests)
+	assert.Empty(t, s.monitors)
+}
+
+func TestProvider_RetriesExhausted(t *testing.T) {
+	s := newServer(t, "")
+	s.failures = 3
+
+	p := newTestProvider(t, s, func(c *config.WebhookConfig) {
+		c.MaxRetries = 2
+	})
+
+	_, err := p.Get(context.Background(), "foo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "giving up after 3 attempts")
+	assert.Len(t, s.requests, 3)
+}
+
+func TestProvider_RetriesAbortedOnContextCancel(t *testing.T) {
+	s := newServer(t, "")
+	s.failures = 10
+
+	p := newTestProvider(t, s, func(c *config.WebhookConfig) {
+		c.MaxRetries = 10
+		c.RetryBackoff = metav1.Duration{Duration: time.Hour}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := p.Get(ctx, "foo")
+	require.Error(t, err)
+	assert.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+	assert.Len(t, s.requests, 1)
+}
+
+func TestProvider_MissingIDField(t *testing.T) {
+	s := newServer(t, "")
+
+	p := newTestProvider(t, s, func(c *config.WebhookConfig) {
+		c.IDField = "monitorId"
+	})
+
+	model := &models.Monitor{Name: "foo"}
+
+	require.NoError(t, p.Create(context.Background(), model))
+	assert.Empty(t, model.ID)
+
+	_, err := p.Get(context.Background(), "foo")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `does not contain the ID field "monitorId"`)
+}
+
+func TestProvider_GetIPSourceRanges(t *testing.T) {
+	s := newServer(t, "")
+
+	t.Run("static source ranges", func(t *testing.T) {
+		p := newTestProvider(t, s, func(c *config.WebhookConfig) {
+			c.SourceRanges = []string{"5.6.7.8/32"}
+		})
+
+		sourceRanges, err := p.GetIPSourceRanges(context.Background(), &models.Monitor{Name: "foo"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"5.6.7.8/32"}, sourceRanges)
+	})
+
+	t.Run("source ranges endpoint", func(t *testing.T) {
+		p := newTestProvider(t, s, func(c *config.WebhookConfig) {
+			c.Endpoints.SourceRanges = "/monitors/source-ranges"
+		})
+
+		for i := 0; i < 2; i++ {
+			sourceRanges, err := p.GetIPSourceRanges(context.Background(), &models.Monitor{Name: "foo"})
+			require.NoError(t, err)
+			assert.Equal(t, []string{"10.0.0.0/8", "1.2.3.4/32"}, sourceRanges)
+		}
+
+		// The second call is served from the cache.
+		assert.Equal(t, []string{"GET /monitors/source-ranges"}, s.requests)
+	})
+}
+
+func TestNewProvider_InvalidTemplate(t *testing.T) {
+	c := config.NewDefaultProviderConfig().Webhook
+	c.Endpoints.Get = "/monitors/{{.Name"
+
+	_, err := NewProvider(c)
+	require.Error(t, err)
+}